@@ -0,0 +1,68 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AccessApprovalSpec approves a single revision of a UserConfig, Team or AccessElevation requesting elevated access
+// +kubebuilder:validation:XValidation:rule="[has(self.userConfig), has(self.team), has(self.accessElevation)].filter(x, x).size() == 1",message="exactly one of userConfig, team and accessElevation must be set"
+type AccessApprovalSpec struct {
+	// UserConfig is the name of the approved UserConfig
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	UserConfig string `json:"userConfig,omitempty"`
+
+	// Team is the name of the approved Team
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	Team string `json:"team,omitempty"`
+
+	// AccessElevation is the name of the approved AccessElevation
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	AccessElevation string `json:"accessElevation,omitempty"`
+
+	// SpecHash is the hash of the approved spec, copied from the
+	// status.approval.specHash of the approved object. The approval lapses as
+	// soon as the spec, or its template, changes.
+	// +kubebuilder:validation:MinLength=1
+	SpecHash string `json:"specHash"`
+
+	// Approver is the user approving. The webhook only admits AccessApprovals
+	// created by the approver themselves.
+	// +kubebuilder:validation:MinLength=1
+	Approver string `json:"approver"`
+
+	// Reason records why the access was approved
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="UserConfig",type="string",JSONPath=".spec.userConfig"
+// +kubebuilder:printcolumn:name="Team",type="string",JSONPath=".spec.team"
+// +kubebuilder:printcolumn:name="AccessElevation",type="string",JSONPath=".spec.accessElevation"
+// +kubebuilder:printcolumn:name="Approver",type="string",JSONPath=".spec.approver"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:validation:XValidation:rule="!has(oldSelf.spec) || self.spec == oldSelf.spec",message="spec is immutable"
+// AccessApproval lets an approver release a UserConfig, Team or AccessElevation held in Pending by the OperatorConfig approval policy.
+type AccessApproval struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec AccessApprovalSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// AccessApprovalList contains a list of AccessApproval
+type AccessApprovalList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AccessApproval `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AccessApproval{}, &AccessApprovalList{})
+}
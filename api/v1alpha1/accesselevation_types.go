@@ -0,0 +1,107 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Phases of an AccessElevation, also the outcomes recorded in the elevation
+// history of the UserConfig
+const (
+	// ElevationPending waits for the UserConfig, its namespaces and, when the
+	// approval policy requires it, an AccessApproval
+	ElevationPending string = "Pending"
+	// ElevationActive grants the extra permissions until the expiry time
+	ElevationActive string = "Active"
+	// ElevationExpired removed the extra permissions at the expiry time
+	ElevationExpired string = "Expired"
+	// ElevationRevoked removed the extra permissions early, the AccessElevation was deleted
+	ElevationRevoked string = "Revoked"
+)
+
+// AccessElevationSpec grants a UserConfig extra permissions for a limited time
+type AccessElevationSpec struct {
+	// UserConfig is the name of the elevated UserConfig
+	// +kubebuilder:validation:MinLength=1
+	UserConfig string `json:"userConfig"`
+
+	// Permissions are the extra resource permissions granted to the user
+	// +kubebuilder:validation:MinItems=1
+	Permissions []ResourcePermission `json:"permissions"`
+
+	// Namespaces restricts the elevation to some namespaces of the UserConfig,
+	// every namespace when empty
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// Duration is how long the permissions are granted, counted from the
+	// activation. It can't exceed the maxElevationDuration of the OperatorConfig.
+	// +kubebuilder:validation:XValidation:rule="duration(self) > duration('0s')",message="duration must be positive"
+	Duration metav1.Duration `json:"duration"`
+
+	// Reason records why the elevation was needed, e.g. an incident reference
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
+
+// AccessElevationStatus defines the observed state of AccessElevation
+type AccessElevationStatus struct {
+	// Phase is Pending, Active or Expired
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// StartTime is when the permissions were first granted
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// ExpiryTime is when the permissions are removed
+	// +optional
+	ExpiryTime *metav1.Time `json:"expiryTime,omitempty"`
+
+	// Namespaces lists the namespaces the permissions are granted in
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// FilteredPermissions lists the permissions removed by the operator RBAC policy
+	// +optional
+	FilteredPermissions []string `json:"filteredPermissions,omitempty"`
+
+	// Approval reports why the elevation requires an approval under the
+	// OperatorConfig approval policy and who approved it. Unset when no approval
+	// is required.
+	// +optional
+	Approval *ApprovalStatus `json:"approval,omitempty"`
+
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="UserConfig",type="string",JSONPath=".spec.userConfig"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Expires",type="date",JSONPath=".status.expiryTime"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:validation:XValidation:rule="!has(oldSelf.spec) || self.spec == oldSelf.spec",message="spec is immutable"
+// AccessElevation grants a UserConfig extra permissions for a limited time. They are
+// removed when the duration elapses or the AccessElevation is deleted.
+type AccessElevation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AccessElevationSpec   `json:"spec,omitempty"`
+	Status AccessElevationStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// AccessElevationList contains a list of AccessElevation
+type AccessElevationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AccessElevation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AccessElevation{}, &AccessElevationList{})
+}
@@ -0,0 +1,135 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OperatorConfigName is the name of the OperatorConfig singleton read by the operator
+const OperatorConfigName = "cluster"
+
+// OperatorConfigSpec defines the operator wide defaults. Unset fields keep the
+// built-in defaults, or the operator flags for the fields they can also set.
+type OperatorConfigSpec struct {
+	// DefaultResourceQuota is applied to the UserConfigs and Teams setting no
+	// quota, neither directly nor through their template. Defaults to 10 pods,
+	// 2 CPUs and 4Gi of memory.
+	// +optional
+	DefaultResourceQuota *ResourceQuota `json:"defaultResourceQuota,omitempty"`
+
+	// DefaultLimitRange is applied to the UserConfigs and Teams setting no
+	// limits. Its container limits are also the fallback of the limits left
+	// unset by a UserConfig.
+	// +optional
+	DefaultLimitRange *LimitRange `json:"defaultLimitRange,omitempty"`
+
+	// KubeconfigTokenLifetime is the lifetime of the ServiceAccount token of the
+	// generated kubeconfigs. Defaults to one year.
+	// +optional
+	KubeconfigTokenLifetime *metav1.Duration `json:"kubeconfigTokenLifetime,omitempty"`
+
+	// ProtectedNamespaces can never be provisioned, adopted or deleted for a
+	// UserConfig, in addition to default, kube-system, kube-public and
+	// kube-node-lease
+	// +optional
+	ProtectedNamespaces []string `json:"protectedNamespaces,omitempty"`
+
+	// NamespaceLabels are put on every namespace managed for a UserConfig. The
+	// labels requested by the UserConfig win on conflicts.
+	// +optional
+	NamespaceLabels map[string]string `json:"namespaceLabels,omitempty"`
+
+	// MaxElevationDuration is the longest duration an AccessElevation may
+	// request. Defaults to 24h.
+	// +optional
+	MaxElevationDuration *metav1.Duration `json:"maxElevationDuration,omitempty"`
+
+	// DefaultPodSecurityLevel is the Pod Security level of the managed
+	// namespaces whose spec.podSecurity doesn't set one. Overrides the
+	// --default-pod-security-level flag, restricted by default.
+	// +optional
+	// +kubebuilder:validation:Enum=privileged;baseline;restricted
+	DefaultPodSecurityLevel string `json:"defaultPodSecurityLevel,omitempty"`
+
+	// ClusterReadResources is the allowlist of cluster scoped resources a
+	// UserConfig may request read access to. Overrides the
+	// --cluster-read-resources flag.
+	// +optional
+	ClusterReadResources []string `json:"clusterReadResources,omitempty"`
+
+	// PrivilegedGroups are the identity groups exempt from the privilege
+	// escalation guard. Overrides the --privileged-groups flag, admin by default.
+	// +optional
+	PrivilegedGroups []string `json:"privilegedGroups,omitempty"`
+
+	// NetworkPolicyBackend renders the FQDN egress rules, cilium or calico, or
+	// none to only create NetworkPolicies. Overrides the --network-policy-backend
+	// flag.
+	// +optional
+	// +kubebuilder:validation:Enum=none;cilium;calico
+	NetworkPolicyBackend string `json:"networkPolicyBackend,omitempty"`
+
+	// Approval holds the UserConfigs, Teams and AccessElevations requesting
+	// elevated access in Pending until an approver creates an AccessApproval. No
+	// approval is required when unset.
+	// +optional
+	Approval *ApprovalPolicy `json:"approval,omitempty"`
+}
+
+// ApprovalPolicy defines which UserConfigs and Teams require an approval and who may approve them
+type ApprovalPolicy struct {
+	// Groups are the UserConfig identity groups requiring an approval. Defaults
+	// to admin and security.
+	// +optional
+	Groups []string `json:"groups,omitempty"`
+
+	// Resources are the resources whose write access, any of C, U, D or *,
+	// requires an approval. Defaults to secret.
+	// +optional
+	Resources []string `json:"resources,omitempty"`
+
+	// ResourceQuota maps quota resources to the hard limit of a namespace above
+	// which an approval is required, e.g. requests.cpu: "8"
+	// +optional
+	ResourceQuota map[string]string `json:"resourceQuota,omitempty"`
+
+	// Approvers are the users allowed to approve. A user never approves their
+	// own UserConfig.
+	// +optional
+	Approvers []string `json:"approvers,omitempty"`
+}
+
+// OperatorConfigStatus defines the observed state of OperatorConfig
+type OperatorConfigStatus struct {
+	// Conditions report whether the spec was applied. An invalid spec leaves
+	// the previously applied defaults in place.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:validation:XValidation:rule="self.metadata.name == 'cluster'",message="the OperatorConfig must be named cluster"
+// OperatorConfig holds the defaults of the operator. It is a singleton named cluster.
+type OperatorConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OperatorConfigSpec   `json:"spec,omitempty"`
+	Status OperatorConfigStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// OperatorConfigList contains a list of OperatorConfig
+type OperatorConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OperatorConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OperatorConfig{}, &OperatorConfigList{})
+}
@@ -0,0 +1,114 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TeamRole is a named set of permissions granted to members in the shared namespace
+type TeamRole struct {
+	// Name of the role, referenced by members
+	// +kubebuilder:validation:MaxLength=30
+	// +kubebuilder:validation:Pattern=^[a-z0-9]([-a-z0-9]*[a-z0-9])?$
+	Name string `json:"name"`
+
+	// Resources is the list of resource permissions granted by the role
+	// +kubebuilder:validation:MinItems=1
+	Resources []ResourcePermission `json:"resources"`
+}
+
+// TeamMember adds the user of a UserConfig to the team
+type TeamMember struct {
+	// UserConfig is the name of the member's UserConfig
+	// +kubebuilder:validation:MinLength=1
+	UserConfig string `json:"userConfig"`
+
+	// Role is the name of an entry of spec.roles
+	// +kubebuilder:validation:MinLength=1
+	Role string `json:"role"`
+}
+
+// TeamSpec defines the desired state of Team
+type TeamSpec struct {
+	// Namespace is the name of the shared namespace, defaults to the Team name
+	// +optional
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=^[a-z0-9]([-a-z0-9]*[a-z0-9])?$
+	Namespace string `json:"namespace,omitempty"`
+
+	// Roles defines the roles members can be given in the shared namespace
+	// +kubebuilder:validation:MinItems=1
+	Roles []TeamRole `json:"roles"`
+
+	// Members lists the UserConfigs collaborating in the shared namespace
+	// +optional
+	Members []TeamMember `json:"members,omitempty"`
+
+	// ResourceQuotas defines the resource quota of the shared namespace
+	// +optional
+	ResourceQuotas *ResourceQuota `json:"resourceQuota,omitempty"`
+
+	// LimitRange defines the limits of resource usable by the containers of the shared namespace
+	// +optional
+	LimitRange *LimitRange `json:"limitRange,omitempty"`
+}
+
+// TeamStatus defines the observed state of Team
+type TeamStatus struct {
+	// State represents the current state of the Team
+	// +kubebuilder:validation:Enum=Pending;Active;Error
+	State string `json:"state,omitempty"`
+
+	// Namespace is the shared namespace provisioned for the Team
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Members is the number of members bound in the shared namespace
+	// +optional
+	Members int32 `json:"members,omitempty"`
+
+	// MissingMembers lists members whose UserConfig or role doesn't exist
+	// +optional
+	MissingMembers []string `json:"missingMembers,omitempty"`
+
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// FilteredPermissions lists the permissions removed from the generated Roles by the
+	// operator RBAC policy, e.g. "rolebindings: create, delete"
+	// +optional
+	FilteredPermissions []string `json:"filteredPermissions,omitempty"`
+
+	// Approval reports why the Team requires an approval under the OperatorConfig
+	// approval policy and who approved it. Unset when no approval is required.
+	// +optional
+	Approval *ApprovalStatus `json:"approval,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.state"
+// +kubebuilder:printcolumn:name="Namespace",type="string",JSONPath=".status.namespace"
+// +kubebuilder:printcolumn:name="Members",type="integer",JSONPath=".status.members"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster
+// Team owns a namespace shared by several UserConfigs, each member bound to one of the team roles.
+type Team struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TeamSpec   `json:"spec,omitempty"`
+	Status TeamStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// TeamList contains a list of Team
+type TeamList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Team `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Team{}, &TeamList{})
+}
@@ -0,0 +1,98 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Enforcement modes of a TenantBudget
+const (
+	// BudgetEnforcementReject denies UserConfigs, Teams and UserConfigTemplates raising a quota past the budget
+	BudgetEnforcementReject string = "Reject"
+	// BudgetEnforcementMark admits them and sets their OverCommitted condition
+	BudgetEnforcementMark string = "Mark"
+)
+
+// TenantBudgetSpec defines the cluster capacity the UserConfig and Team quotas can add up to.
+// Every limit is a Kubernetes quantity; unset limits aren't enforced.
+type TenantBudgetSpec struct {
+	// CPU caps the summed requests.cpu quota, or cpu when requests.cpu isn't set
+	// +optional
+	CPU string `json:"cpu,omitempty"`
+
+	// Memory caps the summed requests.memory quota, or memory when requests.memory isn't set
+	// +optional
+	Memory string `json:"memory,omitempty"`
+
+	// Storage caps the summed requests.storage quota
+	// +optional
+	Storage string `json:"storage,omitempty"`
+
+	// Pods caps the summed pods quota
+	// +optional
+	Pods string `json:"pods,omitempty"`
+
+	// Enforcement is Reject to deny the UserConfigs, Teams and UserConfigTemplates
+	// raising a quota past the budget in the validating webhooks, or Mark to only
+	// set the OverCommitted condition of the UserConfigs and Teams
+	// +optional
+	// +kubebuilder:validation:Enum=Reject;Mark
+	// +kubebuilder:default=Reject
+	Enforcement string `json:"enforcement,omitempty"`
+}
+
+// TenantBudgetStatus defines the observed state of TenantBudget
+type TenantBudgetStatus struct {
+	// Committed is the quota handed out to the UserConfigs and Teams for each budget resource
+	// +optional
+	Committed map[string]string `json:"committed,omitempty"`
+
+	// Headroom is the budget left for each resource, negative once over-committed
+	// +optional
+	Headroom map[string]string `json:"headroom,omitempty"`
+
+	// UserConfigs is the number of UserConfigs counted in the budget
+	// +optional
+	UserConfigs int32 `json:"userConfigs,omitempty"`
+
+	// Teams is the number of Teams counted in the budget
+	// +optional
+	Teams int32 `json:"teams,omitempty"`
+
+	// OverCommitted lists the UserConfigs, and the Teams as team/<name>, that
+	// don't fit in the budget, the oldest ones being served first
+	// +optional
+	OverCommitted []string `json:"overCommitted,omitempty"`
+
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Enforcement",type="string",JSONPath=".spec.enforcement"
+// +kubebuilder:printcolumn:name="CPU Left",type="string",JSONPath=".status.headroom.cpu"
+// +kubebuilder:printcolumn:name="Memory Left",type="string",JSONPath=".status.headroom.memory"
+// +kubebuilder:printcolumn:name="OverCommitted",type="string",JSONPath=".status.conditions[?(@.type==\"OverCommitted\")].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster
+// TenantBudget caps the total quota handed out to the UserConfigs and Teams of the cluster.
+type TenantBudget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TenantBudgetSpec   `json:"spec,omitempty"`
+	Status TenantBudgetStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// TenantBudgetList contains a list of TenantBudget
+type TenantBudgetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TenantBudget `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TenantBudget{}, &TenantBudgetList{})
+}
@@ -1,7 +1,9 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // Condition types for UserConfig status
@@ -15,6 +17,10 @@ const (
 	// Error condition indicates there was an error during reconciliation
 	ErrorCondition  string = "Error"
 	UserConfigReady string = "Ready"
+	// QuotaNearlyExhausted condition indicates the namespace quota usage crossed the configured threshold
+	QuotaNearlyExhaustedCondition string = "QuotaNearlyExhausted"
+	// OverCommitted condition indicates the quota of the UserConfig doesn't fit in a TenantBudget
+	OverCommittedCondition string = "OverCommitted"
 )
 
 // Identity defines the user identity configuration
@@ -37,11 +43,25 @@ type Identity struct {
 
 	Contact string `json:"contact"`
 
-	// Labels are optional additional tags for user classification.
+	// Labels are optional additional tags for user classification, given as
+	// key=value pairs. They are put on the managed namespaces and their objects.
 	// +optional
 	Labels []string `json:"labels,omitempty"`
 }
 
+// NamespaceMetadata holds custom labels and annotations put on the managed
+// namespaces and every object generated in them. Keys owned by the operator
+// or by Pod Security Admission are ignored.
+type NamespaceMetadata struct {
+	// Labels to add, e.g. cost-center or team
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations to add
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
 // ResourcePermission defines access level for specific Kubernetes resources
 type ResourcePermission struct {
 	// Resource specifies the type of Kubernetes resource.
@@ -58,11 +78,77 @@ type ResourcePermission struct {
 	Operation string `json:"operation"`
 }
 
+// NamespacePermission grants access to the namespaces of other UserConfigs.
+// Access is only granted by targets listing this UserConfig in spec.allowAccessFrom.
+// +kubebuilder:validation:XValidation:rule="has(self.userConfig) != has(self.selector)",message="exactly one of userConfig or selector must be set"
+type NamespacePermission struct {
+	// UserConfig is the name of the UserConfig whose namespaces are accessed
+	// +optional
+	UserConfig string `json:"userConfig,omitempty"`
+
+	// Selector selects the target UserConfigs by label
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// Resources is a list of resource permissions granted in the target namespaces
+	// +kubebuilder:validation:MinItems=1
+	Resources []ResourcePermission `json:"resources"`
+}
+
 // Permissions defines the overall permission configuration
 type Permissions struct {
 	// Resources is a list of resource permissions granted to the user.
-	// +kubebuilder:validation:MinItems=1
-	Resources []ResourcePermission `json:"resources"`
+	// May be omitted when the permissions come from the referenced template.
+	// +optional
+	Resources []ResourcePermission `json:"resources,omitempty"`
+
+	// Namespaces grants access to the namespaces of other UserConfigs
+	// +optional
+	Namespaces []NamespacePermission `json:"namespaces,omitempty"`
+}
+
+// ClusterPermissions grants read-only access to cluster scoped resources
+type ClusterPermissions struct {
+	// Resources lists the cluster scoped resources the user may get, list and watch,
+	// e.g. nodes or storageclasses. Resources outside the operator allowlist are ignored.
+	// +optional
+	// +kubebuilder:validation:MaxItems=20
+	Resources []string `json:"resources,omitempty"`
+
+	// OwnNamespaces grants get on the namespace objects provisioned for the UserConfig
+	// +optional
+	OwnNamespaces bool `json:"ownNamespaces,omitempty"`
+}
+
+// PodSecurity configures the Pod Security Admission labels of the managed namespaces.
+// Unset levels default to the operator configured level.
+type PodSecurity struct {
+	// Enforce is the level above which pods are rejected
+	// +optional
+	// +kubebuilder:validation:Enum=privileged;baseline;restricted
+	Enforce string `json:"enforce,omitempty"`
+
+	// Audit is the level above which pods are recorded in the audit log
+	// +optional
+	// +kubebuilder:validation:Enum=privileged;baseline;restricted
+	Audit string `json:"audit,omitempty"`
+
+	// Warn is the level above which users are warned when creating pods
+	// +optional
+	// +kubebuilder:validation:Enum=privileged;baseline;restricted
+	Warn string `json:"warn,omitempty"`
+
+	// Version pins the policy version of every level, e.g. v1.31 or latest
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(latest|v1\.[0-9]+)$`
+	Version string `json:"version,omitempty"`
+}
+
+// TemplateReference points to the UserConfigTemplate a UserConfig inherits from
+type TemplateReference struct {
+	// Name of the cluster scoped UserConfigTemplate
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
 }
 
 // Credentials defines the credentials for external secrets
@@ -128,105 +214,205 @@ type Resources struct {
 	// sample values: 100Mi, 1Gi, 1.5Gi
 	// +kubebuilder:validation:Pattern=^([+-]?[0-9.]+)([eEinumkKMGTP]*[-+]?[0-9]*)$
 	Memory string `json:"memory,omitempty"`
+	// EphemeralStorage specifies the local ephemeral storage of a container or pod
+	// sample values: 500Mi, 2Gi
+	// +kubebuilder:validation:Pattern=^([+-]?[0-9.]+)([eEinumkKMGTP]*[-+]?[0-9]*)$
+	EphemeralStorage string `json:"ephemeral-storage,omitempty"`
+	// Storage specifies the requested storage of a PersistentVolumeClaim
+	// sample values: 1Gi, 100Gi
+	// +kubebuilder:validation:Pattern=^([+-]?[0-9.]+)([eEinumkKMGTP]*[-+]?[0-9]*)$
+	Storage string `json:"storage,omitempty"`
 }
 
-// ResourceQuota defines the resource quotas for a namespace
+// ResourceQuota defines the resource quotas for a namespace. Each field is
+// named after its quota resource and holds a Kubernetes quantity, e.g. 500m or
+// 1.5Gi; object counts must be whole numbers.
 type ResourceQuota struct {
 	// CPU quota for the namespace
 	// +optional
-	// +kubebuilder:validation:Pattern=^([0-9]+)([mKMGTP]*i?)$
 	CPU string `json:"cpu,omitempty"`
 
 	// Memory quota for the namespace
 	// +optional
-	// +kubebuilder:validation:Pattern=^([0-9]+)([mKMGTP]*i?)$
 	Memory string `json:"memory,omitempty"`
 
 	// Ephemeral storage quota
 	// +optional
-	// +kubebuilder:validation:Pattern=^([0-9]+)([mKMGTP]*i?)$
 	EphemeralStorage string `json:"ephemeral-storage,omitempty"`
 
 	// Request quotas for CPU
 	// +optional
-	// +kubebuilder:validation:Pattern=^([0-9]+)([mKMGTP]*i?)$
 	RequestsCPU string `json:"requests.cpu,omitempty"`
 
 	// Request quotas for memory
 	// +optional
-	// +kubebuilder:validation:Pattern=^([0-9]+)([mKMGTP]*i?)$
 	RequestsMemory string `json:"requests.memory,omitempty"`
 
 	// Request quotas for storage
 	// +optional
-	// +kubebuilder:validation:Pattern=^([0-9]+)([mKMGTP]*i?)$
 	RequestsStorage string `json:"requests.storage,omitempty"`
 
 	// Request quotas for ephemeral storage
 	// +optional
-	// +kubebuilder:validation:Pattern=^([0-9]+)([mKMGTP]*i?)$
 	RequestsEphemeralStorage string `json:"requests.ephemeral-storage,omitempty"`
 
 	// Limit quotas for CPU
 	// +optional
-	// +kubebuilder:validation:Pattern=^([0-9]+)([mKMGTP]*i?)$
 	LimitsCPU string `json:"limits.cpu,omitempty"`
 
 	// Limit quotas for memory
 	// +optional
-	// +kubebuilder:validation:Pattern=^([0-9]+)([mKMGTP]*i?)$
 	LimitsMemory string `json:"limits.memory,omitempty"`
 
 	// Limit quotas for ephemeral storage
 	// +optional
-	// +kubebuilder:validation:Pattern=^([0-9]+)([mKMGTP]*i?)$
 	LimitsEphemeralStorage string `json:"limits.ephemeral-storage,omitempty"`
 
 	// Maximum number of pods
 	// +optional
-	// +kubebuilder:validation:Pattern=^[0-9]+$
 	Pods string `json:"pods,omitempty"`
 
 	// Maximum number of services
 	// +optional
-	// +kubebuilder:validation:Pattern=^[0-9]+$
 	Services string `json:"services,omitempty"`
 
 	// Maximum number of replication controllers
 	// +optional
-	// +kubebuilder:validation:Pattern=^[0-9]+$
 	ReplicationControllers string `json:"replicationcontrollers,omitempty"`
 
 	// Maximum number of secrets
 	// +optional
-	// +kubebuilder:validation:Pattern=^[0-9]+$
 	Secrets string `json:"secrets,omitempty"`
 
 	// Maximum number of config maps
 	// +optional
-	// +kubebuilder:validation:Pattern=^[0-9]+$
-	ConfigMaps string `json:"requests.configmaps,omitempty"`
+	ConfigMaps string `json:"configmaps,omitempty"`
+
+	// Deprecated: DeprecatedConfigMaps is the former spelling of configmaps. It is
+	// still accepted and moved to configmaps by the operator.
+	// +optional
+	DeprecatedConfigMaps string `json:"requests.configmaps,omitempty"`
 
 	// Maximum number of persistent volume claims
 	// +optional
-	// +kubebuilder:validation:Pattern=^[0-9]+$
 	PersistentVolumeClaims string `json:"persistentvolumeclaims,omitempty"`
 
 	// Maximum number of node port services
 	// +optional
-	// +kubebuilder:validation:Pattern=^[0-9]+$
 	ServicesNodePorts string `json:"services.nodeports,omitempty"`
 
 	// Maximum number of load balancer services
 	// +optional
-	// +kubebuilder:validation:Pattern=^[0-9]+$
 	ServicesLoadBalancers string `json:"services.loadbalancers,omitempty"`
+
+	// Hard sets quota resources by name, e.g. count/deployments.apps,
+	// requests.nvidia.com/gpu or gold.storageclass.storage.k8s.io/requests.storage.
+	// A resource can't be set both here and in a typed field.
+	// +optional
+	Hard map[string]string `json:"hard,omitempty"`
+
+	// Scopes restrict the quota to the objects matching every scope
+	// +optional
+	Scopes []QuotaScope `json:"scopes,omitempty"`
+
+	// ScopeSelector restricts the quota to the objects matching the scope expressions,
+	// e.g. the pods of a PriorityClass
+	// +optional
+	ScopeSelector *corev1.ScopeSelector `json:"scopeSelector,omitempty"`
+
+	// Additional quotas created next to the main one, each named <userconfig>-<name>
+	// +optional
+	// +kubebuilder:validation:MaxItems=10
+	Additional []ScopedResourceQuota `json:"additional,omitempty"`
+
+	// Autoscale adjusts the hard limits of the main quota to the observed usage
+	// +optional
+	Autoscale *QuotaAutoscale `json:"autoscale,omitempty"`
 }
 
-// LimitRangeLimit defines the limit range of resource usable by container
+// QuotaAutoscale grows the hard limits of a quota while its usage stays above
+// ScaleUpThreshold and shrinks them while it stays below ScaleDownThreshold
+// +kubebuilder:validation:XValidation:rule="self.scaleDownThreshold < self.scaleUpThreshold",message="scaleDownThreshold must be lower than scaleUpThreshold"
+type QuotaAutoscale struct {
+	// Resources lists the quota resources to scale with their bounds
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=10
+	Resources []AutoscaledResource `json:"resources"`
+
+	// ScaleUpThreshold is the used percentage of the hard limit above which it grows
+	// +optional
+	// +kubebuilder:default=80
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	ScaleUpThreshold int32 `json:"scaleUpThreshold,omitempty"`
+
+	// ScaleDownThreshold is the used percentage of the hard limit below which it shrinks
+	// +optional
+	// +kubebuilder:default=30
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=99
+	ScaleDownThreshold int32 `json:"scaleDownThreshold,omitempty"`
+
+	// StepPercent is the percentage of the hard limit added or removed by an adjustment
+	// +optional
+	// +kubebuilder:default=25
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	StepPercent int32 `json:"stepPercent,omitempty"`
+
+	// StabilizationWindow is how long the usage must stay past a threshold before
+	// an adjustment, and the minimum time between two adjustments
+	// +optional
+	// +kubebuilder:default="10m"
+	StabilizationWindow *metav1.Duration `json:"stabilizationWindow,omitempty"`
+}
+
+// AutoscaledResource bounds the hard limit of an autoscaled quota resource. The
+// limit starts from the quota value, or Min when the quota doesn't set it.
+type AutoscaledResource struct {
+	// Name of the quota resource, e.g. requests.cpu or pods
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Min is the lowest hard limit, as a Kubernetes quantity
+	// +kubebuilder:validation:MinLength=1
+	Min string `json:"min"`
+
+	// Max is the highest hard limit, as a Kubernetes quantity
+	// +kubebuilder:validation:MinLength=1
+	Max string `json:"max"`
+}
+
+// QuotaScope is a ResourceQuota scope
+// +kubebuilder:validation:Enum=Terminating;NotTerminating;BestEffort;NotBestEffort;PriorityClass;CrossNamespacePodAffinity
+type QuotaScope string
+
+// ScopedResourceQuota is an additional quota object of the namespace
+type ScopedResourceQuota struct {
+	// Name of the quota, appended to the UserConfig name
+	// +kubebuilder:validation:MaxLength=40
+	// +kubebuilder:validation:Pattern=^[a-z0-9]([-a-z0-9]*[a-z0-9])?$
+	Name string `json:"name"`
+
+	// Hard sets the quota resources by name
+	// +kubebuilder:validation:MinProperties=1
+	Hard map[string]string `json:"hard"`
+
+	// Scopes restrict the quota to the objects matching every scope
+	// +optional
+	Scopes []QuotaScope `json:"scopes,omitempty"`
+
+	// ScopeSelector restricts the quota to the objects matching the scope expressions
+	// +optional
+	ScopeSelector *corev1.ScopeSelector `json:"scopeSelector,omitempty"`
+}
+
+// LimitRangeLimit defines the limit range of resource usable by containers, pods or PersistentVolumeClaims
+// +kubebuilder:validation:XValidation:rule="self.type != 'PersistentVolumeClaim' || (!has(self.default) && !has(self.defaultRequest) && !has(self.maxLimitRequestRatio))",message="PersistentVolumeClaim limits only support min and max"
 type LimitRangeLimit struct {
-	// Type specifies the type of resource, which can be either "Container" or "Pod", and in case of Pod Default resources are not set as they are not applicable
-	// +kubebuilder:validation:Enum=Container;Pod
+	// Type specifies the type of resource, which can be "Container", "Pod" or "PersistentVolumeClaim". Default resources
+	// are not set for Pod as they are not applicable, and PersistentVolumeClaim limits only bound the requested storage
+	// +kubebuilder:validation:Enum=Container;Pod;PersistentVolumeClaim
 	Type string `json:"type"`
 
 	// Maximum allowed resource a container can request or limit. Cannot be assigned above this.
@@ -244,6 +430,10 @@ type LimitRangeLimit struct {
 	// default usable resource allocated to container can request if not assigned any
 	// +optional
 	DefaultRequest *Resources `json:"defaultRequest,omitempty"`
+
+	// MaxLimitRequestRatio caps the ratio of the limit to the request of a resource, e.g. "4"
+	// +optional
+	MaxLimitRequestRatio *Resources `json:"maxLimitRequestRatio,omitempty"`
 }
 
 // LimitRange defines the limit of resource usable by container
@@ -251,20 +441,82 @@ type LimitRange struct {
 	Limits []LimitRangeLimit `json:"limits,omitempty"`
 }
 
+// IPBlock selects a CIDR range, minus the excepted ranges
+type IPBlock struct {
+	// CIDR is the allowed range, e.g. 10.0.0.0/16
+	// +kubebuilder:validation:MinLength=1
+	CIDR string `json:"cidr"`
+
+	// Except lists the ranges within CIDR that are not allowed
+	// +optional
+	Except []string `json:"except,omitempty"`
+}
+
+// NetworkPeer is a single traffic source or destination. A pod selector and a
+// namespace selector set together select the matching pods of the matching
+// namespaces.
+// +kubebuilder:validation:XValidation:rule="!has(self.ipBlock) || (!has(self.podSelector) && !has(self.namespaceSelector))",message="ipBlock can't be combined with podSelector or namespaceSelector"
+// +kubebuilder:validation:XValidation:rule="has(self.podSelector) || has(self.namespaceSelector) || has(self.ipBlock)",message="a peer needs a podSelector, a namespaceSelector or an ipBlock"
+type NetworkPeer struct {
+	// PodSelector selects pods, in the namespace of the policy unless namespaceSelector is set
+	// +optional
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
+
+	// NamespaceSelector selects namespaces
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// IPBlock selects a CIDR range
+	// +optional
+	IPBlock *IPBlock `json:"ipBlock,omitempty"`
+}
+
 // NetworkPolicyPeer defines allowed traffic sources or destinations
 type NetworkPolicyPeer struct {
-	// Pods specifies the allowed pods
+	// Pods specifies the allowed pods of the policy namespace. Each entry is a
+	// separate peer, use peers to select pods of other namespaces.
 	Pods []map[string]string `json:"pods,omitempty"`
-	// Namespaces specifies the allowed namespaces
+	// Namespaces specifies the allowed namespaces. Each entry allows every pod
+	// of the matching namespaces.
 	Namespaces []map[string]string `json:"namespaces,omitempty"`
 
+	// Peers lists sources or destinations whose pod and namespace selectors
+	// must both match
+	// +optional
+	Peers []NetworkPeer `json:"peers,omitempty"`
+
+	// IPBlocks specifies the allowed CIDR ranges
+	// +optional
+	IPBlocks []IPBlock `json:"ipBlocks,omitempty"`
+
+	// FQDNs specifies the allowed external domain names, e.g. api.github.com or
+	// *.github.com. Only used in allowTrafficTo and only enforced when the operator
+	// runs with a Cilium or Calico network policy backend.
+	// +optional
+	// +kubebuilder:validation:Items:Pattern=`^(\*\.)?([a-z0-9]([-a-z0-9]*[a-z0-9])?\.)+[a-z][-a-z0-9]*[a-z0-9]$`
+	FQDNs []string `json:"fqdns,omitempty"`
+
 	// Ports specifies the allowed network ports
 	// +optional
 	Ports []NetworkPolicyPort `json:"ports,omitempty"`
 }
 
 // NetworkPolicy defines the network policy configuration
+// +kubebuilder:validation:XValidation:rule="!has(self.podSelector) || has(self.name)",message="podSelector requires name"
 type NetworkPolicy struct {
+	// Name generates a separate NetworkPolicy <userconfig>-<name> for the entry.
+	// Entries without name are merged into the baseline policy of the namespace,
+	// entries sharing a name into the same policy.
+	// +optional
+	// +kubebuilder:validation:MaxLength=40
+	// +kubebuilder:validation:Pattern=^[a-z0-9]([-a-z0-9]*[a-z0-9])?$
+	Name string `json:"name,omitempty"`
+
+	// PodSelector selects the pods the named policy applies to, defaults to
+	// every pod of the namespace. Requires name.
+	// +optional
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
+
 	// AllowTrafficFrom specifies the allowed traffic sources
 	// Example:
 	// - allowTrafficFrom:
@@ -287,11 +539,17 @@ type NetworkPolicy struct {
 }
 
 // NetworkPolicyPort defines a port and protocol for network policies
+// +kubebuilder:validation:XValidation:rule="type(self.port) == string || (self.port >= 1 && self.port <= 65535)",message="port must be between 1 and 65535"
+// +kubebuilder:validation:XValidation:rule="!has(self.endPort) || (type(self.port) == int && self.endPort >= self.port)",message="endPort requires a numeric port lower or equal to it"
 type NetworkPolicyPort struct {
-	// Port number for network policy, through which traffic is allowed
+	// Port number or named container port through which traffic is allowed
+	Port intstr.IntOrString `json:"port"`
+
+	// EndPort makes the rule cover the range from port to endPort
+	// +optional
 	// +kubebuilder:validation:Minimum=1
 	// +kubebuilder:validation:Maximum=65535
-	Port int `json:"port"`
+	EndPort *int32 `json:"endPort,omitempty"`
 
 	// Protocol for network traffic (defaults to TCP)
 	// +kubebuilder:validation:Enum=TCP;UDP;SCTP
@@ -299,15 +557,49 @@ type NetworkPolicyPort struct {
 	Protocol string `json:"protocol,omitempty"`
 }
 
+// NetworkDefaults toggles the built-in rules of the generated NetworkPolicy
+type NetworkDefaults struct {
+	// AllowDNS allows egress to the cluster DNS, defaults to true
+	// +optional
+	AllowDNS *bool `json:"allowDNS,omitempty"`
+
+	// AllowSameNamespace allows traffic between the pods of the namespace
+	// +optional
+	AllowSameNamespace bool `json:"allowSameNamespace,omitempty"`
+}
+
 // UserConfigSpec defines the desired state of UserConfig
 type UserConfigSpec struct {
 	// Identity contains the user identification and group membership details
 	// +kubebuilder:validation:Required
 	Identity Identity `json:"identity"`
 
+	// TemplateRef references a UserConfigTemplate whose settings are merged with this spec.
+	// Fields set here override the template.
+	// +optional
+	TemplateRef *TemplateReference `json:"templateRef,omitempty"`
+
 	// Permissions defines the access level for specific Kubernetes resources
-	// +kubebuilder:validation:Required
-	Permissions Permissions `json:"permissions"`
+	// +optional
+	Permissions Permissions `json:"permissions,omitempty"`
+
+	// AllowAccessFrom lists the UserConfigs allowed to request access to the
+	// namespaces of this UserConfig through spec.permissions.namespaces
+	// +optional
+	AllowAccessFrom []string `json:"allowAccessFrom,omitempty"`
+
+	// PodSecurity configures the Pod Security Admission levels of the managed namespaces
+	// +optional
+	PodSecurity *PodSecurity `json:"podSecurity,omitempty"`
+
+	// NamespaceMetadata adds custom labels and annotations to the managed namespaces and their objects
+	// +optional
+	NamespaceMetadata *NamespaceMetadata `json:"namespaceMetadata,omitempty"`
+
+	// ClusterPermissions grants read-only access to cluster scoped resources through a
+	// per-user ClusterRole
+	// +optional
+	ClusterPermissions *ClusterPermissions `json:"clusterPermissions,omitempty"`
 
 	// Secrets defines the secrets configuration
 	// +optional
@@ -328,6 +620,117 @@ type UserConfigSpec struct {
 	// NetworkPolicy defines the network policy configuration
 	// +optional
 	NetworkPolicy []NetworkPolicy `json:"networkPolicy,omitempty"`
+
+	// NetworkDefaults configures the traffic always allowed next to the network policies
+	// +optional
+	NetworkDefaults *NetworkDefaults `json:"networkDefaults,omitempty"`
+
+	// Namespaces lists additional namespaces provisioned next to the primary
+	// namespace named after the UserConfig, e.g. dev or staging sandboxes
+	// +optional
+	// +kubebuilder:validation:MaxItems=10
+	Namespaces []UserNamespace `json:"namespaces,omitempty"`
+}
+
+// UserNamespace defines an additional namespace of the UserConfig. Quota, limit
+// range and network policies default to the UserConfig settings when unset.
+// +kubebuilder:validation:XValidation:rule="has(self.name) != has(self.suffix)",message="exactly one of name or suffix must be set"
+type UserNamespace struct {
+	// Name is the full name of the namespace
+	// +optional
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=^[a-z0-9]([-a-z0-9]*[a-z0-9])?$
+	Name string `json:"name,omitempty"`
+
+	// Suffix names the namespace <userconfig-name>-<suffix>
+	// +optional
+	// +kubebuilder:validation:MaxLength=30
+	// +kubebuilder:validation:Pattern=^[a-z0-9]([-a-z0-9]*[a-z0-9])?$
+	Suffix string `json:"suffix,omitempty"`
+
+	// ResourceQuotas overrides the resource quota of the namespace
+	// +optional
+	ResourceQuotas *ResourceQuota `json:"resourceQuota,omitempty"`
+
+	// LimitRange overrides the limit range of the namespace
+	// +optional
+	LimitRange *LimitRange `json:"limitRange,omitempty"`
+
+	// NetworkPolicy overrides the network policies of the namespace
+	// +optional
+	NetworkPolicy []NetworkPolicy `json:"networkPolicy,omitempty"`
+}
+
+// ResourceQuotaUsage mirrors the hard and used amount of a single resource of the namespace ResourceQuota
+type ResourceQuotaUsage struct {
+	// Namespace is the namespace of the ResourceQuota
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Resource is the name of the quota resource, e.g. requests.cpu or pods
+	Resource string `json:"resource"`
+
+	// Hard is the enforced limit for the resource
+	Hard string `json:"hard"`
+
+	// Used is the amount of the resource currently consumed in the namespace
+	// +optional
+	Used string `json:"used,omitempty"`
+
+	// Utilization is the percentage of Hard that is currently used
+	// +optional
+	Utilization int32 `json:"utilization,omitempty"`
+}
+
+// ApprovalStatus reports the approval of a UserConfig requesting elevated access
+type ApprovalStatus struct {
+	// Reasons lists why the UserConfig requires an approval, e.g. "group admin"
+	Reasons []string `json:"reasons"`
+
+	// SpecHash is the hash of the resolved spec an AccessApproval must reference
+	SpecHash string `json:"specHash"`
+
+	// Approver is the user who approved the current spec, empty while pending
+	// +optional
+	Approver string `json:"approver,omitempty"`
+
+	// AccessApproval is the name of the AccessApproval granting the approval
+	// +optional
+	AccessApproval string `json:"accessApproval,omitempty"`
+
+	// ApprovedAt is when the AccessApproval was created
+	// +optional
+	ApprovedAt *metav1.Time `json:"approvedAt,omitempty"`
+}
+
+// ElevationRecord records a time-bound elevation granted by an AccessElevation
+type ElevationRecord struct {
+	// AccessElevation is the name of the AccessElevation
+	AccessElevation string `json:"accessElevation"`
+
+	// Permissions lists the granted permissions, e.g. "secret CRUD"
+	Permissions []string `json:"permissions"`
+
+	// Namespaces lists the namespaces the permissions were granted in
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// Reason is the reason of the AccessElevation
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// StartTime is when the permissions were granted
+	StartTime metav1.Time `json:"startTime"`
+
+	// ExpiryTime is when the permissions were due to be removed
+	ExpiryTime metav1.Time `json:"expiryTime"`
+
+	// EndTime is when the permissions were removed
+	// +optional
+	EndTime *metav1.Time `json:"endTime,omitempty"`
+
+	// Outcome is Active, Expired or Revoked
+	Outcome string `json:"outcome"`
 }
 
 // UserConfigStatus defines the observed state of UserConfig
@@ -341,6 +744,128 @@ type UserConfigStatus struct {
 
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Quota mirrors used vs. hard for each resource of the namespace ResourceQuota
+	// +optional
+	Quota []ResourceQuotaUsage `json:"quota,omitempty"`
+
+	// CPUUtilization is the used percentage of the CPU quota, e.g. "42%"
+	// +optional
+	CPUUtilization string `json:"cpuUtilization,omitempty"`
+
+	// MemoryUtilization is the used percentage of the memory quota, e.g. "42%"
+	// +optional
+	MemoryUtilization string `json:"memoryUtilization,omitempty"`
+
+	// FilteredPermissions lists the permissions removed from the generated Roles by the
+	// operator RBAC policy, e.g. "rolebindings: create, delete"
+	// +optional
+	FilteredPermissions []string `json:"filteredPermissions,omitempty"`
+
+	// AccessGrants lists the namespaces of other UserConfigs the user was granted access to
+	// +optional
+	AccessGrants []string `json:"accessGrants,omitempty"`
+
+	// DeniedAccess lists the requested namespace accesses that were not granted
+	// +optional
+	DeniedAccess []string `json:"deniedAccess,omitempty"`
+
+	// FilteredAccessPermissions lists the spec.permissions.namespaces permissions
+	// removed by the operator RBAC policy, e.g. "rolebindings: create, delete"
+	// +optional
+	FilteredAccessPermissions []string `json:"filteredAccessPermissions,omitempty"`
+
+	// DeniedClusterPermissions lists the spec.clusterPermissions resources that
+	// were not granted, off the operator allowlist or unknown
+	// +optional
+	DeniedClusterPermissions []string `json:"deniedClusterPermissions,omitempty"`
+
+	// Approval reports why the UserConfig requires an approval and who approved it
+	// +optional
+	Approval *ApprovalStatus `json:"approval,omitempty"`
+
+	// ElevationHistory records the AccessElevations of the UserConfig, the most
+	// recent last
+	// +optional
+	ElevationHistory []ElevationRecord `json:"elevationHistory,omitempty"`
+
+	// PodSecurityViolations lists the existing pods reported by the dry-run when
+	// the last reconcile tightened the enforced Pod Security level. It is cleared
+	// once a reconcile tightens nothing.
+	// +optional
+	PodSecurityViolations []string `json:"podSecurityViolations,omitempty"`
+
+	// Namespaces lists every namespace provisioned for the UserConfig
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// Template records the UserConfigTemplate generation the current spec was resolved against
+	// +optional
+	Template *ResolvedTemplate `json:"template,omitempty"`
+
+	// QuotaAutoscale holds the current hard limit of every autoscaled quota resource
+	// +optional
+	QuotaAutoscale []AutoscaledQuota `json:"quotaAutoscale,omitempty"`
+
+	// QuotaHistory lists the latest autoscaling adjustments, oldest first
+	// +optional
+	QuotaHistory []QuotaAdjustment `json:"quotaHistory,omitempty"`
+}
+
+// AutoscaledQuota is the autoscaling state of a quota resource of a namespace
+type AutoscaledQuota struct {
+	// Namespace of the ResourceQuota
+	Namespace string `json:"namespace"`
+
+	// Resource is the quota resource name
+	Resource string `json:"resource"`
+
+	// Hard is the current hard limit
+	Hard string `json:"hard"`
+
+	// Pressure is High while the usage is above the scale up threshold and Low
+	// while it is below the scale down threshold
+	// +optional
+	// +kubebuilder:validation:Enum=High;Low
+	Pressure string `json:"pressure,omitempty"`
+
+	// PressureSince is when the current pressure started
+	// +optional
+	PressureSince *metav1.Time `json:"pressureSince,omitempty"`
+
+	// LastScaleTime is when the hard limit was last adjusted
+	// +optional
+	LastScaleTime *metav1.Time `json:"lastScaleTime,omitempty"`
+}
+
+// QuotaAdjustment records a change of an autoscaled hard limit
+type QuotaAdjustment struct {
+	// Time of the adjustment
+	Time metav1.Time `json:"time"`
+
+	// Namespace of the ResourceQuota
+	Namespace string `json:"namespace"`
+
+	// Resource is the quota resource name
+	Resource string `json:"resource"`
+
+	// From is the previous hard limit
+	From string `json:"from"`
+
+	// To is the new hard limit
+	To string `json:"to"`
+
+	// Utilization is the used percentage of the previous hard limit
+	Utilization int32 `json:"utilization"`
+}
+
+// ResolvedTemplate identifies the template generation merged into the UserConfig
+type ResolvedTemplate struct {
+	// Name of the UserConfigTemplate
+	Name string `json:"name"`
+
+	// Generation of the UserConfigTemplate that was merged
+	Generation int64 `json:"generation"`
 }
 
 // +kubebuilder:object:root=true
@@ -348,6 +873,8 @@ type UserConfigStatus struct {
 // +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.state"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:printcolumn:name="Username",type="string",JSONPath=".spec.identity.username"
+// +kubebuilder:printcolumn:name="CPU",type="string",JSONPath=".status.cpuUtilization"
+// +kubebuilder:printcolumn:name="Memory",type="string",JSONPath=".status.memoryUtilization"
 // +kubebuilder:resource:shortName=ucfg
 // +kubebuilder:resource:scope=Cluster
 // UserConfig is the CRD for managing user configurations in a Kubernetes cluster, defining identity, permissions, secrets, and resource quotas.
@@ -0,0 +1,50 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// UserConfigTemplateSpec defines the shared settings a UserConfig can inherit through spec.templateRef.
+// Fields set on the UserConfig itself override the template.
+type UserConfigTemplateSpec struct {
+	// Permissions are merged with the UserConfig permissions; a UserConfig entry for the same resource wins
+	// +optional
+	Permissions *Permissions `json:"permissions,omitempty"`
+
+	// ResourceQuotas provides the quota fields not set on the UserConfig
+	// +optional
+	ResourceQuotas *ResourceQuota `json:"resourceQuota,omitempty"`
+
+	// LimitRange provides the limits for every type not set on the UserConfig
+	// +optional
+	LimitRange *LimitRange `json:"limitRange,omitempty"`
+
+	// NetworkPolicy entries are applied in addition to the UserConfig entries
+	// +optional
+	NetworkPolicy []NetworkPolicy `json:"networkPolicy,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:shortName=ucfgt
+// +kubebuilder:resource:scope=Cluster
+// UserConfigTemplate is a reusable profile of permissions, quotas, limit ranges and network policies shared by UserConfigs.
+type UserConfigTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec UserConfigTemplateSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// UserConfigTemplateList contains a list of UserConfigTemplate
+type UserConfigTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []UserConfigTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&UserConfigTemplate{}, &UserConfigTemplateList{})
+}
@@ -16,9 +16,13 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
+	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -28,6 +32,7 @@ import (
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -35,8 +40,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+	"01cloud/zoperator/internal/cli"
 	"01cloud/zoperator/internal/controller"
 	"01cloud/zoperator/internal/usecase"
+	webhookmyoperatorv1alpha1 "01cloud/zoperator/internal/webhook/v1alpha1"
 
 	sealedsecretsv1alpha1 "github.com/bitnami-labs/sealed-secrets/pkg/apis/sealedsecrets/v1alpha1"
 	// +kubebuilder:scaffold:imports
@@ -56,12 +63,28 @@ func init() {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == cli.SimulateNetworkPolicyCommand {
+		if err := cli.SimulateNetworkPolicy(context.Background(), os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
 	var tlsOpts []func(*tls.Config)
+	var quotaUsageThreshold int
+	var clusterReadResources string
+	var disableClusterPermissions bool
+	var rejectDangerousPermissions bool
+	var privilegedGroups string
+	var podSecurityLevel string
+	var networkPolicyBackend string
+	var quotaAutoscaleInterval time.Duration
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -72,6 +95,26 @@ func main() {
 		"If set, the metrics endpoint is served securely via HTTPS. Use --metrics-secure=false to use HTTP instead.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.IntVar(&quotaUsageThreshold, "quota-usage-threshold", 90,
+		"Used percentage of a namespace quota resource above which the QuotaNearlyExhausted condition is set.")
+	flag.StringVar(&clusterReadResources, "cluster-read-resources", strings.Join(usecase.DefaultConfig().ClusterReadResources, ","),
+		"Comma separated allowlist of cluster scoped resources UserConfigs may request read access to. "+
+			"The OperatorConfig spec.clusterReadResources takes precedence.")
+	flag.BoolVar(&disableClusterPermissions, "disable-cluster-permissions", false,
+		"If set, spec.clusterPermissions is ignored and no per-user ClusterRoles are created.")
+	flag.BoolVar(&rejectDangerousPermissions, "reject-dangerous-permissions", false,
+		"If set, UserConfigs requesting privilege escalating permissions fail instead of having them stripped.")
+	flag.StringVar(&privilegedGroups, "privileged-groups", strings.Join(usecase.DefaultConfig().RBACPolicy.PrivilegedGroups, ","),
+		"Comma separated identity groups exempt from the privilege escalation policy. "+
+			"The OperatorConfig spec.privilegedGroups takes precedence.")
+	flag.StringVar(&podSecurityLevel, "default-pod-security-level", usecase.DefaultConfig().PodSecurityLevel,
+		"Pod Security level (privileged, baseline or restricted) applied to managed namespaces without spec.podSecurity. "+
+			"The OperatorConfig spec.defaultPodSecurityLevel takes precedence.")
+	flag.StringVar(&networkPolicyBackend, "network-policy-backend", "",
+		"CNI policy backend (cilium or calico) rendering the FQDN egress rules of network policies. "+
+			"Leave empty to only create NetworkPolicies. The OperatorConfig spec.networkPolicyBackend takes precedence.")
+	flag.DurationVar(&quotaAutoscaleInterval, "quota-autoscale-interval", time.Minute,
+		"Interval between two samplings of the usage of the autoscaled quotas.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -145,15 +188,132 @@ func main() {
 		os.Exit(1)
 	}
 
-	uc := usecase.NewUserConfigUseCase(mgr.GetClient(), mgr.GetScheme())
+	ucConfig := usecase.DefaultConfig()
+	ucConfig.QuotaUsageThreshold = int32(quotaUsageThreshold)
+	ucConfig.ClusterPermissionsEnabled = !disableClusterPermissions
+	ucConfig.ClusterReadResources = strings.Split(clusterReadResources, ",")
+	ucConfig.RBACPolicy.Reject = rejectDangerousPermissions
+	ucConfig.RBACPolicy.PrivilegedGroups = strings.Split(privilegedGroups, ",")
+	if !usecase.IsPodSecurityLevel(podSecurityLevel) {
+		setupLog.Error(nil, "unknown Pod Security level, expected privileged, baseline or restricted", "level", podSecurityLevel)
+		os.Exit(1)
+	}
+	ucConfig.PodSecurityLevel = podSecurityLevel
+	ucConfig.RESTConfig = mgr.GetConfig()
+	if !usecase.IsNetworkPolicyBackend(networkPolicyBackend) {
+		setupLog.Error(nil, "unknown network policy backend", "backend", networkPolicyBackend)
+		os.Exit(1)
+	}
+	ucConfig.NetworkPolicyBackend = networkPolicyBackend
+	if quotaAutoscaleInterval <= 0 {
+		setupLog.Error(nil, "quota autoscale interval must be positive", "interval", quotaAutoscaleInterval)
+		os.Exit(1)
+	}
+
+	// The cache isn't started yet, read the OperatorConfig straight from the API server
+	defaults := usecase.NewOperatorDefaults()
+	if err := defaults.Load(context.Background(), mgr.GetAPIReader()); err != nil {
+		setupLog.Error(err, "unable to load OperatorConfig, starting with the built-in defaults")
+	}
+	// Only the AccessApproval webhook checks who approves. Without it no
+	// AccessApproval is trusted, so an approval policy would hold its
+	// UserConfigs forever: refuse to start with one.
+	// nolint:goconst
+	webhooksEnabled := os.Getenv("ENABLE_WEBHOOKS") != "false"
+	ucConfig.ApprovalsVerified = webhooksEnabled
+	if !webhooksEnabled && defaults.Spec().Approval != nil {
+		setupLog.Error(nil, "the OperatorConfig approval policy requires the webhooks, unset ENABLE_WEBHOOKS=false",
+			"operatorConfig", myoperatorv1alpha1.OperatorConfigName)
+		os.Exit(1)
+	}
+	defaultsChanged := make(chan event.GenericEvent)
+	teamsChanged := make(chan event.GenericEvent)
+	budgetsChanged := make(chan event.GenericEvent)
+
+	recorder := mgr.GetEventRecorderFor("userconfig-controller")
+	uc := usecase.NewUserConfigUseCase(mgr.GetClient(), mgr.GetScheme(), recorder, ucConfig, defaults)
 	if err = (&controller.UserConfigReconciler{
+		Client:          mgr.GetClient(),
+		Scheme:          mgr.GetScheme(),
+		UC:              uc,
+		Recorder:        recorder,
+		DefaultsChanged: defaultsChanged,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "UserConfig")
+		os.Exit(1)
+	}
+	if err = (&controller.OperatorConfigReconciler{
+		Client:          mgr.GetClient(),
+		Scheme:          mgr.GetScheme(),
+		Defaults:        defaults,
+		DefaultsChanged: defaultsChanged,
+		TeamsChanged:    teamsChanged,
+		BudgetsChanged:  budgetsChanged,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "OperatorConfig")
+		os.Exit(1)
+	}
+	if err = (&controller.QuotaStatusReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 		UC:     uc,
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "UserConfig")
+		setupLog.Error(err, "unable to create controller", "controller", "QuotaStatus")
+		os.Exit(1)
+	}
+	if err = (&controller.TeamReconciler{
+		Client:          mgr.GetClient(),
+		Scheme:          mgr.GetScheme(),
+		UC:              uc,
+		Recorder:        mgr.GetEventRecorderFor("team-controller"),
+		DefaultsChanged: teamsChanged,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Team")
+		os.Exit(1)
+	}
+	if err = (&controller.TenantBudgetReconciler{
+		Client:          mgr.GetClient(),
+		Scheme:          mgr.GetScheme(),
+		UC:              uc,
+		DefaultsChanged: budgetsChanged,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "TenantBudget")
 		os.Exit(1)
 	}
+	if err = (&controller.AccessElevationReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+		UC:     uc,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "AccessElevation")
+		os.Exit(1)
+	}
+	if err = mgr.Add(&controller.QuotaAutoscaler{
+		Client:   mgr.GetClient(),
+		UC:       uc,
+		Interval: quotaAutoscaleInterval,
+	}); err != nil {
+		setupLog.Error(err, "unable to add runnable", "runnable", "QuotaAutoscaler")
+		os.Exit(1)
+	}
+	if webhooksEnabled {
+		if err = webhookmyoperatorv1alpha1.SetupUserConfigWebhookWithManager(mgr, uc); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "UserConfig")
+			os.Exit(1)
+		}
+		if err = webhookmyoperatorv1alpha1.SetupUserConfigTemplateWebhookWithManager(mgr, uc); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "UserConfigTemplate")
+			os.Exit(1)
+		}
+		if err = webhookmyoperatorv1alpha1.SetupTeamWebhookWithManager(mgr, uc); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Team")
+			os.Exit(1)
+		}
+		if err = webhookmyoperatorv1alpha1.SetupAccessApprovalWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "AccessApproval")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
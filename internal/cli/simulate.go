@@ -0,0 +1,326 @@
+// Package cli implements the operator subcommands run from the manager binary
+// instead of starting the manager.
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+	"01cloud/zoperator/internal/evaluator"
+	"01cloud/zoperator/internal/usecase"
+)
+
+// SimulateNetworkPolicyCommand is the name of the subcommand running SimulateNetworkPolicy
+const SimulateNetworkPolicyCommand = "simulate-netpol"
+
+// endpointFlags are the flags describing one end of the simulated connection
+type endpointFlags struct {
+	namespace       string
+	namespaceLabels string
+	podLabels       string
+	ip              string
+	namedPorts      string
+}
+
+func (e *endpointFlags) register(fs *flag.FlagSet, prefix, side string) {
+	fs.StringVar(&e.namespace, prefix+"-namespace", "", "Namespace of the "+side+" pod, empty for an endpoint outside the cluster.")
+	fs.StringVar(&e.namespaceLabels, prefix+"-namespace-labels", "", "Comma separated key=value labels of the "+side+" namespace.")
+	fs.StringVar(&e.podLabels, prefix+"-labels", "", "Comma separated key=value labels of the "+side+" pod.")
+	fs.StringVar(&e.ip, prefix+"-ip", "", "IP address of the "+side+", matched against ipBlocks.")
+	if side == "destination" {
+		fs.StringVar(&e.namedPorts, prefix+"-named-ports", "", "Comma separated name=number container ports of the destination pod.")
+	}
+}
+
+func (e *endpointFlags) endpoint() (evaluator.Endpoint, error) {
+	endpoint := evaluator.Endpoint{Namespace: e.namespace, IP: e.ip}
+	var err error
+	if endpoint.NamespaceLabels, err = labels.ConvertSelectorToLabelsMap(e.namespaceLabels); err != nil {
+		return endpoint, fmt.Errorf("invalid namespace labels: %w", err)
+	}
+	if endpoint.PodLabels, err = labels.ConvertSelectorToLabelsMap(e.podLabels); err != nil {
+		return endpoint, fmt.Errorf("invalid pod labels: %w", err)
+	}
+	namedPorts, err := labels.ConvertSelectorToLabelsMap(e.namedPorts)
+	if err != nil {
+		return endpoint, fmt.Errorf("invalid named ports: %w", err)
+	}
+	endpoint.NamedPorts = map[string]int32{}
+	for name, number := range namedPorts {
+		port := intstr.Parse(number)
+		if port.Type != intstr.Int {
+			return endpoint, fmt.Errorf("named port %s must map to a number", name)
+		}
+		endpoint.NamedPorts[name] = port.IntVal
+	}
+	return endpoint, nil
+}
+
+// SimulateNetworkPolicy evaluates a connection against the NetworkPolicies
+// generated for a UserConfig, read from a file or from the cluster, and
+// prints whether it is allowed and which rule decided it
+func SimulateNetworkPolicy(ctx context.Context, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet(SimulateNetworkPolicyCommand, flag.ContinueOnError)
+	fs.SetOutput(out)
+	var file, name, port, protocol string
+	var source, destination endpointFlags
+	fs.StringVar(&file, "f", "", "File holding the UserConfig and its UserConfigTemplate. Read from the cluster when empty.")
+	fs.StringVar(&name, "userconfig", "", "Name of the UserConfig, required without -f and optional when the file holds a single one.")
+	fs.StringVar(&port, "port", "", "Destination port, a number or a named port.")
+	fs.StringVar(&protocol, "protocol", string(corev1.ProtocolTCP), "Protocol of the connection: TCP, UDP or SCTP.")
+	source.register(fs, "from", "source")
+	destination.register(fs, "to", "destination")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	scheme := runtime.NewScheme()
+	if err := myoperatorv1alpha1.AddToScheme(scheme); err != nil {
+		return err
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		return err
+	}
+
+	query := evaluator.Query{Port: intstr.Parse(port), Protocol: corev1.Protocol(strings.ToUpper(protocol))}
+	var err error
+	if query.Source, err = source.endpoint(); err != nil {
+		return fmt.Errorf("source: %w", err)
+	}
+	if query.Destination, err = destination.endpoint(); err != nil {
+		return fmt.Errorf("destination: %w", err)
+	}
+
+	var sim *simulation
+	if file == "" {
+		sim, err = loadCluster(ctx, scheme, name, query.Source.Namespace, query.Destination.Namespace)
+	} else {
+		sim, err = loadFile(scheme, file)
+	}
+	if err != nil {
+		return err
+	}
+	uc, err := sim.userConfig(name)
+	if err != nil {
+		return err
+	}
+
+	// Evaluate the policies of the UserConfigs owning either endpoint namespace
+	owners := []*myoperatorv1alpha1.UserConfig{uc}
+	var unmanaged []string
+	for _, endpoint := range []*evaluator.Endpoint{&query.Source, &query.Destination} {
+		if endpoint.Namespace == "" {
+			continue
+		}
+		owner := sim.owner(endpoint.Namespace)
+		if owner == nil {
+			unmanaged = append(unmanaged, endpoint.Namespace)
+		} else if owner != uc && owner != owners[len(owners)-1] {
+			owners = append(owners, owner)
+		}
+		if len(endpoint.NamespaceLabels) == 0 {
+			endpoint.NamespaceLabels = sim.namespaceLabels(endpoint.Namespace, owner)
+		}
+	}
+	var policies []networkingv1.NetworkPolicy
+	for _, owner := range owners {
+		resolved, err := sim.resolve(owner)
+		if err != nil {
+			return err
+		}
+		policies = append(policies, usecase.NetworkPolicies(resolved)...)
+	}
+
+	result, err := evaluator.Evaluate(policies, query)
+	if err != nil {
+		return err
+	}
+
+	verdict := "DENIED"
+	if result.Allowed {
+		verdict = "ALLOWED"
+	}
+	fmt.Fprintln(out, verdict)
+	fmt.Fprintf(out, "  egress:  %s\n", describeDecision(result.Egress))
+	fmt.Fprintf(out, "  ingress: %s\n", describeDecision(result.Ingress))
+	for _, namespace := range unmanaged {
+		fmt.Fprintf(out, "  note: namespace %s isn't managed by a known UserConfig, its own policies weren't evaluated\n", namespace)
+	}
+	return nil
+}
+
+// simulation holds the objects the NetworkPolicies are generated from
+type simulation struct {
+	source      string
+	userConfigs []*myoperatorv1alpha1.UserConfig
+	templates   map[string]*myoperatorv1alpha1.UserConfigTemplate
+	defaults    myoperatorv1alpha1.OperatorConfigSpec
+
+	// labels holds the labels of the endpoint namespaces read from the cluster
+	labels map[string]map[string]string
+}
+
+// loadFile decodes the UserConfigs, UserConfigTemplates and OperatorConfig of file
+func loadFile(scheme *runtime.Scheme, file string) (*simulation, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	sim := &simulation{source: file, templates: map[string]*myoperatorv1alpha1.UserConfigTemplate{}}
+	decoder := serializer.NewCodecFactory(scheme).UniversalDeserializer()
+	for _, doc := range strings.Split(string(data), "\n---\n") {
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+		obj, _, err := decoder.Decode([]byte(doc), nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", file, err)
+		}
+		switch o := obj.(type) {
+		case *myoperatorv1alpha1.UserConfig:
+			sim.userConfigs = append(sim.userConfigs, o)
+		case *myoperatorv1alpha1.UserConfigTemplate:
+			sim.templates[o.Name] = o
+		case *myoperatorv1alpha1.OperatorConfig:
+			if o.Name == myoperatorv1alpha1.OperatorConfigName {
+				sim.defaults = o.Spec
+			}
+		}
+	}
+	return sim, nil
+}
+
+// loadCluster reads the UserConfigs, UserConfigTemplates and OperatorConfig
+// from the cluster, along with the labels of the endpoint namespaces
+func loadCluster(ctx context.Context, scheme *runtime.Scheme, name string, namespaces ...string) (*simulation, error) {
+	if name == "" {
+		return nil, fmt.Errorf("-userconfig is required without -f")
+	}
+	config, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubernetes config: %w", err)
+	}
+	c, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+
+	sim := &simulation{
+		source:    "the cluster",
+		templates: map[string]*myoperatorv1alpha1.UserConfigTemplate{},
+		labels:    map[string]map[string]string{},
+	}
+	userConfigs := &myoperatorv1alpha1.UserConfigList{}
+	if err := c.List(ctx, userConfigs); err != nil {
+		return nil, fmt.Errorf("failed to list UserConfigs: %w", err)
+	}
+	for i := range userConfigs.Items {
+		sim.userConfigs = append(sim.userConfigs, &userConfigs.Items[i])
+	}
+	templates := &myoperatorv1alpha1.UserConfigTemplateList{}
+	if err := c.List(ctx, templates); err != nil {
+		return nil, fmt.Errorf("failed to list UserConfigTemplates: %w", err)
+	}
+	for i := range templates.Items {
+		sim.templates[templates.Items[i].Name] = &templates.Items[i]
+	}
+	defaults := usecase.NewOperatorDefaults()
+	if err := defaults.Load(ctx, c); err != nil {
+		return nil, err
+	}
+	sim.defaults = defaults.Spec()
+
+	for _, namespace := range namespaces {
+		if namespace == "" {
+			continue
+		}
+		ns := &corev1.Namespace{}
+		if err := c.Get(ctx, client.ObjectKey{Name: namespace}, ns); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get namespace %s: %w", namespace, err)
+		}
+		sim.labels[namespace] = ns.Labels
+	}
+	return sim, nil
+}
+
+// userConfig returns the UserConfig name, the only one when name is empty
+func (s *simulation) userConfig(name string) (*myoperatorv1alpha1.UserConfig, error) {
+	var found []*myoperatorv1alpha1.UserConfig
+	for _, uc := range s.userConfigs {
+		if name == "" || uc.Name == name {
+			found = append(found, uc)
+		}
+	}
+	if len(found) != 1 {
+		return nil, fmt.Errorf("expected one UserConfig in %s, found %d", s.source, len(found))
+	}
+	return found[0], nil
+}
+
+// owner returns the UserConfig provisioning namespace, nil when there is none
+func (s *simulation) owner(namespace string) *myoperatorv1alpha1.UserConfig {
+	for _, uc := range s.userConfigs {
+		for _, name := range usecase.NamespaceNames(uc) {
+			if name == namespace {
+				return uc
+			}
+		}
+	}
+	return nil
+}
+
+// resolve merges the template of uc into a copy of it
+func (s *simulation) resolve(uc *myoperatorv1alpha1.UserConfig) (*myoperatorv1alpha1.UserConfig, error) {
+	if uc.Spec.TemplateRef == nil {
+		return usecase.ApplyTemplate(uc, nil)
+	}
+	template, ok := s.templates[uc.Spec.TemplateRef.Name]
+	if !ok {
+		return nil, fmt.Errorf("UserConfigTemplate %s of UserConfig %s not found in %s", uc.Spec.TemplateRef.Name, uc.Name, s.source)
+	}
+	return usecase.ApplyTemplate(uc, template)
+}
+
+// namespaceLabels returns the labels of namespace read from the cluster, or
+// the ones the operator sets when owner provisions it
+func (s *simulation) namespaceLabels(namespace string, owner *myoperatorv1alpha1.UserConfig) map[string]string {
+	if labels, ok := s.labels[namespace]; ok {
+		return labels
+	}
+	if owner == nil {
+		return nil
+	}
+	return usecase.NamespaceLabels(owner, s.defaults)
+}
+
+// describeDecision explains decision in one line
+func describeDecision(decision evaluator.Decision) string {
+	switch {
+	case !decision.Isolated:
+		return "allowed, no policy selects the endpoint"
+	case decision.Allowed:
+		return fmt.Sprintf("allowed by rule %d of %s", decision.Rule, decision.Policy)
+	default:
+		return "denied, no rule of the selecting policies matches"
+	}
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SimulateNetworkPolicy", func() {
+	run := func(args ...string) (string, error) {
+		out := &bytes.Buffer{}
+		err := SimulateNetworkPolicy(context.Background(), args, out)
+		return out.String(), err
+	}
+
+	It("reports the rule allowing a connection", func() {
+		out, err := run("-f", filepath.Join("..", "..", "examples", "advanced-user-config.yaml"),
+			"-from-namespace", "frontend-namespace", "-from-labels", "app=frontend",
+			"-to-namespace", "devops-advanced", "-port", "443")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(HavePrefix("ALLOWED\n"))
+		Expect(out).To(ContainSubstring("allowed by rule 0 of devops-advanced/devops-advanced"))
+	})
+
+	It("notes the endpoint namespaces no UserConfig manages", func() {
+		out, err := run("-f", filepath.Join("..", "..", "examples", "advanced-user-config.yaml"),
+			"-from-namespace", "frontend-namespace", "-from-labels", "app=frontend",
+			"-to-namespace", "devops-advanced", "-port", "443")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(ContainSubstring("note: namespace frontend-namespace isn't managed by a known UserConfig"))
+		Expect(out).NotTo(ContainSubstring("note: namespace devops-advanced"))
+	})
+
+	Context("with several UserConfigs", func() {
+		var file string
+
+		BeforeEach(func() {
+			file = filepath.Join(GinkgoT().TempDir(), "userconfigs.yaml")
+			Expect(os.WriteFile(file, []byte(`apiVersion: myoperator.01cloud.io/v1alpha1
+kind: OperatorConfig
+metadata:
+  name: cluster
+spec:
+  namespaceLabels:
+    environment: staging
+---
+apiVersion: myoperator.01cloud.io/v1alpha1
+kind: UserConfig
+metadata:
+  name: alice
+spec:
+  networkPolicy:
+  - allowTrafficFrom:
+      peers:
+      - namespaceSelector:
+          matchLabels:
+            environment: staging
+---
+apiVersion: myoperator.01cloud.io/v1alpha1
+kind: UserConfig
+metadata:
+  name: bob
+spec:
+  networkPolicy:
+  - allowTrafficTo:
+      peers:
+      - namespaceSelector:
+          matchLabels:
+            kubernetes.io/metadata.name: database
+`), 0o600)).To(Succeed())
+		})
+
+		It("evaluates the policies of both endpoint namespaces", func() {
+			out, err := run("-f", file, "-userconfig", "alice", "-from-namespace", "bob", "-to-namespace", "alice", "-port", "80")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(HavePrefix("DENIED\n"))
+			Expect(out).To(ContainSubstring("egress:  denied"))
+			Expect(out).NotTo(ContainSubstring("note:"))
+		})
+
+		It("labels the managed namespaces with the OperatorConfig defaults", func() {
+			out, err := run("-f", file, "-userconfig", "alice", "-from-namespace", "alice", "-to-namespace", "alice", "-port", "80")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(ContainSubstring("ingress: allowed by rule 0 of alice/alice"))
+		})
+
+		It("requires the UserConfig to be named", func() {
+			_, err := run("-f", file, "-to-namespace", "alice", "-port", "80")
+			Expect(err).To(MatchError(ContainSubstring("expected one UserConfig")))
+		})
+	})
+
+	It("resolves the template of the UserConfig", func() {
+		out, err := run("-f", filepath.Join("..", "..", "examples", "templated-user-config.yaml"),
+			"-from-namespace", "other", "-to-namespace", "developer-templated", "-port", "80")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(HavePrefix("DENIED\n"))
+	})
+
+	It("fails on invalid labels", func() {
+		_, err := run("-f", filepath.Join("..", "..", "examples", "basic-user-config.yaml"), "-from-labels", "a=b=c", "-port", "80")
+		Expect(err).To(HaveOccurred())
+	})
+})
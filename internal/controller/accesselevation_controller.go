@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+	usecase "01cloud/zoperator/internal/usecase"
+)
+
+const (
+	// elevationFinalizer removes the permissions of an AccessElevation deleted before its expiry
+	elevationFinalizer = "myoperator.01cloud.io/elevation-finalizer"
+
+	// elevationUserConfigIndex indexes AccessElevations by the name of their UserConfig
+	elevationUserConfigIndex = ".spec.userConfig"
+)
+
+// AccessElevationReconciler grants the temporary permissions of an
+// AccessElevation and requeues it to remove them when it expires
+type AccessElevationReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	UC     usecase.UseCase
+}
+
+// +kubebuilder:rbac:groups=myoperator.01cloud.io,resources=accesselevations,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=myoperator.01cloud.io,resources=accesselevations/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=myoperator.01cloud.io,resources=accesselevations/finalizers,verbs=update
+// +kubebuilder:rbac:groups=myoperator.01cloud.io,resources=accessapprovals,verbs=get;list;watch
+
+// Reconcile grants, expires or revokes the permissions of an AccessElevation
+func (r *AccessElevationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	elevation := &myoperatorv1alpha1.AccessElevation{}
+	if err := r.Get(ctx, req.NamespacedName, elevation); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !elevation.DeletionTimestamp.IsZero() {
+		if !controllerutil.ContainsFinalizer(elevation, elevationFinalizer) {
+			return ctrl.Result{}, nil
+		}
+		if err := r.UC.RevokeAccessElevation(ctx, elevation, time.Now()); err != nil {
+			return ctrl.Result{}, err
+		}
+		controllerutil.RemoveFinalizer(elevation, elevationFinalizer)
+		if err := r.Update(ctx, elevation); err != nil {
+			log.FromContext(ctx).Error(err, errUpdateFinalizer)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if !controllerutil.ContainsFinalizer(elevation, elevationFinalizer) {
+		controllerutil.AddFinalizer(elevation, elevationFinalizer)
+		if err := r.Update(ctx, elevation); err != nil {
+			log.FromContext(ctx).Error(err, errUpdateFinalizer)
+			return ctrl.Result{}, err
+		}
+	}
+
+	remaining, reconcileErr := r.UC.ReconcileAccessElevation(ctx, elevation, time.Now())
+
+	condition := metav1.Condition{
+		Type:               myoperatorv1alpha1.ReadyCondition,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Granted",
+		Message:            fmt.Sprintf("Permissions granted until %s", formatTime(elevation.Status.ExpiryTime)),
+		ObservedGeneration: elevation.Generation,
+	}
+	switch elevation.Status.Phase {
+	case myoperatorv1alpha1.ElevationExpired:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Expired"
+		condition.Message = fmt.Sprintf("Permissions removed at %s", formatTime(elevation.Status.ExpiryTime))
+	case myoperatorv1alpha1.ElevationPending:
+		if approval := elevation.Status.Approval; approval != nil && approval.Approver == "" {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = "ApprovalRequired"
+			condition.Message = fmt.Sprintf("Waiting for an AccessApproval of spec hash %s: %s", approval.SpecHash, strings.Join(approval.Reasons, ", "))
+		}
+	}
+	if reconcileErr != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Error"
+		condition.Message = fmt.Sprintf("Failed to reconcile access elevation: %v", reconcileErr)
+	}
+	meta.SetStatusCondition(&elevation.Status.Conditions, condition)
+
+	if err := r.Status().Update(ctx, elevation); err != nil {
+		log.FromContext(ctx).Error(err, errUpdateStatus)
+		return ctrl.Result{}, err
+	}
+
+	// A missing UserConfig is picked up by the UserConfig watch once created
+	if apierrors.IsNotFound(reconcileErr) {
+		return ctrl.Result{}, nil
+	}
+	if reconcileErr != nil {
+		return ctrl.Result{}, reconcileErr
+	}
+	return ctrl.Result{RequeueAfter: remaining}, nil
+}
+
+// formatTime formats t as RFC 3339, or "unknown" when unset
+func formatTime(t *metav1.Time) string {
+	if t == nil {
+		return "unknown"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// elevationsForUserConfig enqueues the AccessElevations of a UserConfig
+func (r *AccessElevationReconciler) elevationsForUserConfig(ctx context.Context, userConfig client.Object) []reconcile.Request {
+	elevations := &myoperatorv1alpha1.AccessElevationList{}
+	if err := r.List(ctx, elevations, client.MatchingFields{elevationUserConfigIndex: userConfig.GetName()}); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list AccessElevations for UserConfig", "userConfig", userConfig.GetName())
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(elevations.Items))
+	for _, item := range elevations.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&item)})
+	}
+	return requests
+}
+
+// elevationForApproval enqueues the AccessElevation approved by an AccessApproval
+func (r *AccessElevationReconciler) elevationForApproval(_ context.Context, obj client.Object) []reconcile.Request {
+	approval, ok := obj.(*myoperatorv1alpha1.AccessApproval)
+	if !ok || approval.Spec.AccessElevation == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: client.ObjectKey{Name: approval.Spec.AccessElevation}}}
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *AccessElevationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &myoperatorv1alpha1.AccessElevation{}, elevationUserConfigIndex, func(obj client.Object) []string {
+		return []string{obj.(*myoperatorv1alpha1.AccessElevation).Spec.UserConfig}
+	}); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&myoperatorv1alpha1.AccessElevation{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&myoperatorv1alpha1.UserConfig{}, handler.EnqueueRequestsFromMapFunc(r.elevationsForUserConfig),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&myoperatorv1alpha1.AccessApproval{}, handler.EnqueueRequestsFromMapFunc(r.elevationForApproval)).
+		Complete(r)
+}
@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+	usecase "01cloud/zoperator/internal/usecase"
+)
+
+// OperatorConfigReconciler applies the OperatorConfig singleton to the
+// defaults shared by the use case. When they change, every UserConfig is sent
+// to DefaultsChanged so the UserConfig controller reconciles it again, every
+// Team to TeamsChanged, and every TenantBudget to BudgetsChanged so the
+// default quota is counted again.
+type OperatorConfigReconciler struct {
+	client.Client
+	Scheme          *runtime.Scheme
+	Defaults        *usecase.OperatorDefaults
+	DefaultsChanged chan<- event.GenericEvent
+	TeamsChanged    chan<- event.GenericEvent
+	BudgetsChanged  chan<- event.GenericEvent
+}
+
+// +kubebuilder:rbac:groups=myoperator.01cloud.io,resources=operatorconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=myoperator.01cloud.io,resources=operatorconfigs/status,verbs=get;update;patch
+
+// Reconcile applies the OperatorConfig, or the built-in defaults once it is
+// deleted. An invalid spec is reported in its Ready condition and leaves the
+// applied defaults in place.
+func (r *OperatorConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if req.Name != myoperatorv1alpha1.OperatorConfigName {
+		return ctrl.Result{}, nil
+	}
+
+	config := &myoperatorv1alpha1.OperatorConfig{}
+	if err := r.Get(ctx, req.NamespacedName, config); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, r.apply(ctx, myoperatorv1alpha1.OperatorConfigSpec{})
+	}
+
+	condition := metav1.Condition{
+		Type:               myoperatorv1alpha1.ReadyCondition,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Applied",
+		Message:            "OperatorConfig applied",
+		ObservedGeneration: config.Generation,
+	}
+	validationErr := usecase.ValidateOperatorConfig(&config.Spec)
+	if validationErr != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Invalid"
+		condition.Message = fmt.Sprintf("Invalid OperatorConfig, the previous defaults stay applied: %v", validationErr)
+		log.FromContext(ctx).Error(validationErr, "invalid OperatorConfig")
+	} else if err := r.apply(ctx, config.Spec); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if meta.SetStatusCondition(&config.Status.Conditions, condition) {
+		if err := r.Status().Update(ctx, config); err != nil {
+			log.FromContext(ctx).Error(err, errUpdateStatus)
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// apply replaces the defaults with spec and requeues every UserConfig, Team
+// and TenantBudget when they changed
+func (r *OperatorConfigReconciler) apply(ctx context.Context, spec myoperatorv1alpha1.OperatorConfigSpec) error {
+	// List first so a failure is retried before the change is recorded
+	userConfigs := &myoperatorv1alpha1.UserConfigList{}
+	if err := r.List(ctx, userConfigs); err != nil {
+		return fmt.Errorf("failed to list UserConfigs: %w", err)
+	}
+	teams := &myoperatorv1alpha1.TeamList{}
+	if err := r.List(ctx, teams); err != nil {
+		return fmt.Errorf("failed to list Teams: %w", err)
+	}
+	budgets := &myoperatorv1alpha1.TenantBudgetList{}
+	if err := r.List(ctx, budgets); err != nil {
+		return fmt.Errorf("failed to list TenantBudgets: %w", err)
+	}
+	if !r.Defaults.Set(spec) {
+		return nil
+	}
+	log.FromContext(ctx).Info("OperatorConfig defaults changed, reconciling every UserConfig and Team",
+		"userConfigs", len(userConfigs.Items), "teams", len(teams.Items))
+	for i := range userConfigs.Items {
+		if err := requeue(ctx, r.DefaultsChanged, &userConfigs.Items[i]); err != nil {
+			return err
+		}
+	}
+	for i := range teams.Items {
+		if err := requeue(ctx, r.TeamsChanged, &teams.Items[i]); err != nil {
+			return err
+		}
+	}
+	for i := range budgets.Items {
+		if err := requeue(ctx, r.BudgetsChanged, &budgets.Items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// requeue sends obj to the controller watching events, if any
+func requeue(ctx context.Context, events chan<- event.GenericEvent, obj client.Object) error {
+	if events == nil {
+		return nil
+	}
+	select {
+	case events <- event.GenericEvent{Object: obj}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *OperatorConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&myoperatorv1alpha1.OperatorConfig{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
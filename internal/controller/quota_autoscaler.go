@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+	usecase "01cloud/zoperator/internal/usecase"
+)
+
+// QuotaAutoscaler periodically adjusts the autoscaled quotas of every
+// UserConfig to their observed usage. It runs as a manager Runnable on the
+// leader only, as usage is sampled on a schedule rather than on events.
+type QuotaAutoscaler struct {
+	client.Client
+	UC usecase.UseCase
+
+	// Interval between two samplings of the quota usage
+	Interval time.Duration
+}
+
+var (
+	_ manager.Runnable               = &QuotaAutoscaler{}
+	_ manager.LeaderElectionRunnable = &QuotaAutoscaler{}
+)
+
+// Start samples the quotas every Interval until ctx is done
+func (a *QuotaAutoscaler) Start(ctx context.Context) error {
+	ticker := time.NewTicker(a.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			a.autoscale(ctx)
+		}
+	}
+}
+
+// NeedLeaderElection makes sure a single replica adjusts the quotas
+func (a *QuotaAutoscaler) NeedLeaderElection() bool {
+	return true
+}
+
+// autoscale adjusts the quotas of every UserConfig, logging the failures so
+// that one UserConfig doesn't hold the others back
+func (a *QuotaAutoscaler) autoscale(ctx context.Context) {
+	logger := log.FromContext(ctx).WithName("quota-autoscaler")
+	userConfigs := &myoperatorv1alpha1.UserConfigList{}
+	if err := a.List(ctx, userConfigs); err != nil {
+		logger.Error(err, "failed to list UserConfigs")
+		return
+	}
+
+	now := time.Now()
+	for i := range userConfigs.Items {
+		userConfig := &userConfigs.Items[i]
+		if !userConfig.DeletionTimestamp.IsZero() {
+			continue
+		}
+		patch := client.MergeFrom(userConfig.DeepCopy())
+		if err := a.UC.AutoscaleQuotas(ctx, userConfig, now); err != nil {
+			logger.Error(err, "failed to autoscale quotas", "userConfig", userConfig.Name)
+			continue
+		}
+		if err := a.Status().Patch(ctx, userConfig, patch); err != nil {
+			logger.Error(err, errUpdateStatus, "userConfig", userConfig.Name)
+		}
+	}
+}
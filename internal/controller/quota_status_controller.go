@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+	usecase "01cloud/zoperator/internal/usecase"
+)
+
+// QuotaStatusReconciler mirrors the usage of the ResourceQuota managed for a
+// UserConfig into the UserConfig status. It runs separately from the
+// UserConfigReconciler so that usage changes don't re-run the whole
+// provisioning pipeline (and rotate the kubeconfig token).
+type QuotaStatusReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	UC     usecase.UseCase
+}
+
+// Reconcile refreshes the quota usage status of the UserConfig owning the ResourceQuota
+func (r *QuotaStatusReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	// The managed ResourceQuota is named after its UserConfig in every namespace
+	userConfig := &myoperatorv1alpha1.UserConfig{}
+	if err := r.Get(ctx, client.ObjectKey{Name: req.Name}, userConfig); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !userConfig.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	patch := client.MergeFrom(userConfig.DeepCopy())
+	if err := r.UC.ReconcileQuotaStatus(ctx, userConfig); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.Status().Patch(ctx, userConfig, patch); err != nil {
+		log.FromContext(ctx).Error(err, errUpdateStatus)
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *QuotaStatusReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("userconfig-quota-status").
+		For(&corev1.ResourceQuota{}, builder.WithPredicates(managedQuotaPredicate())).
+		Complete(r)
+}
+
+// managedQuotaPredicate only admits the ResourceQuota created by ReconcileResourceQuota
+func managedQuotaPredicate() predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		labels := obj.GetLabels()
+		return labels[usecase.LabelManagedBy] == usecase.ManagedByValue &&
+			obj.GetName() == labels[usecase.LabelUserConfigName]
+	})
+}
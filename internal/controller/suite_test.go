@@ -19,6 +19,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+	"01cloud/zoperator/internal/usecase"
 
 	sealedsecretsv1alpha1 "github.com/bitnami-labs/sealed-secrets/pkg/apis/sealedsecrets/v1alpha1"
 	// +kubebuilder:scaffold:imports
@@ -81,9 +82,12 @@ var _ = BeforeSuite(func() {
 	Expect(err).ToNot(HaveOccurred())
 
 	// Setup your controller
+	recorder := k8sManager.GetEventRecorderFor("userconfig-controller")
 	err = (&UserConfigReconciler{
-		Client: k8sManager.GetClient(),
-		Scheme: k8sManager.GetScheme(),
+		Client:   k8sManager.GetClient(),
+		Scheme:   k8sManager.GetScheme(),
+		UC:       usecase.NewUserConfigUseCase(k8sManager.GetClient(), k8sManager.GetScheme(), recorder, usecase.DefaultConfig(), nil),
+		Recorder: recorder,
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+	usecase "01cloud/zoperator/internal/usecase"
+)
+
+// teamMemberIndex indexes Teams by the UserConfig names of their members
+const teamMemberIndex = ".spec.members.userConfig"
+
+// TeamReconciler reconciles a Team object. The shared namespace and the
+// objects in it are owned by the Team and garbage collected with it.
+type TeamReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	UC       usecase.UseCase
+	Recorder record.EventRecorder
+
+	// DefaultsChanged receives the Teams to reconcile again after the
+	// OperatorConfig defaults changed
+	DefaultsChanged <-chan event.GenericEvent
+}
+
+// +kubebuilder:rbac:groups=myoperator.01cloud.io,resources=teams,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=myoperator.01cloud.io,resources=teams/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=myoperator.01cloud.io,resources=teams/finalizers,verbs=update
+// +kubebuilder:rbac:groups=myoperator.01cloud.io,resources=accessapprovals,verbs=get;list;watch
+
+// Reconcile provisions the shared namespace of a Team and binds its members
+func (r *TeamReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	team := &myoperatorv1alpha1.Team{}
+	if err := r.Get(ctx, req.NamespacedName, team); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !team.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	// Hold the Teams granting elevated access until an approver releases them
+	approved, err := r.UC.ReconcileTeamApproval(ctx, team)
+	if err != nil {
+		r.Recorder.Event(team, corev1.EventTypeWarning, eventReasonReconcileFailed, err.Error())
+		return ctrl.Result{}, err
+	}
+	if !approved {
+		team.Status.State = "Pending"
+		meta.SetStatusCondition(&team.Status.Conditions, metav1.Condition{
+			Type:               myoperatorv1alpha1.PendingCondition,
+			Status:             metav1.ConditionTrue,
+			Reason:             "ApprovalRequired",
+			Message:            fmt.Sprintf("Waiting for an AccessApproval of spec hash %s: %s", team.Status.Approval.SpecHash, strings.Join(team.Status.Approval.Reasons, ", ")),
+			ObservedGeneration: team.Generation,
+		})
+		if err := r.Status().Update(ctx, team); err != nil {
+			log.FromContext(ctx).Error(err, errUpdateStatus)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+	if meta.IsStatusConditionTrue(team.Status.Conditions, myoperatorv1alpha1.PendingCondition) {
+		meta.SetStatusCondition(&team.Status.Conditions, metav1.Condition{
+			Type:               myoperatorv1alpha1.PendingCondition,
+			Status:             metav1.ConditionFalse,
+			Reason:             "Approved",
+			Message:            fmt.Sprintf("Approved by %s", team.Status.Approval.Approver),
+			ObservedGeneration: team.Generation,
+		})
+	}
+
+	reconcileErr := r.UC.ReconcileTeam(ctx, team)
+
+	condition := metav1.Condition{
+		Type:               myoperatorv1alpha1.ReadyCondition,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Reconciled",
+		Message:            "Team reconciled successfully",
+		ObservedGeneration: team.Generation,
+	}
+	team.Status.State = "Active"
+	if reconcileErr != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Error"
+		condition.Message = fmt.Sprintf("Failed to reconcile team: %v", reconcileErr)
+		team.Status.State = "Error"
+		r.Recorder.Event(team, corev1.EventTypeWarning, eventReasonReconcileFailed, condition.Message)
+	}
+	meta.SetStatusCondition(&team.Status.Conditions, condition)
+
+	if err := r.Status().Update(ctx, team); err != nil {
+		log.FromContext(ctx).Error(err, errUpdateStatus)
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, reconcileErr
+}
+
+// teamsForUserConfig enqueues every Team listing the UserConfig as a member
+func (r *TeamReconciler) teamsForUserConfig(ctx context.Context, userConfig client.Object) []reconcile.Request {
+	teams := &myoperatorv1alpha1.TeamList{}
+	if err := r.List(ctx, teams, client.MatchingFields{teamMemberIndex: userConfig.GetName()}); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list Teams for UserConfig", "userConfig", userConfig.GetName())
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(teams.Items))
+	for _, item := range teams.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&item)})
+	}
+	return requests
+}
+
+// teamForApproval enqueues the Team approved by an AccessApproval
+func (r *TeamReconciler) teamForApproval(_ context.Context, obj client.Object) []reconcile.Request {
+	approval, ok := obj.(*myoperatorv1alpha1.AccessApproval)
+	if !ok || approval.Spec.Team == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: client.ObjectKey{Name: approval.Spec.Team}}}
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *TeamReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &myoperatorv1alpha1.Team{}, teamMemberIndex, func(obj client.Object) []string {
+		team := obj.(*myoperatorv1alpha1.Team)
+		members := make([]string, 0, len(team.Spec.Members))
+		for _, member := range team.Spec.Members {
+			members = append(members, member.UserConfig)
+		}
+		return members
+	}); err != nil {
+		return err
+	}
+
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&myoperatorv1alpha1.Team{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Owns(&corev1.Namespace{}).
+		Owns(&rbacv1.Role{}).
+		Owns(&rbacv1.RoleBinding{}).
+		Watches(&myoperatorv1alpha1.UserConfig{}, handler.EnqueueRequestsFromMapFunc(r.teamsForUserConfig),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&myoperatorv1alpha1.AccessApproval{}, handler.EnqueueRequestsFromMapFunc(r.teamForApproval))
+	if r.DefaultsChanged != nil {
+		b = b.WatchesRawSource(source.Channel(r.DefaultsChanged, &handler.EnqueueRequestForObject{}))
+	}
+	return b.Complete(r)
+}
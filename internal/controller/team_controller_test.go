@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+	"01cloud/zoperator/internal/usecase"
+)
+
+var _ = Describe("Team Controller", func() {
+	var teamName, memberName string
+	var team *myoperatorv1alpha1.Team
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		randomVal := fmt.Sprintf("%d", GinkgoRandomSeed())
+		teamName = "test-team-" + randomVal
+		memberName = "test-member-" + randomVal
+
+		member := &myoperatorv1alpha1.UserConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: memberName},
+			Spec: myoperatorv1alpha1.UserConfigSpec{
+				Identity: myoperatorv1alpha1.Identity{
+					Username: memberName,
+					Contact:  "test@example.com",
+					Groups:   []string{"viewer"},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, member)).To(Succeed())
+
+		team = &myoperatorv1alpha1.Team{
+			ObjectMeta: metav1.ObjectMeta{Name: teamName},
+			Spec: myoperatorv1alpha1.TeamSpec{
+				Roles: []myoperatorv1alpha1.TeamRole{
+					{
+						Name: "developer",
+						Resources: []myoperatorv1alpha1.ResourcePermission{
+							{Resource: "deployment", Operation: "CRUD"},
+						},
+					},
+				},
+				Members: []myoperatorv1alpha1.TeamMember{
+					{UserConfig: memberName, Role: "developer"},
+					{UserConfig: "does-not-exist", Role: "developer"},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, team)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(k8sClient.Delete(ctx, team)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, &myoperatorv1alpha1.UserConfig{ObjectMeta: metav1.ObjectMeta{Name: memberName}})).To(Succeed())
+	})
+
+	It("should provision the shared namespace and bind the members", func() {
+		controllerReconciler := &TeamReconciler{
+			Client:   k8sManager.GetClient(),
+			Scheme:   k8sManager.GetScheme(),
+			UC:       usecase.NewUserConfigUseCase(k8sManager.GetClient(), k8sManager.GetScheme(), record.NewFakeRecorder(100), usecase.DefaultConfig(), nil),
+			Recorder: record.NewFakeRecorder(100),
+		}
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: teamName},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		namespace := &corev1.Namespace{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: teamName}, namespace)).To(Succeed())
+
+		roleBinding := &rbacv1.RoleBinding{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: teamName + "-developer", Namespace: teamName}, roleBinding)).To(Succeed())
+		Expect(roleBinding.Subjects).To(ContainElement(rbacv1.Subject{Kind: "User", Name: memberName}))
+
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: teamName}, team)).To(Succeed())
+		Expect(team.Status.Members).To(Equal(int32(1)))
+		Expect(team.Status.MissingMembers).To(ConsistOf("does-not-exist"))
+	})
+})
@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+	usecase "01cloud/zoperator/internal/usecase"
+)
+
+// TenantBudgetReconciler sums the quota of every UserConfig and Team against
+// the TenantBudgets. A UserConfig may be over-committed in several budgets, so
+// every reconcile refreshes all of them along with the OverCommitted condition
+// of every UserConfig and Team.
+type TenantBudgetReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	UC     usecase.UseCase
+
+	// DefaultsChanged receives the TenantBudgets to reconcile again after the
+	// OperatorConfig defaults changed, the default quota being counted
+	DefaultsChanged <-chan event.GenericEvent
+}
+
+// +kubebuilder:rbac:groups=myoperator.01cloud.io,resources=tenantbudgets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=myoperator.01cloud.io,resources=tenantbudgets/status,verbs=get;update;patch
+
+// Reconcile refreshes the status of the TenantBudgets and the OverCommitted
+// condition of the UserConfigs and Teams
+func (r *TenantBudgetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if err := r.UC.ReconcileTenantBudgets(ctx); err != nil {
+		log.FromContext(ctx).Error(err, "failed to reconcile tenant budgets")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// budgetsForQuota enqueues every TenantBudget when the quota of a UserConfig or a Team may have changed
+func (r *TenantBudgetReconciler) budgetsForQuota(ctx context.Context, _ client.Object) []reconcile.Request {
+	budgets := &myoperatorv1alpha1.TenantBudgetList{}
+	if err := r.List(ctx, budgets); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list TenantBudgets")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(budgets.Items))
+	for _, item := range budgets.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&item)})
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *TenantBudgetReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&myoperatorv1alpha1.TenantBudget{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&myoperatorv1alpha1.UserConfig{}, handler.EnqueueRequestsFromMapFunc(r.budgetsForQuota),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&myoperatorv1alpha1.UserConfigTemplate{}, handler.EnqueueRequestsFromMapFunc(r.budgetsForQuota),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&myoperatorv1alpha1.Team{}, handler.EnqueueRequestsFromMapFunc(r.budgetsForQuota),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}))
+	if r.DefaultsChanged != nil {
+		b = b.WatchesRawSource(source.Channel(r.DefaultsChanged, &handler.EnqueueRequestForObject{}))
+	}
+	return b.Complete(r)
+}
@@ -3,33 +3,50 @@ package controller
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	sealedsecretsv1alpha1 "github.com/bitnami-labs/sealed-secrets/pkg/apis/sealedsecrets/v1alpha1"
 
 	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+	"01cloud/zoperator/internal/metrics"
 	usecase "01cloud/zoperator/internal/usecase"
 )
 
 // UserConfigReconciler reconciles a UserConfig object
 type UserConfigReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
-	UC     usecase.UseCase
+	Scheme   *runtime.Scheme
+	UC       usecase.UseCase
+	Recorder record.EventRecorder
+
+	// DefaultsChanged receives the UserConfigs to reconcile again after the
+	// OperatorConfig defaults changed
+	DefaultsChanged <-chan event.GenericEvent
 }
 
 const (
 	// userConfigFinalizer is the finalizer name used to prevent premature deletion
 	userConfigFinalizer = "myoperator.01cloud.io/finalizer"
 
+	// templateRefIndex indexes UserConfigs by the name of their UserConfigTemplate
+	templateRefIndex = ".spec.templateRef.name"
+
 	// Error messages
 	errGetUserConfig    = "failed to get UserConfig"
 	errUpdateFinalizer  = "failed to update finalizer"
@@ -37,12 +54,18 @@ const (
 	errGetNamespace     = "failed to get namespace"
 	errReconcileSecrets = "failed to reconcile sealed secrets"
 	errUpdateStatus     = "failed to update status"
+
+	// Event reasons
+	eventReasonReconciled      = "Reconciled"
+	eventReasonReconcileFailed = "ReconcileFailed"
 )
 
 // RBAC permissions required for the controller
 // +kubebuilder:rbac:groups=myoperator.01cloud.io,resources=userconfigs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=myoperator.01cloud.io,resources=userconfigs/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=myoperator.01cloud.io,resources=userconfigs/finalizers,verbs=update
+// +kubebuilder:rbac:groups=myoperator.01cloud.io,resources=userconfigtemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=myoperator.01cloud.io,resources=accessapprovals,verbs=get;list;watch
 // +kubebuilder:rbac:groups=bitnami.com,resources=sealedsecrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=resourcequotas,verbs=get;list;watch;create;update;patch;delete
@@ -51,6 +74,8 @@ const (
 // +kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=serviceaccounts/token,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=cilium.io,resources=ciliumnetworkpolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=projectcalico.org,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=bitnami.com,resources=sealedsecrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles,verbs=get;list;watch;create;update;delete;patch
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch;create;update;delete;patch
@@ -81,6 +106,17 @@ const (
 
 // +kubebuilder:rbac:groups=core,resources=persistentvolumes/status,verbs=get;update;patch
 
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+// The operator must hold every cluster scoped read permission it grants through spec.clusterPermissions
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles;clusterrolebindings,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses;csidrivers;csinodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingressclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=scheduling.k8s.io,resources=priorityclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=node.k8s.io,resources=runtimeclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch
+
 // Reconcile handles the reconciliation loop for UserConfig resources
 func (r *UserConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	// Get UserConfig instance
@@ -111,41 +147,103 @@ func (r *UserConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		}
 	}
 
+	// Rewrite the quotas stored with a deprecated spelling
+	if err := r.UC.MigrateResourceQuotas(ctx, userConfig); err != nil {
+		userConfig = r.updateErrorStatus(ctx, userConfig, fmt.Errorf("Failed to migrate resource quotas: %v", err))
+		return ctrl.Result{}, err
+	}
+
+	// Merge the referenced UserConfigTemplate into a resolved copy. From here on
+	// only the status is written back, so the merged spec never reaches the API server.
+	resolved, err := r.UC.ResolveTemplate(ctx, userConfig)
+	if err != nil {
+		r.updateErrorStatus(ctx, userConfig, fmt.Errorf("Failed to resolve template: %v", err))
+		return ctrl.Result{}, err
+	}
+	userConfig = resolved
+
+	// Hold the UserConfigs requesting elevated access until an approver releases them
+	approved, err := r.UC.ReconcileApproval(ctx, userConfig)
+	if err != nil {
+		r.updateErrorStatus(ctx, userConfig, fmt.Errorf("Failed to check approval: %v", err))
+		return ctrl.Result{}, err
+	}
+	if !approved {
+		userConfig.Status.State = "Pending"
+		meta.SetStatusCondition(&userConfig.Status.Conditions, metav1.Condition{
+			Type:   myoperatorv1alpha1.PendingCondition,
+			Status: metav1.ConditionTrue,
+			Reason: "ApprovalRequired",
+			Message: fmt.Sprintf("Waiting for an AccessApproval of spec hash %s: %s",
+				userConfig.Status.Approval.SpecHash, strings.Join(userConfig.Status.Approval.Reasons, ", ")),
+		})
+		if err := r.Status().Update(ctx, userConfig); err != nil {
+			log.FromContext(ctx).Error(err, errUpdateStatus)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+	if meta.IsStatusConditionTrue(userConfig.Status.Conditions, myoperatorv1alpha1.PendingCondition) {
+		meta.SetStatusCondition(&userConfig.Status.Conditions, metav1.Condition{
+			Type:    myoperatorv1alpha1.PendingCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  "Approved",
+			Message: fmt.Sprintf("Approved by %s", userConfig.Status.Approval.Approver),
+		})
+	}
+
 	// Create/Update namespace
-	if err := r.UC.ReconcileNamespace(ctx, userConfig); err != nil {
+	if err := r.runStep("namespace", func() error { return r.UC.ReconcileNamespace(ctx, userConfig) }); err != nil {
 		r.updateErrorStatus(ctx, userConfig, fmt.Errorf("Failed to reconcile namespace: %v", err))
 		return ctrl.Result{}, err
 	}
 
 	// Create/Update sealed secrets
-	if err := r.UC.ReconcileSealedSecrets(ctx, userConfig); err != nil {
+	if err := r.runStep("sealed_secrets", func() error { return r.UC.ReconcileSealedSecrets(ctx, userConfig) }); err != nil {
 		userConfig = r.updateErrorStatus(ctx, userConfig, fmt.Errorf("Failed to reconcile sealed secrets: %v", err))
 		return ctrl.Result{}, err
 	}
 
 	// After namespace reconciliation
-	if err := r.UC.ReconcileRBAC(ctx, userConfig); err != nil {
+	if err := r.runStep("rbac", func() error { return r.UC.ReconcileRBAC(ctx, userConfig) }); err != nil {
 		userConfig = r.updateErrorStatus(ctx, userConfig, fmt.Errorf("Failed to reconcile RBAC: %v", err))
 		return ctrl.Result{}, err
 	}
 
-	if err := r.UC.ReconcileLimitRange(ctx, userConfig); err != nil {
+	if err := r.runStep("namespace_access", func() error { return r.UC.ReconcileNamespaceAccess(ctx, userConfig) }); err != nil {
+		userConfig = r.updateErrorStatus(ctx, userConfig, fmt.Errorf("Failed to reconcile namespace access: %v", err))
+		return ctrl.Result{}, err
+	}
+
+	if err := r.runStep("cluster_rbac", func() error { return r.UC.ReconcileClusterRBAC(ctx, userConfig) }); err != nil {
+		userConfig = r.updateErrorStatus(ctx, userConfig, fmt.Errorf("Failed to reconcile cluster RBAC: %v", err))
+		return ctrl.Result{}, err
+	}
+
+	if err := r.runStep("limit_range", func() error { return r.UC.ReconcileLimitRange(ctx, userConfig) }); err != nil {
 		userConfig = r.updateErrorStatus(ctx, userConfig, fmt.Errorf("Failed to reconcile LimitRange: %v", err))
 		return ctrl.Result{}, err
 	}
 
 	// Generate and save kubeconfig
-	if err := r.UC.GenerateAndSaveKubeconfig(ctx, userConfig); err != nil {
+	if err := r.runStep("kubeconfig", func() error { return r.UC.GenerateAndSaveKubeconfig(ctx, userConfig) }); err != nil {
 		userConfig = r.updateErrorStatus(ctx, userConfig, fmt.Errorf("Failed to generate and save kubeconfig: %v", err))
 		return ctrl.Result{}, err
 	}
 
 	// create network policy
-	if err := r.UC.ReconcileNetworkPolicies(ctx, userConfig); err != nil {
+	if err := r.runStep("network_policies", func() error { return r.UC.ReconcileNetworkPolicies(ctx, userConfig) }); err != nil {
 		userConfig = r.updateErrorStatus(ctx, userConfig, fmt.Errorf("Failed to reconcile network policies: %v", err))
 		return ctrl.Result{}, err
 	}
 
+	// Mirror quota usage into the status
+	if err := r.runStep("quota_status", func() error { return r.UC.ReconcileQuotaStatus(ctx, userConfig) }); err != nil {
+		userConfig = r.updateErrorStatus(ctx, userConfig, fmt.Errorf("Failed to reconcile quota status: %v", err))
+		return ctrl.Result{}, err
+	}
+
+	wasActive := userConfig.Status.State == "Active"
 	userConfig.Status.State = "Active"
 	userConfig.Status.Conditions = append(userConfig.Status.Conditions, metav1.Condition{
 		Type:               myoperatorv1alpha1.ReadyCondition,
@@ -159,12 +257,25 @@ func (r *UserConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		log.FromContext(ctx).Error(err, errUpdateStatus)
 		return ctrl.Result{}, err
 	}
+	// Only record the transition to Active, not every successful pass
+	if !wasActive {
+		r.Recorder.Event(userConfig, corev1.EventTypeNormal, eventReasonReconciled, "UserConfig reconciled successfully")
+	}
 
 	return ctrl.Result{}, nil
 }
 
+// runStep runs a single reconcile step and records its duration
+func (r *UserConfigReconciler) runStep(step string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	metrics.ObserveReconcileStep(step, start, err)
+	return err
+}
+
 // updateErrorStatus updates UserConfig status to Error state
 func (r *UserConfigReconciler) updateErrorStatus(ctx context.Context, userConfig *myoperatorv1alpha1.UserConfig, err error) *myoperatorv1alpha1.UserConfig {
+	r.Recorder.Event(userConfig, corev1.EventTypeWarning, eventReasonReconcileFailed, err.Error())
 	userConfig.Status.State = "Error"
 	userConfig.Status.Conditions = append(userConfig.Status.Conditions, metav1.Condition{
 		Type:               myoperatorv1alpha1.ReadyCondition,
@@ -180,12 +291,67 @@ func (r *UserConfigReconciler) updateErrorStatus(ctx context.Context, userConfig
 	return userConfig
 }
 
+// userConfigsForTemplate enqueues every UserConfig referencing the changed template
+func (r *UserConfigReconciler) userConfigsForTemplate(ctx context.Context, template client.Object) []reconcile.Request {
+	userConfigs := &myoperatorv1alpha1.UserConfigList{}
+	if err := r.List(ctx, userConfigs, client.MatchingFields{templateRefIndex: template.GetName()}); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list UserConfigs for template", "template", template.GetName())
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(userConfigs.Items))
+	for _, item := range userConfigs.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&item)})
+	}
+	return requests
+}
+
+// userConfigForApproval enqueues the UserConfig approved by an AccessApproval
+func (r *UserConfigReconciler) userConfigForApproval(_ context.Context, obj client.Object) []reconcile.Request {
+	approval, ok := obj.(*myoperatorv1alpha1.AccessApproval)
+	if !ok || approval.Spec.UserConfig == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: client.ObjectKey{Name: approval.Spec.UserConfig}}}
+}
+
+// userConfigsAllowedAccess enqueues the UserConfigs listed in spec.allowAccessFrom
+// so their access grants follow the target opting in or out
+func (r *UserConfigReconciler) userConfigsAllowedAccess(ctx context.Context, obj client.Object) []reconcile.Request {
+	userConfig, ok := obj.(*myoperatorv1alpha1.UserConfig)
+	if !ok {
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(userConfig.Spec.AllowAccessFrom))
+	for _, name := range userConfig.Spec.AllowAccessFrom {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKey{Name: name}})
+	}
+	return requests
+}
+
 // SetupWithManager sets up the controller with the Manager
 func (r *UserConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &myoperatorv1alpha1.UserConfig{}, templateRefIndex, func(obj client.Object) []string {
+		uc := obj.(*myoperatorv1alpha1.UserConfig)
+		if uc.Spec.TemplateRef == nil {
+			return nil
+		}
+		return []string{uc.Spec.TemplateRef.Name}
+	}); err != nil {
+		return err
+	}
+	// Count the UserConfigs per state from the cache at scrape time
+	metrics.CountUserConfigStates(mgr.GetCache())
+
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&myoperatorv1alpha1.UserConfig{}).
 		Owns(&corev1.Namespace{}).
 		Owns(&sealedsecretsv1alpha1.SealedSecret{}).
-		WithEventFilter(predicate.GenerationChangedPredicate{}).
-		Complete(r)
+		Watches(&myoperatorv1alpha1.UserConfigTemplate{}, handler.EnqueueRequestsFromMapFunc(r.userConfigsForTemplate)).
+		Watches(&myoperatorv1alpha1.UserConfig{}, handler.EnqueueRequestsFromMapFunc(r.userConfigsAllowedAccess)).
+		Watches(&myoperatorv1alpha1.AccessApproval{}, handler.EnqueueRequestsFromMapFunc(r.userConfigForApproval)).
+		WithEventFilter(predicate.GenerationChangedPredicate{})
+	if r.DefaultsChanged != nil {
+		b = b.WatchesRawSource(source.Channel(r.DefaultsChanged, &handler.EnqueueRequestForObject{}))
+	}
+	return b.Complete(r)
 }
@@ -25,12 +25,14 @@ import (
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+	"01cloud/zoperator/internal/usecase"
 
 	sealedsecretsv1alpha1 "github.com/bitnami-labs/sealed-secrets/pkg/apis/sealedsecrets/v1alpha1"
 )
@@ -123,8 +125,10 @@ var _ = Describe("UserConfig Controller", func() {
 		It("should create a namespace when UserConfig is created", func() {
 			// First reconcile
 			controllerReconciler := &UserConfigReconciler{
-				Client: k8sManager.GetClient(),
-				Scheme: k8sManager.GetScheme(),
+				Client:   k8sManager.GetClient(),
+				Scheme:   k8sManager.GetScheme(),
+				UC:       usecase.NewUserConfigUseCase(k8sManager.GetClient(), k8sManager.GetScheme(), record.NewFakeRecorder(100), usecase.DefaultConfig(), nil),
+				Recorder: record.NewFakeRecorder(100),
 			}
 			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
 				NamespacedName: typeNamespacedName,
@@ -160,8 +164,10 @@ var _ = Describe("UserConfig Controller", func() {
 		It("should create a sealed-secret when UserConfig is created", func() {
 			// First reconcile
 			controllerReconciler := &UserConfigReconciler{
-				Client: k8sManager.GetClient(),
-				Scheme: k8sManager.GetScheme(),
+				Client:   k8sManager.GetClient(),
+				Scheme:   k8sManager.GetScheme(),
+				UC:       usecase.NewUserConfigUseCase(k8sManager.GetClient(), k8sManager.GetScheme(), record.NewFakeRecorder(100), usecase.DefaultConfig(), nil),
+				Recorder: record.NewFakeRecorder(100),
 			}
 			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
 				NamespacedName: typeNamespacedName,
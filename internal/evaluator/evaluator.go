@@ -0,0 +1,294 @@
+// Package evaluator simulates NetworkPolicies against a traffic query. It
+// answers whether a connection from a source to a destination endpoint is
+// allowed by the policies of their namespaces, following the Kubernetes
+// semantics: a pod is isolated in a direction once a policy of that type
+// selects it, and isolated pods only accept the traffic some rule allows.
+package evaluator
+
+import (
+	"fmt"
+	"net"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Endpoint is the source or destination of a connection. An endpoint without
+// namespace is outside the cluster and only matched by ipBlocks.
+type Endpoint struct {
+	// Namespace of the pod
+	Namespace string
+	// NamespaceLabels of the namespace, kubernetes.io/metadata.name is implied
+	NamespaceLabels map[string]string
+	// PodLabels of the pod
+	PodLabels map[string]string
+	// IP of the endpoint, required to match ipBlocks
+	IP string
+	// NamedPorts maps the named container ports of a destination pod to their number
+	NamedPorts map[string]int32
+}
+
+// Query is a connection to evaluate
+type Query struct {
+	Source      Endpoint
+	Destination Endpoint
+	// Port on the destination, a number or a named port of the destination
+	Port intstr.IntOrString
+	// Protocol of the connection, defaults to TCP
+	Protocol corev1.Protocol
+}
+
+// Decision is the verdict of one direction of a connection
+type Decision struct {
+	// Isolated is set when a policy of this direction selects the endpoint
+	Isolated bool
+	// Allowed is set when the endpoint isn't isolated or a rule matched
+	Allowed bool
+	// Policy is the namespace/name of the policy whose rule matched
+	Policy string
+	// Rule is the index of the matching rule in the policy
+	Rule int
+}
+
+// Result is the verdict of a connection
+type Result struct {
+	// Allowed is set when both the egress of the source and the ingress of the destination allow it
+	Allowed bool
+	Egress  Decision
+	Ingress Decision
+}
+
+// Evaluate decides whether the connection of q is allowed by policies
+func Evaluate(policies []networkingv1.NetworkPolicy, q Query) (Result, error) {
+	if q.Protocol == "" {
+		q.Protocol = corev1.ProtocolTCP
+	}
+	port, err := resolvePort(q.Port, q.Destination)
+	if err != nil {
+		return Result{}, err
+	}
+
+	egress, err := decide(policies, networkingv1.PolicyTypeEgress, q.Source, func(policy *networkingv1.NetworkPolicy, i int) (bool, error) {
+		rule := policy.Spec.Egress[i]
+		return matchRule(policy.Namespace, rule.To, rule.Ports, q.Destination, q, port)
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	ingress, err := decide(policies, networkingv1.PolicyTypeIngress, q.Destination, func(policy *networkingv1.NetworkPolicy, i int) (bool, error) {
+		rule := policy.Spec.Ingress[i]
+		return matchRule(policy.Namespace, rule.From, rule.Ports, q.Source, q, port)
+	})
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{
+		Allowed: egress.Allowed && ingress.Allowed,
+		Egress:  egress,
+		Ingress: ingress,
+	}, nil
+}
+
+// decide evaluates the policies of direction selecting endpoint, matchRule
+// reporting whether the rule i of a policy allows the connection
+func decide(policies []networkingv1.NetworkPolicy, direction networkingv1.PolicyType, endpoint Endpoint,
+	matchRule func(policy *networkingv1.NetworkPolicy, i int) (bool, error)) (Decision, error) {
+	decision := Decision{Allowed: true, Rule: -1}
+	if endpoint.Namespace == "" {
+		return decision, nil
+	}
+
+	for i := range policies {
+		policy := &policies[i]
+		if policy.Namespace != endpoint.Namespace || !hasPolicyType(policy, direction) {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(&policy.Spec.PodSelector)
+		if err != nil {
+			return Decision{}, fmt.Errorf("invalid pod selector of policy %s: %w", policy.Name, err)
+		}
+		if !selector.Matches(labels.Set(endpoint.PodLabels)) {
+			continue
+		}
+		if !decision.Isolated {
+			decision = Decision{Isolated: true, Rule: -1}
+		}
+
+		rules := len(policy.Spec.Ingress)
+		if direction == networkingv1.PolicyTypeEgress {
+			rules = len(policy.Spec.Egress)
+		}
+		for r := 0; r < rules; r++ {
+			matched, err := matchRule(policy, r)
+			if err != nil {
+				return Decision{}, fmt.Errorf("policy %s: %w", policy.Name, err)
+			}
+			if matched {
+				return Decision{Isolated: true, Allowed: true, Policy: policy.Namespace + "/" + policy.Name, Rule: r}, nil
+			}
+		}
+	}
+
+	return decision, nil
+}
+
+// hasPolicyType reports whether policy applies to direction. Policies without
+// types apply to ingress, and to egress when they have egress rules.
+func hasPolicyType(policy *networkingv1.NetworkPolicy, direction networkingv1.PolicyType) bool {
+	if len(policy.Spec.PolicyTypes) == 0 {
+		return direction == networkingv1.PolicyTypeIngress || len(policy.Spec.Egress) > 0
+	}
+	for _, t := range policy.Spec.PolicyTypes {
+		if t == direction {
+			return true
+		}
+	}
+	return false
+}
+
+// matchRule reports whether a rule of a policy in namespace with peers and
+// ports allows q from or to peer, the other endpoint of the connection
+func matchRule(namespace string, peers []networkingv1.NetworkPolicyPeer, ports []networkingv1.NetworkPolicyPort, peer Endpoint, q Query, port int32) (bool, error) {
+	ok, err := matchPorts(ports, q, port)
+	if err != nil || !ok {
+		return false, err
+	}
+	if len(peers) == 0 {
+		return true, nil
+	}
+	return matchPeers(namespace, peers, peer)
+}
+
+// matchPeers reports whether one of peers selects endpoint
+func matchPeers(namespace string, peers []networkingv1.NetworkPolicyPeer, endpoint Endpoint) (bool, error) {
+	for _, peer := range peers {
+		matched, err := matchPeer(namespace, peer, endpoint)
+		if err != nil || matched {
+			return matched, err
+		}
+	}
+	return false, nil
+}
+
+// matchPeer reports whether peer of a policy in namespace selects endpoint
+func matchPeer(namespace string, peer networkingv1.NetworkPolicyPeer, endpoint Endpoint) (bool, error) {
+	if peer.IPBlock != nil {
+		return matchIPBlock(peer.IPBlock, endpoint.IP)
+	}
+	if endpoint.Namespace == "" {
+		return false, nil
+	}
+
+	if peer.NamespaceSelector == nil {
+		if endpoint.Namespace != namespace {
+			return false, nil
+		}
+	} else {
+		nsLabels := labels.Set{corev1.LabelMetadataName: endpoint.Namespace}
+		for k, v := range endpoint.NamespaceLabels {
+			nsLabels[k] = v
+		}
+		matched, err := matchSelector(peer.NamespaceSelector, nsLabels)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+
+	if peer.PodSelector == nil {
+		return true, nil
+	}
+	return matchSelector(peer.PodSelector, labels.Set(endpoint.PodLabels))
+}
+
+// matchSelector reports whether selector matches set
+func matchSelector(selector *metav1.LabelSelector, set labels.Set) (bool, error) {
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return false, fmt.Errorf("invalid selector: %w", err)
+	}
+	return s.Matches(set), nil
+}
+
+// matchIPBlock reports whether ip is in block and none of its exceptions
+func matchIPBlock(block *networkingv1.IPBlock, ip string) (bool, error) {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false, nil
+	}
+	_, cidr, err := net.ParseCIDR(block.CIDR)
+	if err != nil {
+		return false, fmt.Errorf("invalid ipBlock %s: %w", block.CIDR, err)
+	}
+	if !cidr.Contains(addr) {
+		return false, nil
+	}
+	for _, except := range block.Except {
+		_, excluded, err := net.ParseCIDR(except)
+		if err != nil {
+			return false, fmt.Errorf("invalid ipBlock exception %s: %w", except, err)
+		}
+		if excluded.Contains(addr) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// matchPorts reports whether one of ports allows the connection of q to port,
+// the resolved number of q.Port or 0 when it is an unresolved name
+func matchPorts(ports []networkingv1.NetworkPolicyPort, q Query, port int32) (bool, error) {
+	if len(ports) == 0 {
+		return true, nil
+	}
+	for _, p := range ports {
+		protocol := corev1.ProtocolTCP
+		if p.Protocol != nil {
+			protocol = *p.Protocol
+		}
+		if protocol != q.Protocol {
+			continue
+		}
+		if p.Port == nil {
+			return true, nil
+		}
+
+		if p.Port.Type == intstr.String {
+			if q.Port.Type == intstr.String && q.Port.StrVal == p.Port.StrVal {
+				return true, nil
+			}
+			if number, ok := q.Destination.NamedPorts[p.Port.StrVal]; ok && port != 0 && number == port {
+				return true, nil
+			}
+			continue
+		}
+
+		start, end := p.Port.IntVal, p.Port.IntVal
+		if p.EndPort != nil {
+			end = *p.EndPort
+		}
+		if port >= start && port <= end {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// resolvePort returns the number of port on destination, 0 when a named port
+// isn't listed in the named ports of destination
+func resolvePort(port intstr.IntOrString, destination Endpoint) (int32, error) {
+	if port.Type == intstr.Int {
+		if port.IntVal < 1 || port.IntVal > 65535 {
+			return 0, fmt.Errorf("invalid port %d", port.IntVal)
+		}
+		return port.IntVal, nil
+	}
+	if port.StrVal == "" {
+		return 0, fmt.Errorf("port is required")
+	}
+	return destination.NamedPorts[port.StrVal], nil
+}
@@ -0,0 +1,150 @@
+package evaluator
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+	"01cloud/zoperator/internal/usecase"
+)
+
+// examplePolicies returns the NetworkPolicies generated for the UserConfig of examples/<name>
+func examplePolicies(name string) []networkingv1.NetworkPolicy {
+	scheme := runtime.NewScheme()
+	Expect(myoperatorv1alpha1.AddToScheme(scheme)).To(Succeed())
+	data, err := os.ReadFile(filepath.Join("..", "..", "examples", name))
+	Expect(err).NotTo(HaveOccurred())
+	obj, _, err := serializer.NewCodecFactory(scheme).UniversalDeserializer().Decode(data, nil, nil)
+	Expect(err).NotTo(HaveOccurred())
+	return usecase.NetworkPolicies(obj.(*myoperatorv1alpha1.UserConfig))
+}
+
+func pod(namespace string, podLabels map[string]string) Endpoint {
+	return Endpoint{Namespace: namespace, PodLabels: podLabels}
+}
+
+var _ = Describe("Evaluate", func() {
+	var policies []networkingv1.NetworkPolicy
+
+	evaluate := func(source, destination Endpoint, port intstr.IntOrString) Result {
+		result, err := Evaluate(policies, Query{Source: source, Destination: destination, Port: port})
+		Expect(err).NotTo(HaveOccurred())
+		return result
+	}
+
+	Context("with the advanced example", func() {
+		BeforeEach(func() {
+			policies = examplePolicies("advanced-user-config.yaml")
+		})
+
+		It("only admits the frontend pods of frontend-namespace", func() {
+			tenant := pod("devops-advanced", map[string]string{"app": "api"})
+
+			result := evaluate(pod("frontend-namespace", map[string]string{"app": "frontend"}), tenant, intstr.FromInt32(443))
+			Expect(result.Allowed).To(BeTrue())
+			Expect(result.Ingress.Policy).To(Equal("devops-advanced/devops-advanced"))
+			Expect(result.Ingress.Rule).To(Equal(0))
+
+			Expect(evaluate(pod("frontend-namespace", map[string]string{"app": "admin"}), tenant, intstr.FromInt32(443)).Allowed).To(BeFalse())
+			Expect(evaluate(pod("devops-advanced", map[string]string{"app": "frontend"}), tenant, intstr.FromInt32(443)).Allowed).To(BeFalse())
+			Expect(evaluate(pod("frontend-namespace", map[string]string{"app": "frontend"}), tenant, intstr.FromInt32(22)).Allowed).To(BeFalse())
+		})
+
+		It("allows egress to the databases and DNS only", func() {
+			tenant := pod("devops-advanced", map[string]string{"app": "api"})
+
+			result := evaluate(tenant, pod("database-namespace", map[string]string{"app": "redis"}), intstr.FromInt32(6379))
+			Expect(result.Allowed).To(BeTrue())
+			Expect(result.Ingress.Isolated).To(BeFalse())
+
+			dns := pod("kube-system", map[string]string{"k8s-app": "kube-dns"})
+			result, err := Evaluate(policies, Query{Source: tenant, Destination: dns, Port: intstr.FromInt32(53), Protocol: corev1.ProtocolUDP})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Allowed).To(BeTrue())
+
+			Expect(evaluate(tenant, pod("database-namespace", map[string]string{"app": "mysql"}), intstr.FromInt32(3306)).Allowed).To(BeFalse())
+			Expect(evaluate(tenant, Endpoint{IP: "140.82.112.3"}, intstr.FromInt32(443)).Egress.Allowed).To(BeFalse())
+		})
+
+		It("admits external HTTPS on the ingress pods through the named policy", func() {
+			ingress := pod("devops-advanced", map[string]string{"app": "ingress"})
+
+			result := evaluate(Endpoint{IP: "203.0.113.7"}, ingress, intstr.FromInt32(443))
+			Expect(result.Allowed).To(BeTrue())
+			Expect(result.Ingress.Policy).To(Equal("devops-advanced/devops-advanced-ingress"))
+
+			Expect(evaluate(Endpoint{IP: "203.0.113.7"}, pod("devops-advanced", map[string]string{"app": "api"}), intstr.FromInt32(443)).Allowed).To(BeFalse())
+		})
+	})
+
+	Context("with ipBlocks, named ports and port ranges", func() {
+		BeforeEach(func() {
+			endPort := int32(8100)
+			policies = usecase.NetworkPolicies(&myoperatorv1alpha1.UserConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "tenant"},
+				Spec: myoperatorv1alpha1.UserConfigSpec{
+					NetworkPolicy: []myoperatorv1alpha1.NetworkPolicy{
+						{AllowTrafficTo: &myoperatorv1alpha1.NetworkPolicyPeer{
+							IPBlocks: []myoperatorv1alpha1.IPBlock{{CIDR: "10.0.0.0/8", Except: []string{"10.1.0.0/16"}}},
+							Ports:    []myoperatorv1alpha1.NetworkPolicyPort{{Port: intstr.FromInt32(8000), EndPort: &endPort}},
+						}},
+						{AllowTrafficFrom: &myoperatorv1alpha1.NetworkPolicyPeer{
+							Namespaces: []map[string]string{{"team": "monitoring"}},
+							Ports:      []myoperatorv1alpha1.NetworkPolicyPort{{Port: intstr.FromString("metrics")}},
+						}},
+					},
+					NetworkDefaults: &myoperatorv1alpha1.NetworkDefaults{AllowSameNamespace: true},
+				},
+			})
+		})
+
+		It("matches CIDR ranges and port ranges", func() {
+			tenant := pod("tenant", nil)
+			Expect(evaluate(tenant, Endpoint{IP: "10.2.3.4"}, intstr.FromInt32(8050)).Allowed).To(BeTrue())
+			Expect(evaluate(tenant, Endpoint{IP: "10.1.3.4"}, intstr.FromInt32(8050)).Allowed).To(BeFalse())
+			Expect(evaluate(tenant, Endpoint{IP: "10.2.3.4"}, intstr.FromInt32(8101)).Allowed).To(BeFalse())
+		})
+
+		It("resolves named ports on the destination", func() {
+			prometheus := Endpoint{Namespace: "observability", NamespaceLabels: map[string]string{"team": "monitoring"}}
+			exporter := pod("tenant", map[string]string{"app": "exporter"})
+			exporter.NamedPorts = map[string]int32{"metrics": 9090}
+
+			Expect(evaluate(prometheus, exporter, intstr.FromInt32(9090)).Allowed).To(BeTrue())
+			Expect(evaluate(prometheus, exporter, intstr.FromString("metrics")).Allowed).To(BeTrue())
+			Expect(evaluate(prometheus, exporter, intstr.FromInt32(9091)).Allowed).To(BeFalse())
+		})
+
+		It("allows traffic within the namespace", func() {
+			result := evaluate(pod("tenant", map[string]string{"app": "a"}), pod("tenant", map[string]string{"app": "b"}), intstr.FromInt32(5000))
+			Expect(result.Allowed).To(BeTrue())
+			Expect(result.Egress.Isolated).To(BeTrue())
+			Expect(result.Ingress.Isolated).To(BeTrue())
+		})
+	})
+
+	It("allows everything between endpoints no policy selects", func() {
+		policies = nil
+		result := evaluate(pod("a", nil), pod("b", nil), intstr.FromInt32(80))
+		Expect(result).To(Equal(Result{
+			Allowed: true,
+			Egress:  Decision{Allowed: true, Rule: -1},
+			Ingress: Decision{Allowed: true, Rule: -1},
+		}))
+	})
+
+	It("rejects invalid ports", func() {
+		_, err := Evaluate(nil, Query{Port: intstr.FromInt32(0)})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,225 @@
+// Package metrics defines the operator specific Prometheus metrics. All
+// collectors are registered on the controller-runtime registry so they are
+// served from the manager's /metrics endpoint next to the default
+// controller-runtime metrics and scraped by config/prometheus/monitor.yaml.
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+)
+
+const namespace = "zoperator"
+
+// stateScrapeTimeout bounds the listing of the UserConfigs at scrape time
+const stateScrapeTimeout = 5 * time.Second
+
+var (
+	// ReconcileStepDuration tracks how long each subsystem step of the
+	// UserConfig reconciliation takes.
+	ReconcileStepDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "reconcile_step_duration_seconds",
+		Help:      "Duration of each UserConfig reconcile step in seconds.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"step", "result"})
+
+	// SecretSyncFailures counts failed secret synchronisations per provider.
+	SecretSyncFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "secret_sync_failures_total",
+		Help:      "Total number of failed secret synchronisations by provider.",
+	}, []string{"provider"})
+
+	// QuotaUsed mirrors status.used of the managed ResourceQuota.
+	QuotaUsed = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "namespace_quota_used",
+		Help:      "Used amount of a resource in the managed ResourceQuota of a UserConfig namespace.",
+	}, []string{"userconfig", "namespace", "resource"})
+
+	// QuotaHard mirrors status.hard of the managed ResourceQuota.
+	QuotaHard = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "namespace_quota_hard",
+		Help:      "Hard limit of a resource in the managed ResourceQuota of a UserConfig namespace.",
+	}, []string{"userconfig", "namespace", "resource"})
+
+	states = &stateCollector{
+		userConfigs: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "userconfigs"),
+			"Number of UserConfigs by status state.",
+			[]string{"state"}, nil,
+		),
+	}
+
+	tokens = &tokenCollector{
+		tokens: map[string]tokenTimes{},
+		age: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "kubeconfig", "token_age_seconds"),
+			"Age of the service account token embedded in the UserConfig kubeconfig.",
+			[]string{"userconfig"}, nil,
+		),
+		expiry: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "kubeconfig", "token_expiry_timestamp_seconds"),
+			"Unix time at which the service account token embedded in the UserConfig kubeconfig expires.",
+			[]string{"userconfig"}, nil,
+		),
+	}
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		states,
+		ReconcileStepDuration,
+		SecretSyncFailures,
+		QuotaUsed,
+		QuotaHard,
+		tokens,
+	)
+}
+
+// ObserveReconcileStep records the duration of a reconcile step started at start.
+func ObserveReconcileStep(step string, start time.Time, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	ReconcileStepDuration.WithLabelValues(step, result).Observe(time.Since(start).Seconds())
+}
+
+// CountUserConfigStates counts the UserConfigs per status state at scrape
+// time, listing them from reader, usually the manager cache.
+func CountUserConfigStates(reader client.Reader) {
+	states.setReader(reader)
+}
+
+// SetKubeconfigToken records when the kubeconfig token of a UserConfig was
+// issued and when it expires.
+func SetKubeconfigToken(userConfig string, issued, expiry time.Time) {
+	tokens.set(userConfig, tokenTimes{issued: issued, expiry: expiry})
+}
+
+// IncSecretSyncFailure counts a failed secret synchronisation for provider.
+func IncSecretSyncFailure(provider string) {
+	SecretSyncFailures.WithLabelValues(provider).Inc()
+}
+
+// SetQuotaUsage mirrors the hard and used amounts of a ResourceQuota status.
+func SetQuotaUsage(userConfig, ns string, hard, used corev1.ResourceList) {
+	for name, quantity := range hard {
+		QuotaHard.WithLabelValues(userConfig, ns, string(name)).Set(quantity.AsApproximateFloat64())
+		usedQuantity := used[name]
+		QuotaUsed.WithLabelValues(userConfig, ns, string(name)).Set(usedQuantity.AsApproximateFloat64())
+	}
+}
+
+// DeleteNamespaceQuota drops the quota series of a namespace pruned from a UserConfig.
+func DeleteNamespaceQuota(userConfig, ns string) {
+	QuotaUsed.DeletePartialMatch(prometheus.Labels{"userconfig": userConfig, "namespace": ns})
+	QuotaHard.DeletePartialMatch(prometheus.Labels{"userconfig": userConfig, "namespace": ns})
+}
+
+// DeleteUserConfig drops every per user series once a UserConfig is removed.
+func DeleteUserConfig(userConfig string) {
+	QuotaUsed.DeletePartialMatch(prometheus.Labels{"userconfig": userConfig})
+	QuotaHard.DeletePartialMatch(prometheus.Labels{"userconfig": userConfig})
+	tokens.delete(userConfig)
+}
+
+// stateCollector counts the UserConfigs per state at scrape time, so
+// reconciles don't list every UserConfig to keep a gauge up to date.
+type stateCollector struct {
+	mu          sync.RWMutex
+	reader      client.Reader
+	userConfigs *prometheus.Desc
+}
+
+func (c *stateCollector) setReader(reader client.Reader) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reader = reader
+}
+
+func (c *stateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.userConfigs
+}
+
+func (c *stateCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	reader := c.reader
+	c.mu.RUnlock()
+	if reader == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), stateScrapeTimeout)
+	defer cancel()
+	userConfigs := &myoperatorv1alpha1.UserConfigList{}
+	if err := reader.List(ctx, userConfigs); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list UserConfigs for metrics")
+		return
+	}
+	counts := map[string]int{}
+	for _, item := range userConfigs.Items {
+		state := item.Status.State
+		if state == "" {
+			state = "Unknown"
+		}
+		counts[state]++
+	}
+	for state, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.userConfigs, prometheus.GaugeValue, float64(count), state)
+	}
+}
+
+type tokenTimes struct {
+	issued time.Time
+	expiry time.Time
+}
+
+// tokenCollector computes token age at scrape time instead of at issuance.
+type tokenCollector struct {
+	mu     sync.RWMutex
+	tokens map[string]tokenTimes
+	age    *prometheus.Desc
+	expiry *prometheus.Desc
+}
+
+func (c *tokenCollector) set(userConfig string, t tokenTimes) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokens[userConfig] = t
+}
+
+func (c *tokenCollector) delete(userConfig string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.tokens, userConfig)
+}
+
+func (c *tokenCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.age
+	ch <- c.expiry
+}
+
+func (c *tokenCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	now := time.Now()
+	for userConfig, t := range c.tokens {
+		ch <- prometheus.MustNewConstMetric(c.age, prometheus.GaugeValue, now.Sub(t.issued).Seconds(), userConfig)
+		ch <- prometheus.MustNewConstMetric(c.expiry, prometheus.GaugeValue, float64(t.expiry.Unix()), userConfig)
+	}
+}
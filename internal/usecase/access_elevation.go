@@ -0,0 +1,283 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+)
+
+// maxElevationHistory is the number of elevations kept in status.elevationHistory
+const maxElevationHistory = 50
+
+// elevationName returns the name of the Role and RoleBinding granted by elevation
+func elevationName(elevation *myoperatorv1alpha1.AccessElevation) string {
+	return "elevation-" + elevation.Name
+}
+
+// elevationNamespaces returns the namespaces of uc the elevation applies to
+func elevationNamespaces(elevation *myoperatorv1alpha1.AccessElevation, uc *myoperatorv1alpha1.UserConfig) ([]string, error) {
+	names := NamespaceNames(uc)
+	if len(elevation.Spec.Namespaces) == 0 {
+		return names, nil
+	}
+	for _, namespace := range elevation.Spec.Namespaces {
+		if !slices.Contains(names, namespace) {
+			return nil, fmt.Errorf("namespace %s doesn't belong to UserConfig %s", namespace, uc.Name)
+		}
+	}
+	return elevation.Spec.Namespaces, nil
+}
+
+// describePermissions returns every permission as "<resource> <operation>"
+func describePermissions(permissions []myoperatorv1alpha1.ResourcePermission) []string {
+	described := make([]string, 0, len(permissions))
+	for _, perm := range permissions {
+		described = append(described, fmt.Sprintf("%s %s", perm.Resource, perm.Operation))
+	}
+	return described
+}
+
+// ReconcileAccessElevation grants the permissions of elevation through a
+// separate Role and RoleBinding in the namespaces of its UserConfig until it
+// expires, then removes them. Elevations matching the OperatorConfig approval
+// policy wait in Pending for an AccessApproval. Every transition is recorded
+// in the elevation history of the UserConfig. It returns how long until the
+// elevation expires, zero once it did. The caller is responsible for
+// persisting the status of elevation.
+func (u *UserConfigUseCase) ReconcileAccessElevation(ctx context.Context, elevation *myoperatorv1alpha1.AccessElevation, now time.Time) (time.Duration, error) {
+	if elevation.Status.Phase == myoperatorv1alpha1.ElevationExpired {
+		return 0, nil
+	}
+	if elevation.Status.Phase == "" {
+		elevation.Status.Phase = myoperatorv1alpha1.ElevationPending
+	}
+
+	if expiry := elevation.Status.ExpiryTime; expiry != nil && !now.Before(expiry.Time) {
+		return 0, u.expireElevation(ctx, elevation, now)
+	}
+
+	uc := &myoperatorv1alpha1.UserConfig{}
+	if err := u.Get(ctx, client.ObjectKey{Name: elevation.Spec.UserConfig}, uc); err != nil {
+		return 0, fmt.Errorf("failed to get UserConfig %s: %w", elevation.Spec.UserConfig, err)
+	}
+	if uc.Status.State == "Pending" {
+		return 0, fmt.Errorf("UserConfig %s is pending approval", uc.Name)
+	}
+	namespaces, err := elevationNamespaces(elevation, uc)
+	if err != nil {
+		return 0, err
+	}
+
+	granted := elevation.Status.StartTime == nil
+	if granted {
+		defaults := u.Defaults.Spec()
+		if elevation.Spec.Duration.Duration <= 0 {
+			return 0, fmt.Errorf("duration %s must be positive", elevation.Spec.Duration.Duration)
+		}
+		if limit := maxElevationDuration(defaults); elevation.Spec.Duration.Duration > limit {
+			return 0, fmt.Errorf("duration %s exceeds the maximum %s of OperatorConfig %s", elevation.Spec.Duration.Duration, limit, myoperatorv1alpha1.OperatorConfigName)
+		}
+		approved, err := u.reconcileElevationApproval(ctx, elevation, uc, defaults.Approval)
+		if err != nil || !approved {
+			return 0, err
+		}
+	}
+
+	// Elevations go through the same RBAC policy as the regular permissions
+	rules, filtered, err := u.settings().RBACPolicy.Apply(uc.Spec.Identity.Groups, policyRules(elevation.Spec.Permissions))
+	elevation.Status.FilteredPermissions = filtered
+	if err != nil {
+		return 0, err
+	}
+
+	for _, namespace := range namespaces {
+		if err := u.applyElevation(ctx, uc, elevation, namespace, rules); err != nil {
+			return 0, fmt.Errorf("failed to grant AccessElevation %s in namespace %s: %w", elevation.Name, namespace, err)
+		}
+	}
+	// Drop the grants of the namespaces the UserConfig no longer has
+	if err := u.removeElevation(ctx, elevation, func(obj client.Object) bool {
+		return !slices.Contains(namespaces, obj.GetNamespace())
+	}); err != nil {
+		return 0, err
+	}
+
+	if granted {
+		elevation.Status.StartTime = &metav1.Time{Time: now}
+		elevation.Status.ExpiryTime = &metav1.Time{Time: now.Add(elevation.Spec.Duration.Duration)}
+	}
+	elevation.Status.Phase = myoperatorv1alpha1.ElevationActive
+	elevation.Status.Namespaces = namespaces
+	if err := u.recordElevation(ctx, uc, elevation, now); err != nil {
+		return 0, err
+	}
+	if granted {
+		u.Recorder.Eventf(uc, corev1.EventTypeNormal, EventReasonElevationGranted, "Granted %s in %s until %s through AccessElevation %s",
+			strings.Join(describePermissions(elevation.Spec.Permissions), ", "), strings.Join(namespaces, ", "),
+			elevation.Status.ExpiryTime.UTC().Format(time.RFC3339), elevation.Name)
+	}
+
+	// Never leave an elevation active without a pending requeue
+	remaining := elevation.Status.ExpiryTime.Sub(now)
+	if remaining <= 0 {
+		return 0, u.expireElevation(ctx, elevation, now)
+	}
+	return remaining, nil
+}
+
+// reconcileElevationApproval records in the status of elevation whether the
+// approval policy requires an approval of its permissions and reports whether
+// it may be granted. The user of uc never approves their own elevation.
+func (u *UserConfigUseCase) reconcileElevationApproval(ctx context.Context, elevation *myoperatorv1alpha1.AccessElevation, uc *myoperatorv1alpha1.UserConfig, policy *myoperatorv1alpha1.ApprovalPolicy) (bool, error) {
+	if policy == nil {
+		elevation.Status.Approval = nil
+		return true, nil
+	}
+	reasons := permissionApprovalReasons(policy, elevation.Spec.Permissions)
+	status, err := u.reconcileApproval(ctx, elevation, elevation.Status.Approval, policy, reasons, hashOf(elevation.Spec), uc.Spec.Identity.Username,
+		func(approval *myoperatorv1alpha1.AccessApproval) bool {
+			return approval.Spec.AccessElevation == elevation.Name
+		})
+	elevation.Status.Approval = status
+	return status == nil || status.Approver != "", err
+}
+
+// expireElevation removes the permissions of elevation and records its expiry
+func (u *UserConfigUseCase) expireElevation(ctx context.Context, elevation *myoperatorv1alpha1.AccessElevation, now time.Time) error {
+	if err := u.removeElevation(ctx, elevation, nil); err != nil {
+		return err
+	}
+	elevation.Status.Phase = myoperatorv1alpha1.ElevationExpired
+	uc := &myoperatorv1alpha1.UserConfig{}
+	if err := u.Get(ctx, client.ObjectKey{Name: elevation.Spec.UserConfig}, uc); err != nil {
+		// The permissions went away with the UserConfig
+		return client.IgnoreNotFound(err)
+	}
+	if err := u.recordElevation(ctx, uc, elevation, now); err != nil {
+		return err
+	}
+	u.Recorder.Eventf(uc, corev1.EventTypeNormal, EventReasonElevationExpired,
+		"Removed the permissions of AccessElevation %s granted at %s", elevation.Name, elevation.Status.StartTime.UTC().Format(time.RFC3339))
+	return nil
+}
+
+// RevokeAccessElevation removes the permissions of a deleted elevation and
+// records the revocation in the UserConfig history when it was still active
+func (u *UserConfigUseCase) RevokeAccessElevation(ctx context.Context, elevation *myoperatorv1alpha1.AccessElevation, now time.Time) error {
+	if err := u.removeElevation(ctx, elevation, nil); err != nil {
+		return err
+	}
+	if elevation.Status.Phase != myoperatorv1alpha1.ElevationActive {
+		return nil
+	}
+
+	elevation.Status.Phase = myoperatorv1alpha1.ElevationRevoked
+	uc := &myoperatorv1alpha1.UserConfig{}
+	if err := u.Get(ctx, client.ObjectKey{Name: elevation.Spec.UserConfig}, uc); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if err := u.recordElevation(ctx, uc, elevation, now); err != nil {
+		return err
+	}
+	u.Recorder.Eventf(uc, corev1.EventTypeNormal, EventReasonElevationRevoked,
+		"Removed the permissions of AccessElevation %s before their expiry, it was deleted", elevation.Name)
+	return nil
+}
+
+// applyElevation creates or updates the Role and RoleBinding granting the rules of elevation in namespace
+func (u *UserConfigUseCase) applyElevation(ctx context.Context, uc *myoperatorv1alpha1.UserConfig, elevation *myoperatorv1alpha1.AccessElevation, namespace string, rules []rbacv1.PolicyRule) error {
+	name := elevationName(elevation)
+	setMetadata := func(obj client.Object) error {
+		obj.SetLabels(mergeStringMaps(obj.GetLabels(), map[string]string{LabelElevation: elevation.Name}))
+		return u.setManagedMetadata(uc, obj)
+	}
+
+	role := &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	if _, err := controllerutil.CreateOrUpdate(ctx, u.Client, role, func() error {
+		role.Rules = rules
+		return setMetadata(role)
+	}); err != nil {
+		return err
+	}
+
+	roleBinding := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	_, err := controllerutil.CreateOrUpdate(ctx, u.Client, roleBinding, func() error {
+		roleBinding.Subjects = userSubjects(uc)
+		roleBinding.RoleRef = rbacv1.RoleRef{
+			Kind:     "Role",
+			Name:     name,
+			APIGroup: "rbac.authorization.k8s.io",
+		}
+		return setMetadata(roleBinding)
+	})
+	return err
+}
+
+// removeElevation deletes the Roles and RoleBindings granted by elevation for
+// which stale returns true, or all of them when stale is nil
+func (u *UserConfigUseCase) removeElevation(ctx context.Context, elevation *myoperatorv1alpha1.AccessElevation, stale func(client.Object) bool) error {
+	if stale == nil {
+		stale = func(client.Object) bool { return true }
+	}
+	err := u.deleteRoleGrants(ctx, []client.ListOption{client.MatchingLabels{LabelElevation: elevation.Name}}, stale)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to remove AccessElevation %s: %w", elevation.Name, err)
+	}
+	return nil
+}
+
+// recordElevation updates the entry of elevation in the elevation history of
+// uc with its current phase, appending it when it isn't recorded as active yet
+func (u *UserConfigUseCase) recordElevation(ctx context.Context, uc *myoperatorv1alpha1.UserConfig, elevation *myoperatorv1alpha1.AccessElevation, now time.Time) error {
+	if elevation.Status.StartTime == nil {
+		return nil
+	}
+	record := myoperatorv1alpha1.ElevationRecord{
+		AccessElevation: elevation.Name,
+		Permissions:     describePermissions(elevation.Spec.Permissions),
+		Namespaces:      elevation.Status.Namespaces,
+		Reason:          elevation.Spec.Reason,
+		StartTime:       *elevation.Status.StartTime,
+		ExpiryTime:      *elevation.Status.ExpiryTime,
+		Outcome:         elevation.Status.Phase,
+	}
+	if record.Outcome != myoperatorv1alpha1.ElevationActive {
+		record.EndTime = &metav1.Time{Time: now}
+	}
+
+	patch := client.MergeFrom(uc.DeepCopy())
+	history := uc.Status.ElevationHistory
+	index := slices.IndexFunc(history, func(r myoperatorv1alpha1.ElevationRecord) bool {
+		return r.AccessElevation == elevation.Name && r.Outcome == myoperatorv1alpha1.ElevationActive
+	})
+	if index < 0 {
+		history = append(history, record)
+	} else {
+		if equality.Semantic.DeepEqual(history[index], record) {
+			return nil
+		}
+		history[index] = record
+	}
+	if len(history) > maxElevationHistory {
+		history = history[len(history)-maxElevationHistory:]
+	}
+	uc.Status.ElevationHistory = history
+	if err := u.Status().Patch(ctx, uc, patch); err != nil {
+		return fmt.Errorf("failed to record AccessElevation %s in UserConfig %s: %w", elevation.Name, uc.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+)
+
+var _ = Describe("access elevations", func() {
+	var (
+		ctx       context.Context
+		u         *UserConfigUseCase
+		recorder  *record.FakeRecorder
+		elevation *myoperatorv1alpha1.AccessElevation
+		start     time.Time
+	)
+
+	// history returns the elevation history of the UserConfig
+	history := func() []myoperatorv1alpha1.ElevationRecord {
+		uc := &myoperatorv1alpha1.UserConfig{}
+		Expect(u.Get(ctx, client.ObjectKey{Name: "alice"}, uc)).To(Succeed())
+		return uc.Status.ElevationHistory
+	}
+
+	// granted reports whether the elevation Role and RoleBinding exist in namespace
+	granted := func(namespace string) bool {
+		key := client.ObjectKey{Name: "elevation-alice-incident", Namespace: namespace}
+		roleErr := u.Get(ctx, key, &rbacv1.Role{})
+		bindingErr := u.Get(ctx, key, &rbacv1.RoleBinding{})
+		Expect(client.IgnoreNotFound(roleErr)).To(Succeed())
+		Expect(client.IgnoreNotFound(bindingErr)).To(Succeed())
+		return roleErr == nil && bindingErr == nil
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		uc := &myoperatorv1alpha1.UserConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "alice"},
+			Spec: myoperatorv1alpha1.UserConfigSpec{
+				Identity:   myoperatorv1alpha1.Identity{Username: "alice"},
+				Namespaces: []myoperatorv1alpha1.UserNamespace{{Suffix: "dev"}},
+			},
+		}
+		elevation = &myoperatorv1alpha1.AccessElevation{
+			ObjectMeta: metav1.ObjectMeta{Name: "alice-incident"},
+			Spec: myoperatorv1alpha1.AccessElevationSpec{
+				UserConfig:  "alice",
+				Permissions: []myoperatorv1alpha1.ResourcePermission{{Resource: "secret", Operation: "R"}},
+				Namespaces:  []string{"alice-dev"},
+				Duration:    metav1.Duration{Duration: time.Hour},
+				Reason:      "incident 4211",
+			},
+		}
+		u, recorder = newTestUseCase(uc)
+	})
+
+	It("grants the permissions until the expiry and records the elevation", func() {
+		remaining, err := u.ReconcileAccessElevation(ctx, elevation, start)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(remaining).To(Equal(time.Hour))
+		Expect(elevation.Status.Phase).To(Equal(myoperatorv1alpha1.ElevationActive))
+		Expect(elevation.Status.ExpiryTime.Time).To(Equal(start.Add(time.Hour)))
+		Expect(granted("alice-dev")).To(BeTrue())
+		Expect(granted("alice")).To(BeFalse())
+		Expect(recorder.Events).To(Receive(ContainSubstring("ElevationGranted Granted secret R in alice-dev")))
+
+		role := &rbacv1.Role{}
+		Expect(u.Get(ctx, client.ObjectKey{Name: "elevation-alice-incident", Namespace: "alice-dev"}, role)).To(Succeed())
+		Expect(role.Labels).To(HaveKeyWithValue(LabelElevation, "alice-incident"))
+		Expect(role.Rules[0].Resources).To(Equal([]string{"secrets"}))
+
+		records := history()
+		Expect(records).To(HaveLen(1))
+		Expect(records[0].Outcome).To(Equal(myoperatorv1alpha1.ElevationActive))
+		Expect(records[0].Permissions).To(Equal([]string{"secret R"}))
+		Expect(records[0].EndTime).To(BeNil())
+
+		// Reconciling again keeps the expiry
+		remaining, err = u.ReconcileAccessElevation(ctx, elevation, start.Add(20*time.Minute))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(remaining).To(Equal(40 * time.Minute))
+		Expect(recorder.Events).To(BeEmpty())
+
+		remaining, err = u.ReconcileAccessElevation(ctx, elevation, start.Add(time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(remaining).To(BeZero())
+		Expect(elevation.Status.Phase).To(Equal(myoperatorv1alpha1.ElevationExpired))
+		Expect(granted("alice-dev")).To(BeFalse())
+		Expect(recorder.Events).To(Receive(ContainSubstring("ElevationExpired")))
+
+		records = history()
+		Expect(records).To(HaveLen(1))
+		Expect(records[0].Outcome).To(Equal(myoperatorv1alpha1.ElevationExpired))
+		Expect(records[0].EndTime.Time).To(BeTemporally("==", start.Add(time.Hour)))
+	})
+
+	It("records the revocation of an elevation deleted before its expiry", func() {
+		_, err := u.ReconcileAccessElevation(ctx, elevation, start)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Events).To(Receive())
+
+		Expect(u.RevokeAccessElevation(ctx, elevation, start.Add(10*time.Minute))).To(Succeed())
+		Expect(granted("alice-dev")).To(BeFalse())
+		Expect(recorder.Events).To(Receive(ContainSubstring("ElevationRevoked")))
+		records := history()
+		Expect(records).To(HaveLen(1))
+		Expect(records[0].Outcome).To(Equal(myoperatorv1alpha1.ElevationRevoked))
+	})
+
+	It("refuses durations that aren't positive or exceed the OperatorConfig maximum", func() {
+		elevation.Spec.Duration = metav1.Duration{}
+		_, err := u.ReconcileAccessElevation(ctx, elevation, start)
+		Expect(err).To(MatchError(ContainSubstring("must be positive")))
+		Expect(granted("alice-dev")).To(BeFalse())
+
+		elevation.Spec.Duration = metav1.Duration{Duration: 48 * time.Hour}
+		_, err = u.ReconcileAccessElevation(ctx, elevation, start)
+		Expect(err).To(MatchError(ContainSubstring("duration 48h0m0s exceeds the maximum 24h0m0s")))
+
+		u.Defaults = NewOperatorDefaults()
+		u.Defaults.Set(myoperatorv1alpha1.OperatorConfigSpec{MaxElevationDuration: &metav1.Duration{Duration: 72 * time.Hour}})
+		Expect(u.ReconcileAccessElevation(ctx, elevation, start)).To(Equal(48 * time.Hour))
+	})
+
+	It("holds elevations matching the approval policy until an approver approves them", func() {
+		u.Defaults = NewOperatorDefaults()
+		u.Defaults.Set(myoperatorv1alpha1.OperatorConfigSpec{Approval: &myoperatorv1alpha1.ApprovalPolicy{Approvers: []string{"sec-lead", "alice"}}})
+		elevation.Spec.Permissions = []myoperatorv1alpha1.ResourcePermission{{Resource: "secret", Operation: "*"}}
+
+		approval := func(name, approver string) *myoperatorv1alpha1.AccessApproval {
+			return &myoperatorv1alpha1.AccessApproval{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Spec:       myoperatorv1alpha1.AccessApprovalSpec{AccessElevation: "alice-incident", SpecHash: hashOf(elevation.Spec), Approver: approver},
+			}
+		}
+		// The user never approves their own elevation
+		Expect(u.Create(ctx, approval("self", "alice"))).To(Succeed())
+
+		remaining, err := u.ReconcileAccessElevation(ctx, elevation, start)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(remaining).To(BeZero())
+		Expect(elevation.Status.Phase).To(Equal(myoperatorv1alpha1.ElevationPending))
+		Expect(elevation.Status.Approval.Reasons).To(Equal([]string{"secret *"}))
+		Expect(elevation.Status.StartTime).To(BeNil())
+		Expect(granted("alice-dev")).To(BeFalse())
+		Expect(recorder.Events).To(Receive(ContainSubstring("ApprovalRequired")))
+
+		Expect(u.Create(ctx, approval("granted", "sec-lead"))).To(Succeed())
+		remaining, err = u.ReconcileAccessElevation(ctx, elevation, start.Add(time.Minute))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(remaining).To(Equal(time.Hour))
+		Expect(elevation.Status.Approval.Approver).To(Equal("sec-lead"))
+		Expect(granted("alice-dev")).To(BeTrue())
+	})
+
+	It("refuses namespaces outside of the UserConfig and waits for a missing UserConfig", func() {
+		elevation.Spec.Namespaces = []string{"bob"}
+		_, err := u.ReconcileAccessElevation(ctx, elevation, start)
+		Expect(err).To(MatchError(ContainSubstring("namespace bob doesn't belong to UserConfig alice")))
+		Expect(elevation.Status.StartTime).To(BeNil())
+
+		elevation.Spec.UserConfig = "bob"
+		_, err = u.ReconcileAccessElevation(ctx, elevation, start)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		Expect(elevation.Status.Phase).To(Equal(myoperatorv1alpha1.ElevationPending))
+	})
+})
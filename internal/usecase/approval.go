@@ -0,0 +1,217 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+)
+
+// Identity groups and resources requiring an approval when the policy leaves them unset
+var (
+	defaultApprovalGroups    = []string{"admin", "security"}
+	defaultApprovalResources = []string{"secret"}
+)
+
+// approvalReasons returns why the resolved uc requires an approval under
+// policy. Namespaces without quota are checked against defaultQuota.
+func approvalReasons(policy *myoperatorv1alpha1.ApprovalPolicy, uc *myoperatorv1alpha1.UserConfig, defaultQuota *myoperatorv1alpha1.ResourceQuota) ([]string, error) {
+	var reasons []string
+
+	groups := policy.Groups
+	if len(groups) == 0 {
+		groups = defaultApprovalGroups
+	}
+	for _, group := range uc.Spec.Identity.Groups {
+		if slices.Contains(groups, group) {
+			reasons = append(reasons, "group "+group)
+		}
+	}
+
+	permissions := append([]myoperatorv1alpha1.ResourcePermission{}, uc.Spec.Permissions.Resources...)
+	for _, access := range uc.Spec.Permissions.Namespaces {
+		permissions = append(permissions, access.Resources...)
+	}
+	reasons = append(reasons, permissionApprovalReasons(policy, permissions)...)
+
+	for _, ns := range tenantNamespaces(uc) {
+		quotaReasons, err := quotaApprovalReasons(policy, ns.Name, ns.ResourceQuotas, defaultQuota)
+		if err != nil {
+			return nil, err
+		}
+		reasons = append(reasons, quotaReasons...)
+	}
+	return reasons, nil
+}
+
+// teamApprovalReasons returns why team requires an approval under policy. The
+// permissions of every role are checked, prefixed with the role name.
+func teamApprovalReasons(policy *myoperatorv1alpha1.ApprovalPolicy, team *myoperatorv1alpha1.Team, defaultQuota *myoperatorv1alpha1.ResourceQuota) ([]string, error) {
+	var reasons []string
+	for _, role := range team.Spec.Roles {
+		for _, reason := range permissionApprovalReasons(policy, role.Resources) {
+			reasons = append(reasons, fmt.Sprintf("role %s: %s", role.Name, reason))
+		}
+	}
+	quotaReasons, err := quotaApprovalReasons(policy, TeamNamespace(team), team.Spec.ResourceQuotas, defaultQuota)
+	if err != nil {
+		return nil, err
+	}
+	return append(reasons, quotaReasons...), nil
+}
+
+// permissionApprovalReasons returns the permissions granting write access to
+// a resource requiring an approval under policy, e.g. "secret *"
+func permissionApprovalReasons(policy *myoperatorv1alpha1.ApprovalPolicy, permissions []myoperatorv1alpha1.ResourcePermission) []string {
+	resources := policy.Resources
+	if len(resources) == 0 {
+		resources = defaultApprovalResources
+	}
+	var reasons []string
+	for _, perm := range permissions {
+		if slices.Contains(resources, perm.Resource) && strings.ContainsAny(perm.Operation, "CUD*") {
+			reason := fmt.Sprintf("%s %s", perm.Resource, perm.Operation)
+			if !slices.Contains(reasons, reason) {
+				reasons = append(reasons, reason)
+			}
+		}
+	}
+	return reasons
+}
+
+// quotaApprovalReasons returns the hard limits of the quota of namespace above
+// the thresholds of policy. A nil quota is checked against defaultQuota.
+func quotaApprovalReasons(policy *myoperatorv1alpha1.ApprovalPolicy, namespace string, quota, defaultQuota *myoperatorv1alpha1.ResourceQuota) ([]string, error) {
+	names := make([]string, 0, len(policy.ResourceQuota))
+	for name := range policy.ResourceQuota {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	spec, err := resourceQuotaSpec(quota, defaultQuota)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ResourceQuota of namespace %s: %w", namespace, err)
+	}
+	var reasons []string
+	for _, name := range names {
+		threshold, err := resource.ParseQuantity(policy.ResourceQuota[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid approval threshold %q of %s: %w", policy.ResourceQuota[name], name, err)
+		}
+		if hard, ok := spec.Hard[corev1.ResourceName(name)]; ok && hard.Cmp(threshold) > 0 {
+			reasons = append(reasons, fmt.Sprintf("%s %s in namespace %s exceeds %s", name, hard.String(), namespace, threshold.String()))
+		}
+	}
+	return reasons, nil
+}
+
+// ReconcileApproval records in the status of the resolved uc whether the
+// OperatorConfig approval policy requires an approval and which AccessApproval
+// granted it. It reports whether uc may be provisioned; the caller is
+// responsible for persisting the status.
+func (u *UserConfigUseCase) ReconcileApproval(ctx context.Context, uc *myoperatorv1alpha1.UserConfig) (bool, error) {
+	defaults := u.Defaults.Spec()
+	if defaults.Approval == nil {
+		uc.Status.Approval = nil
+		return true, nil
+	}
+	reasons, err := approvalReasons(defaults.Approval, uc, defaults.DefaultResourceQuota)
+	if err != nil {
+		return false, err
+	}
+	status, err := u.reconcileApproval(ctx, uc, uc.Status.Approval, defaults.Approval, reasons, specHash(uc), uc.Spec.Identity.Username,
+		func(approval *myoperatorv1alpha1.AccessApproval) bool { return approval.Spec.UserConfig == uc.Name })
+	uc.Status.Approval = status
+	return status == nil || status.Approver != "", err
+}
+
+// ReconcileTeamApproval is ReconcileApproval for the roles and quota of team
+func (u *UserConfigUseCase) ReconcileTeamApproval(ctx context.Context, team *myoperatorv1alpha1.Team) (bool, error) {
+	defaults := u.Defaults.Spec()
+	if defaults.Approval == nil {
+		team.Status.Approval = nil
+		return true, nil
+	}
+	reasons, err := teamApprovalReasons(defaults.Approval, team, defaults.DefaultResourceQuota)
+	if err != nil {
+		return false, err
+	}
+	status, err := u.reconcileApproval(ctx, team, team.Status.Approval, defaults.Approval, reasons, hashOf(team.Spec), "",
+		func(approval *myoperatorv1alpha1.AccessApproval) bool { return approval.Spec.Team == team.Name })
+	team.Status.Approval = status
+	return status == nil || status.Approver != "", err
+}
+
+// reconcileApproval returns the approval status of obj requiring an approval
+// for reasons, nil when there are none. The AccessApprovals selected by
+// approves must reference hash and be created by one of the approvers of
+// policy other than requester, which only the AccessApproval webhook verifies:
+// obj stays pending without it. Events are recorded on obj when the status
+// differs from previous.
+func (u *UserConfigUseCase) reconcileApproval(ctx context.Context, obj runtime.Object, previous *myoperatorv1alpha1.ApprovalStatus, policy *myoperatorv1alpha1.ApprovalPolicy,
+	reasons []string, hash, requester string, approves func(*myoperatorv1alpha1.AccessApproval) bool) (*myoperatorv1alpha1.ApprovalStatus, error) {
+	if len(reasons) == 0 {
+		return nil, nil
+	}
+
+	status := &myoperatorv1alpha1.ApprovalStatus{Reasons: reasons, SpecHash: hash}
+	changed := previous == nil || previous.SpecHash != status.SpecHash || previous.Approver != ""
+	if !u.Config.ApprovalsVerified {
+		if changed {
+			u.Recorder.Eventf(obj, corev1.EventTypeWarning, EventReasonApprovalRequired,
+				"AccessApprovals aren't trusted without the AccessApproval webhook, enable the webhooks to approve spec hash %s: %s",
+				status.SpecHash, strings.Join(reasons, ", "))
+		}
+		return status, nil
+	}
+	approval, err := u.findAccessApproval(ctx, policy, hash, requester, approves)
+	if err != nil {
+		return status, err
+	}
+	if approval == nil {
+		if changed {
+			u.Recorder.Eventf(obj, corev1.EventTypeWarning, EventReasonApprovalRequired,
+				"Waiting for an AccessApproval of spec hash %s: %s", status.SpecHash, strings.Join(reasons, ", "))
+		}
+		return status, nil
+	}
+
+	status.Approver = approval.Spec.Approver
+	status.AccessApproval = approval.Name
+	status.ApprovedAt = approval.CreationTimestamp.DeepCopy()
+	if previous == nil || previous.AccessApproval != approval.Name || previous.SpecHash != status.SpecHash {
+		u.Recorder.Eventf(obj, corev1.EventTypeNormal, EventReasonApproved,
+			"Approved by %s through AccessApproval %s", approval.Spec.Approver, approval.Name)
+	}
+	return status, nil
+}
+
+// findAccessApproval returns the oldest AccessApproval selected by approves
+// for hash, created by one of the approvers of policy other than requester
+func (u *UserConfigUseCase) findAccessApproval(ctx context.Context, policy *myoperatorv1alpha1.ApprovalPolicy, hash, requester string, approves func(*myoperatorv1alpha1.AccessApproval) bool) (*myoperatorv1alpha1.AccessApproval, error) {
+	approvals := &myoperatorv1alpha1.AccessApprovalList{}
+	if err := u.List(ctx, approvals); err != nil {
+		return nil, fmt.Errorf("failed to list AccessApprovals: %w", err)
+	}
+	var found *myoperatorv1alpha1.AccessApproval
+	for i := range approvals.Items {
+		approval := &approvals.Items[i]
+		if !approves(approval) || approval.Spec.SpecHash != hash || !approval.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if !slices.Contains(policy.Approvers, approval.Spec.Approver) || (requester != "" && approval.Spec.Approver == requester) {
+			continue
+		}
+		if found == nil || approval.CreationTimestamp.Before(&found.CreationTimestamp) {
+			found = approval
+		}
+	}
+	return found, nil
+}
@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+)
+
+var _ = Describe("approvals", func() {
+	var (
+		ctx      context.Context
+		recorder *record.FakeRecorder
+		policy   *myoperatorv1alpha1.ApprovalPolicy
+		uc       *myoperatorv1alpha1.UserConfig
+	)
+
+	// newUseCase returns a use case enforcing the approval policy
+	newUseCase := func(objs ...client.Object) *UserConfigUseCase {
+		var u *UserConfigUseCase
+		u, recorder = newTestUseCase(objs...)
+		u.Defaults = NewOperatorDefaults()
+		u.Defaults.Set(myoperatorv1alpha1.OperatorConfigSpec{Approval: policy})
+		return u
+	}
+
+	approval := func(name, approver, hash string) *myoperatorv1alpha1.AccessApproval {
+		return &myoperatorv1alpha1.AccessApproval{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       myoperatorv1alpha1.AccessApprovalSpec{UserConfig: "alice", SpecHash: hash, Approver: approver},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		policy = &myoperatorv1alpha1.ApprovalPolicy{
+			ResourceQuota: map[string]string{"cpu": "4"},
+			Approvers:     []string{"sec-lead", "alice"},
+		}
+		uc = &myoperatorv1alpha1.UserConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "alice"},
+			Spec: myoperatorv1alpha1.UserConfigSpec{
+				Identity: myoperatorv1alpha1.Identity{Username: "alice", Groups: []string{"developer", "admin"}},
+				Permissions: myoperatorv1alpha1.Permissions{Resources: []myoperatorv1alpha1.ResourcePermission{
+					{Resource: "secret", Operation: "*"},
+					{Resource: "configmap", Operation: "CRUD"},
+				}},
+				ResourceQuotas: &myoperatorv1alpha1.ResourceQuota{CPU: "8"},
+			},
+		}
+	})
+
+	It("lists the groups, permissions and quotas requiring an approval", func() {
+		reasons, err := approvalReasons(policy, uc, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reasons).To(Equal([]string{"group admin", "secret *", "cpu 8 in namespace alice exceeds 4"}))
+
+		uc.Spec.Identity.Groups = []string{"developer"}
+		uc.Spec.Permissions.Resources = []myoperatorv1alpha1.ResourcePermission{{Resource: "secret", Operation: "R"}}
+		uc.Spec.ResourceQuotas = nil
+		reasons, err = approvalReasons(policy, uc, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reasons).To(BeEmpty())
+	})
+
+	It("doesn't hold anything without an approval policy", func() {
+		policy = nil
+		u := newUseCase()
+		Expect(u.ReconcileApproval(ctx, uc)).To(BeTrue())
+		Expect(uc.Status.Approval).To(BeNil())
+	})
+
+	It("holds the UserConfig until an approver approves its current spec", func() {
+		hash := specHash(uc)
+		u := newUseCase(
+			approval("self", "alice", hash),
+			approval("stranger", "mallory", hash),
+			approval("stale", "sec-lead", "0000000000000000"),
+		)
+		Expect(u.ReconcileApproval(ctx, uc)).To(BeFalse())
+		Expect(uc.Status.Approval.SpecHash).To(Equal(hash))
+		Expect(uc.Status.Approval.Approver).To(BeEmpty())
+		Expect(recorder.Events).To(Receive(ContainSubstring("ApprovalRequired Waiting for an AccessApproval of spec hash " + hash)))
+
+		// Reconciling again doesn't repeat the event
+		Expect(u.ReconcileApproval(ctx, uc)).To(BeFalse())
+		Expect(recorder.Events).To(BeEmpty())
+
+		Expect(u.Create(ctx, approval("granted", "sec-lead", hash))).To(Succeed())
+		Expect(u.ReconcileApproval(ctx, uc)).To(BeTrue())
+		Expect(uc.Status.Approval.Approver).To(Equal("sec-lead"))
+		Expect(uc.Status.Approval.AccessApproval).To(Equal("granted"))
+		Expect(recorder.Events).To(Receive(ContainSubstring("Approved Approved by sec-lead through AccessApproval granted")))
+
+		// Changing the spec lapses the approval
+		uc.Spec.ResourceQuotas.CPU = "16"
+		Expect(u.ReconcileApproval(ctx, uc)).To(BeFalse())
+		Expect(uc.Status.Approval.Approver).To(BeEmpty())
+	})
+
+	It("trusts no AccessApproval without the webhook verifying the approvers", func() {
+		u := newUseCase(approval("forged", "sec-lead", specHash(uc)))
+		u.Config.ApprovalsVerified = false
+		Expect(u.ReconcileApproval(ctx, uc)).To(BeFalse())
+		Expect(uc.Status.Approval.Approver).To(BeEmpty())
+		Expect(recorder.Events).To(Receive(ContainSubstring("AccessApprovals aren't trusted without the AccessApproval webhook")))
+	})
+
+	It("holds the Teams granting elevated roles until approved", func() {
+		team := &myoperatorv1alpha1.Team{
+			ObjectMeta: metav1.ObjectMeta{Name: "payments"},
+			Spec: myoperatorv1alpha1.TeamSpec{
+				Roles: []myoperatorv1alpha1.TeamRole{
+					{Name: "developer", Resources: []myoperatorv1alpha1.ResourcePermission{{Resource: "deployment", Operation: "CRUD"}}},
+					{Name: "operator", Resources: []myoperatorv1alpha1.ResourcePermission{{Resource: "secret", Operation: "*"}}},
+				},
+			},
+		}
+		reasons, err := teamApprovalReasons(policy, team, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reasons).To(Equal([]string{"role operator: secret *"}))
+
+		hash := hashOf(team.Spec)
+		userConfigApproval := approval("for-alice", "sec-lead", hash)
+		u := newUseCase(userConfigApproval)
+		Expect(u.ReconcileTeamApproval(ctx, team)).To(BeFalse())
+		Expect(team.Status.Approval.SpecHash).To(Equal(hash))
+		Expect(recorder.Events).To(Receive(ContainSubstring("ApprovalRequired")))
+
+		teamApproval := approval("for-payments", "sec-lead", hash)
+		teamApproval.Spec.UserConfig = ""
+		teamApproval.Spec.Team = "payments"
+		Expect(u.Create(ctx, teamApproval)).To(Succeed())
+		Expect(u.ReconcileTeamApproval(ctx, team)).To(BeTrue())
+		Expect(team.Status.Approval.AccessApproval).To(Equal("for-payments"))
+
+		// Dropping the elevated role doesn't require an approval anymore
+		team.Spec.Roles = team.Spec.Roles[:1]
+		Expect(u.ReconcileTeamApproval(ctx, team)).To(BeTrue())
+		Expect(team.Status.Approval).To(BeNil())
+	})
+})
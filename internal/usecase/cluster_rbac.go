@@ -0,0 +1,193 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+)
+
+// clusterResourceGroups maps the cluster scoped resources that can be put on
+// the read allowlist to their API group
+var clusterResourceGroups = map[string]string{
+	"nodes":                     "",
+	"namespaces":                "",
+	"persistentvolumes":         "",
+	"storageclasses":            "storage.k8s.io",
+	"csidrivers":                "storage.k8s.io",
+	"csinodes":                  "storage.k8s.io",
+	"ingressclasses":            "networking.k8s.io",
+	"priorityclasses":           "scheduling.k8s.io",
+	"runtimeclasses":            "node.k8s.io",
+	"customresourcedefinitions": "apiextensions.k8s.io",
+}
+
+// clusterRoleName returns the name of the ClusterRole and ClusterRoleBinding of uc
+func clusterRoleName(uc *myoperatorv1alpha1.UserConfig) string {
+	return fmt.Sprintf("userconfig:%s", uc.Name)
+}
+
+// ReconcileClusterRBAC manages the per-user ClusterRole and ClusterRoleBinding
+// granting read access to the cluster scoped resources of spec.clusterPermissions
+// that are on the operator allowlist. Both are removed when nothing is granted.
+func (u *UserConfigUseCase) ReconcileClusterRBAC(ctx context.Context, uc *myoperatorv1alpha1.UserConfig) error {
+	rules, denied := u.clusterPolicyRules(uc)
+	if len(denied) > 0 && !reflect.DeepEqual(denied, uc.Status.DeniedClusterPermissions) {
+		u.Recorder.Eventf(uc, corev1.EventTypeWarning, EventReasonClusterAccessDenied,
+			"Cluster read access not granted for: %s", strings.Join(denied, ", "))
+	}
+	uc.Status.DeniedClusterPermissions = denied
+	if len(rules) == 0 {
+		return u.deleteClusterRBAC(ctx, uc)
+	}
+
+	clusterRole := &rbacv1.ClusterRole{
+		ObjectMeta: objectMeta(uc, clusterRoleName(uc), ""),
+		Rules:      rules,
+	}
+	if err := u.setManagedMetadata(uc, clusterRole); err != nil {
+		return fmt.Errorf("failed to set clusterrole owner reference: %w", err)
+	}
+
+	if err := u.Create(ctx, clusterRole); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create clusterrole: %w", err)
+		}
+
+		existing := &rbacv1.ClusterRole{}
+		if err := u.Get(ctx, client.ObjectKey{Name: clusterRole.Name}, existing); err != nil {
+			return fmt.Errorf("failed to get existing clusterrole: %w", err)
+		}
+		metadataChanged, err := u.updateManagedMetadata(uc, existing)
+		if err != nil {
+			return fmt.Errorf("failed to set clusterrole owner reference: %w", err)
+		}
+		if metadataChanged || !reflect.DeepEqual(existing.Rules, rules) {
+			existing.Rules = rules
+			if err := u.Update(ctx, existing); err != nil {
+				return fmt.Errorf("failed to update clusterrole: %w", err)
+			}
+		}
+	} else {
+		u.Recorder.Eventf(uc, corev1.EventTypeNormal, EventReasonClusterRoleCreated, "Created ClusterRole %s", clusterRole.Name)
+	}
+
+	clusterRoleBinding := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: objectMeta(uc, clusterRoleName(uc), ""),
+		Subjects:   userSubjects(uc),
+		RoleRef: rbacv1.RoleRef{
+			Kind:     "ClusterRole",
+			Name:     clusterRole.Name,
+			APIGroup: "rbac.authorization.k8s.io",
+		},
+	}
+	if err := u.setManagedMetadata(uc, clusterRoleBinding); err != nil {
+		return fmt.Errorf("failed to set clusterrolebinding owner reference: %w", err)
+	}
+
+	if err := u.Create(ctx, clusterRoleBinding); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create clusterrolebinding: %w", err)
+		}
+
+		existing := &rbacv1.ClusterRoleBinding{}
+		if err := u.Get(ctx, client.ObjectKey{Name: clusterRoleBinding.Name}, existing); err != nil {
+			return fmt.Errorf("failed to get existing clusterrolebinding: %w", err)
+		}
+		metadataChanged, err := u.updateManagedMetadata(uc, existing)
+		if err != nil {
+			return fmt.Errorf("failed to set clusterrolebinding owner reference: %w", err)
+		}
+		if metadataChanged || !reflect.DeepEqual(existing.Subjects, clusterRoleBinding.Subjects) {
+			existing.Subjects = clusterRoleBinding.Subjects
+			if err := u.Update(ctx, existing); err != nil {
+				return fmt.Errorf("failed to update clusterrolebinding: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// clusterPolicyRules returns the read-only rules granted to uc and the
+// requested resources that were refused
+func (u *UserConfigUseCase) clusterPolicyRules(uc *myoperatorv1alpha1.UserConfig) ([]rbacv1.PolicyRule, []string) {
+	perms := uc.Spec.ClusterPermissions
+	if perms == nil {
+		return nil, nil
+	}
+	if !u.Config.ClusterPermissionsEnabled {
+		return nil, []string{"cluster permissions are disabled by the operator"}
+	}
+
+	allowed := map[string]bool{}
+	for _, resource := range u.settings().ClusterReadResources {
+		allowed[strings.TrimSpace(resource)] = true
+	}
+
+	var rules []rbacv1.PolicyRule
+	var denied []string
+	seen := map[string]bool{}
+	for _, resource := range perms.Resources {
+		resource = mapActualResource(resource)
+		if seen[resource] {
+			continue
+		}
+		seen[resource] = true
+
+		group, known := clusterResourceGroups[resource]
+		if !known || !allowed[resource] {
+			denied = append(denied, resource)
+			continue
+		}
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{group},
+			Resources: []string{resource},
+			Verbs:     []string{"get", "list", "watch"},
+		})
+	}
+
+	if perms.OwnNamespaces {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups:     []string{""},
+			Resources:     []string{"namespaces"},
+			ResourceNames: NamespaceNames(uc),
+			Verbs:         []string{"get"},
+		})
+	}
+
+	return rules, denied
+}
+
+// deleteClusterRBAC removes the ClusterRoleBinding and ClusterRole of uc. They
+// are read from the cache first so UserConfigs that never had any don't issue
+// deletes on every reconcile.
+func (u *UserConfigUseCase) deleteClusterRBAC(ctx context.Context, uc *myoperatorv1alpha1.UserConfig) error {
+	key := client.ObjectKey{Name: clusterRoleName(uc)}
+	binding := &rbacv1.ClusterRoleBinding{}
+	if err := u.Get(ctx, key, binding); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to get clusterrolebinding: %w", err)
+	} else if err == nil {
+		if err := u.Delete(ctx, binding); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete clusterrolebinding: %w", err)
+		}
+	}
+	role := &rbacv1.ClusterRole{}
+	if err := u.Get(ctx, key, role); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to get clusterrole: %w", err)
+	} else if err == nil {
+		if err := u.Delete(ctx, role); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete clusterrole: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+)
+
+var _ = Describe("cluster RBAC", func() {
+	var (
+		ctx      context.Context
+		u        *UserConfigUseCase
+		recorder *record.FakeRecorder
+		uc       *myoperatorv1alpha1.UserConfig
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		uc = &myoperatorv1alpha1.UserConfig{ObjectMeta: metav1.ObjectMeta{Name: "alice", UID: "alice-uid"}}
+		u, recorder = newTestUseCase(uc)
+	})
+
+	DescribeTable("clusterPolicyRules",
+		func(enabled bool, perms *myoperatorv1alpha1.ClusterPermissions, resources, denied []string) {
+			u.Config.ClusterPermissionsEnabled = enabled
+			uc.Spec.ClusterPermissions = perms
+
+			rules, refused := u.clusterPolicyRules(uc)
+			var granted []string
+			for _, rule := range rules {
+				granted = append(granted, rule.Resources...)
+			}
+			Expect(granted).To(Equal(resources))
+			Expect(refused).To(Equal(denied))
+		},
+		Entry("nothing requested", true, nil, nil, nil),
+		Entry("allowlisted resources, duplicates skipped", true,
+			&myoperatorv1alpha1.ClusterPermissions{Resources: []string{"nodes", "storageclasses", "nodes"}},
+			[]string{"nodes", "storageclasses"}, nil),
+		Entry("resources off the allowlist or unknown", true,
+			&myoperatorv1alpha1.ClusterPermissions{Resources: []string{"nodes", "persistentvolumes", "clusterroles"}},
+			[]string{"nodes"}, []string{"persistentvolumes", "clusterroles"}),
+		Entry("own namespaces", true,
+			&myoperatorv1alpha1.ClusterPermissions{OwnNamespaces: true},
+			[]string{"namespaces"}, nil),
+		Entry("disabled by the operator", false,
+			&myoperatorv1alpha1.ClusterPermissions{Resources: []string{"nodes"}},
+			nil, []string{"cluster permissions are disabled by the operator"}),
+	)
+
+	It("grants read only access and removes it once nothing is requested", func() {
+		uc.Spec.ClusterPermissions = &myoperatorv1alpha1.ClusterPermissions{Resources: []string{"nodes"}, OwnNamespaces: true}
+		Expect(u.ReconcileClusterRBAC(ctx, uc)).To(Succeed())
+
+		role := &rbacv1.ClusterRole{}
+		Expect(u.Get(ctx, client.ObjectKey{Name: "userconfig:alice"}, role)).To(Succeed())
+		Expect(role.Rules).To(HaveLen(2))
+		Expect(role.Rules[0].Verbs).To(Equal([]string{"get", "list", "watch"}))
+		Expect(role.Rules[1].ResourceNames).To(Equal([]string{"alice"}))
+		binding := &rbacv1.ClusterRoleBinding{}
+		Expect(u.Get(ctx, client.ObjectKey{Name: "userconfig:alice"}, binding)).To(Succeed())
+		Expect(binding.Subjects).To(Equal(userSubjects(uc)))
+
+		uc.Spec.ClusterPermissions = nil
+		Expect(u.ReconcileClusterRBAC(ctx, uc)).To(Succeed())
+		Expect(apierrors.IsNotFound(u.Get(ctx, client.ObjectKey{Name: "userconfig:alice"}, &rbacv1.ClusterRole{}))).To(BeTrue())
+		Expect(apierrors.IsNotFound(u.Get(ctx, client.ObjectKey{Name: "userconfig:alice"}, &rbacv1.ClusterRoleBinding{}))).To(BeTrue())
+	})
+
+	It("reports the denied resources once", func() {
+		uc.Spec.ClusterPermissions = &myoperatorv1alpha1.ClusterPermissions{Resources: []string{"persistentvolumes"}}
+		Expect(u.ReconcileClusterRBAC(ctx, uc)).To(Succeed())
+		Expect(uc.Status.DeniedClusterPermissions).To(Equal([]string{"persistentvolumes"}))
+		Expect(recorder.Events).To(Receive(ContainSubstring("ClusterAccessDenied")))
+
+		Expect(u.ReconcileClusterRBAC(ctx, uc)).To(Succeed())
+		Expect(recorder.Events).NotTo(Receive())
+
+		uc.Spec.ClusterPermissions = nil
+		Expect(u.ReconcileClusterRBAC(ctx, uc)).To(Succeed())
+		Expect(uc.Status.DeniedClusterPermissions).To(BeEmpty())
+	})
+})
@@ -6,12 +6,12 @@ import (
 	corev1 "k8s.io/api/core/v1"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+	"01cloud/zoperator/internal/metrics"
 )
 
 func (u *UserConfigUseCase) HandleDeletion(ctx context.Context, uc *myoperatorv1alpha1.UserConfig) (ctrl.Result, error) {
@@ -19,16 +19,32 @@ func (u *UserConfigUseCase) HandleDeletion(ctx context.Context, uc *myoperatorv1
 		return ctrl.Result{}, nil
 	}
 
-	// Delete namespace (this will cascade delete secrets)
-	namespace := &corev1.Namespace{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: uc.Name,
-		},
-	}
-	if err := u.Delete(ctx, namespace); err != nil && !apierrors.IsNotFound(err) {
+	// Delete every namespace created for the UserConfig (this will cascade delete secrets)
+	namespaces, err := u.listManagedNamespaces(ctx, uc)
+	if err != nil {
 		return ctrl.Result{}, err
 	}
 
+	defaults := u.Defaults.Spec()
+	for i := range namespaces.Items {
+		namespace := &namespaces.Items[i]
+		// Protected namespaces are never deleted, even if they were adopted before being protected,
+		// nor are the namespaces merely labelled for the UserConfig
+		if isProtectedNamespace(defaults, namespace.Name) || !isManagedFor(uc, namespace) {
+			continue
+		}
+		if err := u.Delete(ctx, namespace); err != nil {
+			if !apierrors.IsNotFound(err) {
+				u.Recorder.Eventf(uc, corev1.EventTypeWarning, EventReasonDeletionFailed, "Failed to delete namespace %s: %v", namespace.Name, err)
+				return ctrl.Result{}, err
+			}
+		} else {
+			u.Recorder.Eventf(uc, corev1.EventTypeNormal, EventReasonNamespaceDeleted, "Deleted namespace %s", namespace.Name)
+		}
+	}
+
+	metrics.DeleteUserConfig(uc.Name)
+
 	controllerutil.RemoveFinalizer(uc, "myoperator.01cloud.io/finalizer")
 	if err := u.Update(ctx, uc); err != nil {
 		return ctrl.Result{}, err
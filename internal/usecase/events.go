@@ -0,0 +1,35 @@
+package usecase
+
+// Event reasons recorded on UserConfig and Team objects. They show up in
+// `kubectl describe ucfg <name>` and `kubectl get events`.
+const (
+	EventReasonNamespaceCreated      = "NamespaceCreated"
+	EventReasonResourceQuotaCreated  = "ResourceQuotaCreated"
+	EventReasonResourceQuotaUpdated  = "ResourceQuotaUpdated"
+	EventReasonRoleCreated           = "RoleCreated"
+	EventReasonRoleUpdated           = "RoleUpdated"
+	EventReasonRoleBindingCreated    = "RoleBindingCreated"
+	EventReasonRoleBindingUpdated    = "RoleBindingUpdated"
+	EventReasonServiceAccountCreated = "ServiceAccountCreated"
+	EventReasonSecretSynced          = "SecretSynced"
+	EventReasonSecretSyncFailed      = "SecretSyncFailed"
+	EventReasonKubeconfigIssued      = "KubeconfigIssued"
+	EventReasonKubeconfigRotated     = "KubeconfigRotated"
+	EventReasonNamespaceDeleted      = "NamespaceDeleted"
+	EventReasonDeletionFailed        = "DeletionFailed"
+	EventReasonTeamMemberMissing     = "TeamMemberMissing"
+	EventReasonClusterRoleCreated    = "ClusterRoleCreated"
+	EventReasonClusterAccessDenied   = "ClusterAccessDenied"
+	EventReasonPermissionsFiltered   = "PermissionsFiltered"
+	EventReasonPodSecurityViolations = "PodSecurityViolations"
+	EventReasonMetadataIgnored       = "MetadataIgnored"
+	EventReasonFQDNPolicyIgnored     = "FQDNPolicyIgnored"
+	EventReasonResourceQuotaMigrated = "ResourceQuotaMigrated"
+	EventReasonQuotaScaledUp         = "QuotaScaledUp"
+	EventReasonQuotaScaledDown       = "QuotaScaledDown"
+	EventReasonApprovalRequired      = "ApprovalRequired"
+	EventReasonApproved              = "Approved"
+	EventReasonElevationGranted      = "ElevationGranted"
+	EventReasonElevationExpired      = "ElevationExpired"
+	EventReasonElevationRevoked      = "ElevationRevoked"
+)
@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"reflect"
 	"strings"
+	"time"
 
 	authenticationv1 "k8s.io/api/authentication/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -18,9 +20,9 @@ import (
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+	"01cloud/zoperator/internal/metrics"
 )
 
 func (u *UserConfigUseCase) GenerateAndSaveKubeconfig(ctx context.Context, uc *myoperatorv1alpha1.UserConfig) error {
@@ -63,34 +65,58 @@ func (u *UserConfigUseCase) GenerateAndSaveKubeconfig(ctx context.Context, uc *m
 		return fmt.Errorf("failed to create clientset: %w", err)
 	}
 
-	pointedValue := int64(86400 * 365)
-
-	// Create the TokenRequest object
-	tokenRequest := &authenticationv1.TokenRequest{
-		Spec: authenticationv1.TokenRequestSpec{
-			Audiences: []string{},
-			// Set expiration to 1 year
-			ExpirationSeconds: &pointedValue,
-		},
+	// Reuse the token of the existing secret until it is due for renewal, so
+	// reconciles don't mint a new token every pass
+	secretName := fmt.Sprintf("%s-kubeconfig", uc.Name)
+	existing := &corev1.Secret{}
+	if err := u.Get(ctx, client.ObjectKey{Name: secretName, Namespace: uc.Name}, existing); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get existing secret: %w", err)
+		}
+		existing = nil
 	}
+	lifetime := kubeconfigTokenLifetime(u.Defaults.Spec())
+	token, issued, expiry, reused := reusableToken(existing, lifetime, time.Now())
+	if !reused {
+		pointedValue := int64(lifetime.Seconds())
+
+		// Create the TokenRequest object
+		tokenRequest := &authenticationv1.TokenRequest{
+			Spec: authenticationv1.TokenRequestSpec{
+				Audiences: []string{},
+				// Set expiration to the OperatorConfig token lifetime, 1 year by default
+				ExpirationSeconds: &pointedValue,
+			},
+		}
 
-	// Create the token request through the API
-	tokenResponse, err := clientset.CoreV1().ServiceAccounts(uc.Name).CreateToken(
-		ctx,
-		uc.Name,
-		tokenRequest,
-		metav1.CreateOptions{},
-	)
-	if err != nil {
-		return fmt.Errorf("failed to create token: %w", err)
-	}
+		// Create the token request through the API
+		tokenResponse, err := clientset.CoreV1().ServiceAccounts(uc.Name).CreateToken(
+			ctx,
+			uc.Name,
+			tokenRequest,
+			metav1.CreateOptions{},
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create token: %w", err)
+		}
 
-	if tokenResponse.Status.Token == "" {
-		return fmt.Errorf("received empty token from API")
+		if tokenResponse.Status.Token == "" {
+			return fmt.Errorf("received empty token from API")
+		}
+		token, issued, expiry = tokenResponse.Status.Token, time.Now(), tokenResponse.Status.ExpirationTimestamp.Time
 	}
 
-	// Determine cluster name and context name
+	// One context per namespace, all sharing the ServiceAccount token. The
+	// primary namespace is the current context.
 	contextName := fmt.Sprintf("%s-context", uc.Name)
+	contexts := map[string]*clientcmdapi.Context{}
+	for _, namespace := range NamespaceNames(uc) {
+		contexts[fmt.Sprintf("%s-context", namespace)] = &clientcmdapi.Context{
+			Cluster:   clusterName,
+			AuthInfo:  uc.Name,
+			Namespace: namespace,
+		}
+	}
 
 	// Create kubeconfig structure
 	kubeconfig := clientcmdapi.Config{
@@ -103,17 +129,11 @@ func (u *UserConfigUseCase) GenerateAndSaveKubeconfig(ctx context.Context, uc *m
 				InsecureSkipTLSVerify:    config.Insecure,
 			},
 		},
-		Contexts: map[string]*clientcmdapi.Context{
-			contextName: {
-				Cluster:   clusterName,
-				AuthInfo:  uc.Name,
-				Namespace: uc.Name,
-			},
-		},
+		Contexts:       contexts,
 		CurrentContext: contextName,
 		AuthInfos: map[string]*clientcmdapi.AuthInfo{
 			uc.Name: {
-				Token: tokenResponse.Status.Token,
+				Token: token,
 			},
 		},
 	}
@@ -123,47 +143,82 @@ func (u *UserConfigUseCase) GenerateAndSaveKubeconfig(ctx context.Context, uc *m
 	if err != nil {
 		return fmt.Errorf("failed to serialize kubeconfig: %w", err)
 	}
+	data := map[string][]byte{"kubeconfig": kubeconfigBytes}
+	tokenAnnotations := map[string]string{
+		AnnotationTokenIssued: issued.UTC().Format(time.RFC3339),
+		AnnotationTokenExpiry: expiry.UTC().Format(time.RFC3339),
+	}
+	metrics.SetKubeconfigToken(uc.Name, issued, expiry)
+
+	if existing == nil {
+		// Create Secret object for storing kubeconfig
+		kubeconfigSecret := &corev1.Secret{
+			ObjectMeta: objectMeta(uc, secretName, uc.Name),
+			Type:       corev1.SecretTypeOpaque,
+			Data:       data,
+		}
+		kubeconfigSecret.Annotations = mergeStringMaps(kubeconfigSecret.Annotations, tokenAnnotations)
 
-	// Create Secret object for storing kubeconfig
-	kubeconfigSecret := &corev1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("%s-kubeconfig", uc.Name),
-			Namespace: uc.Name,
-			Labels: map[string]string{
-				"app.kubernetes.io/managed-by":          "userconfig-operator",
-				"userconfig.myoperator.01cloud.io/name": uc.Name,
-			},
-		},
-		Type: corev1.SecretTypeOpaque,
-		Data: map[string][]byte{
-			"kubeconfig": kubeconfigBytes,
-		},
+		// Set controller reference
+		if err := u.setManagedMetadata(uc, kubeconfigSecret); err != nil {
+			return fmt.Errorf("failed to set owner reference: %w", err)
+		}
+		if err := u.Create(ctx, kubeconfigSecret); err != nil {
+			return fmt.Errorf("failed to create kubeconfig secret: %w", err)
+		}
+		u.Recorder.Eventf(uc, corev1.EventTypeNormal, EventReasonKubeconfigIssued, "Issued kubeconfig in secret %s/%s", uc.Name, secretName)
+		return nil
 	}
 
-	// Set controller reference
-	if err := controllerutil.SetControllerReference(uc, kubeconfigSecret, u.Scheme); err != nil {
+	// Update the existing secret only when something changed
+	changed, err := u.updateManagedMetadata(uc, existing)
+	if err != nil {
 		return fmt.Errorf("failed to set owner reference: %w", err)
 	}
+	annotations := mergeStringMaps(existing.Annotations, tokenAnnotations)
+	if !changed && reflect.DeepEqual(existing.Data, data) && reflect.DeepEqual(existing.Annotations, annotations) {
+		return nil
+	}
+	existing.Data = data
+	existing.Annotations = annotations
+	if err := u.Update(ctx, existing); err != nil {
+		return fmt.Errorf("failed to update kubeconfig secret: %w", err)
+	}
+	if !reused {
+		u.Recorder.Eventf(uc, corev1.EventTypeNormal, EventReasonKubeconfigRotated, "Rotated kubeconfig token in secret %s/%s", uc.Name, secretName)
+	}
 
-	// Create or update the Secret
-	err = u.Create(ctx, kubeconfigSecret)
+	return nil
+}
+
+// reusableToken returns the token of the kubeconfig stored in secret with the
+// times it was issued and expires at, unless it is due for renewal: past four
+// fifths of its validity, or outliving a shortened lifetime
+func reusableToken(secret *corev1.Secret, lifetime time.Duration, now time.Time) (string, time.Time, time.Time, bool) {
+	if secret == nil {
+		return "", time.Time{}, time.Time{}, false
+	}
+	issued, err := time.Parse(time.RFC3339, secret.Annotations[AnnotationTokenIssued])
 	if err != nil {
-		if !apierrors.IsAlreadyExists(err) {
-			return fmt.Errorf("failed to create kubeconfig secret: %w", err)
-		}
-		// Update existing secret
-		existing := &corev1.Secret{}
-		if err := u.Get(ctx, client.ObjectKey{Name: kubeconfigSecret.Name, Namespace: uc.Name}, existing); err != nil {
-			return fmt.Errorf("failed to get existing secret: %w", err)
-		}
-		existing.Data = kubeconfigSecret.Data
-		existing.Labels = kubeconfigSecret.Labels
-		if err := u.Update(ctx, existing); err != nil {
-			return fmt.Errorf("failed to update kubeconfig secret: %w", err)
+		return "", time.Time{}, time.Time{}, false
+	}
+	expiry, err := time.Parse(time.RFC3339, secret.Annotations[AnnotationTokenExpiry])
+	if err != nil {
+		return "", time.Time{}, time.Time{}, false
+	}
+	if remaining := expiry.Sub(now); remaining < expiry.Sub(issued)/5 || remaining > lifetime {
+		return "", time.Time{}, time.Time{}, false
+	}
+	kubeconfig, err := clientcmd.Load(secret.Data["kubeconfig"])
+	if err != nil {
+		return "", time.Time{}, time.Time{}, false
+	}
+	for _, authInfo := range kubeconfig.AuthInfos {
+		if authInfo.Token != "" {
+			return authInfo.Token, issued, expiry, true
 		}
 	}
-
-	return nil
+	return "", time.Time{}, time.Time{}, false
 }
 
 func getKindServerAddress() (*clientcmdapi.Cluster, string, error) {
@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+var _ = Describe("reusableToken", func() {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	lifetime := 100 * time.Hour
+
+	secret := func(issued, expiry time.Time) *corev1.Secret {
+		kubeconfig := clientcmdapi.Config{AuthInfos: map[string]*clientcmdapi.AuthInfo{"alice": {Token: "token"}}}
+		data, err := clientcmd.Write(kubeconfig)
+		Expect(err).NotTo(HaveOccurred())
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+				AnnotationTokenIssued: issued.Format(time.RFC3339),
+				AnnotationTokenExpiry: expiry.Format(time.RFC3339),
+			}},
+			Data: map[string][]byte{"kubeconfig": data},
+		}
+	}
+
+	It("reuses a fresh token", func() {
+		issued := now.Add(-10 * time.Hour)
+		token, gotIssued, gotExpiry, ok := reusableToken(secret(issued, issued.Add(lifetime)), lifetime, now)
+		Expect(ok).To(BeTrue())
+		Expect(token).To(Equal("token"))
+		Expect(gotIssued).To(BeTemporally("==", issued))
+		Expect(gotExpiry).To(BeTemporally("==", issued.Add(lifetime)))
+	})
+
+	DescribeTable("renews the token",
+		func(s *corev1.Secret) {
+			_, _, _, ok := reusableToken(s, lifetime, now)
+			Expect(ok).To(BeFalse())
+		},
+		Entry("without secret", nil),
+		Entry("without annotations", &corev1.Secret{}),
+		Entry("past four fifths of its validity", secret(now.Add(-81*time.Hour), now.Add(19*time.Hour))),
+		Entry("outliving a shortened lifetime", secret(now, now.Add(2*lifetime))),
+	)
+})
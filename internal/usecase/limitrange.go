@@ -8,15 +8,62 @@ import (
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
 )
 
 func (u *UserConfigUseCase) ReconcileLimitRange(ctx context.Context, userConfig *myoperatorv1alpha1.UserConfig) error {
+	for _, ns := range tenantNamespaces(userConfig) {
+		if err := u.reconcileLimitRange(ctx, userConfig, ns); err != nil {
+			return fmt.Errorf("namespace %s: %w", ns.Name, err)
+		}
+	}
+	return nil
+}
+
+func (u *UserConfigUseCase) reconcileLimitRange(ctx context.Context, userConfig *myoperatorv1alpha1.UserConfig, ns tenantNamespace) error {
+	spec, err := limitRangeSpec(ns.LimitRange, u.Defaults.Spec().DefaultLimitRange)
+	if err != nil {
+		return fmt.Errorf("invalid LimitRange: %w", err)
+	}
+	limitRange := &corev1.LimitRange{
+		ObjectMeta: objectMeta(userConfig, userConfig.Name, ns.Name),
+		Spec:       spec,
+	}
+
+	// Set controller reference
+	if err := u.setManagedMetadata(userConfig, limitRange); err != nil {
+		return fmt.Errorf("failed to set managed metadata for LimitRange: %w", err)
+	}
+
+	// Create or update the LimitRange
+	existing := &corev1.LimitRange{}
+	err = u.Get(ctx, client.ObjectKey{Namespace: ns.Name, Name: limitRange.Name}, existing)
+	if err != nil && apierrors.IsNotFound(err) {
+		if err := u.Create(ctx, limitRange); err != nil {
+			return fmt.Errorf("failed to create LimitRange: %w", err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("failed to get LimitRange: %w", err)
+	} else {
+		existing.Spec = limitRange.Spec
+		if err := u.setManagedMetadata(userConfig, existing); err != nil {
+			return fmt.Errorf("failed to set managed metadata for LimitRange: %w", err)
+		}
+		if err := u.Update(ctx, existing); err != nil {
+			return fmt.Errorf("failed to update LimitRange: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// limitRangeSpec converts lr into a LimitRange spec. An empty lr falls back to
+// defaults, the OperatorConfig default limits, then to the built-in container
+// limits. The container limits of defaults also fill the fields lr leaves unset.
+func limitRangeSpec(lr, defaults *myoperatorv1alpha1.LimitRange) (corev1.LimitRangeSpec, error) {
 	defaultLimits := corev1.LimitRangeItem{
 		Type: corev1.LimitTypeContainer,
 		Default: corev1.ResourceList{
@@ -37,62 +84,95 @@ func (u *UserConfigUseCase) ReconcileLimitRange(ctx context.Context, userConfig
 		},
 	}
 
-	limitRange := &corev1.LimitRange{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      userConfig.Name,
-			Namespace: userConfig.Name,
-		},
-		Spec: corev1.LimitRangeSpec{
-			Limits: []corev1.LimitRangeItem{},
-		},
+	spec := corev1.LimitRangeSpec{
+		Limits: []corev1.LimitRangeItem{},
 	}
-	if userConfig.Spec.LimitRange == nil || len(userConfig.Spec.LimitRange.Limits) == 0 {
-		limitRange.Spec.Limits = []corev1.LimitRangeItem{defaultLimits}
-	} else {
-		for _, userLimits := range userConfig.Spec.LimitRange.Limits {
-			item := corev1.LimitRangeItem{
-				Type: corev1.LimitType(userLimits.Type),
-				Max:  parseResourceList(userLimits.Max, defaultLimits.Max),
-				Min:  parseResourceList(userLimits.Min, defaultLimits.Min),
+	if defaults != nil && len(defaults.Limits) > 0 {
+		for _, limits := range defaults.Limits {
+			if corev1.LimitType(limits.Type) != corev1.LimitTypeContainer {
+				continue
 			}
-			if userLimits.Type != string(corev1.LimitTypePod) {
-				item.Default = parseResourceList(userLimits.Default, defaultLimits.Default)
-				item.DefaultRequest = parseResourceList(userLimits.DefaultRequest, defaultLimits.DefaultRequest)
+			item, err := limitRangeItem(limits, defaultLimits)
+			if err != nil {
+				return spec, fmt.Errorf("default %s limits: %w", limits.Type, err)
 			}
-			limitRange.Spec.Limits = append(limitRange.Spec.Limits, item)
+			defaultLimits = item
+		}
+		if lr == nil || len(lr.Limits) == 0 {
+			lr = defaults
 		}
 	}
+	if lr == nil || len(lr.Limits) == 0 {
+		spec.Limits = []corev1.LimitRangeItem{defaultLimits}
+		return spec, nil
+	}
 
-	// Set controller reference
-	if err := controllerutil.SetControllerReference(userConfig, limitRange, u.Scheme); err != nil {
-		return fmt.Errorf("failed to set controller reference for LimitRange: %w", err)
+	for _, userLimits := range lr.Limits {
+		item, err := limitRangeItem(userLimits, defaultLimits)
+		if err != nil {
+			return spec, fmt.Errorf("%s limits: %w", userLimits.Type, err)
+		}
+		spec.Limits = append(spec.Limits, item)
 	}
+	return spec, nil
+}
 
-	// Create or update the LimitRange
-	existing := &corev1.LimitRange{}
-	err := u.Get(ctx, client.ObjectKey{Namespace: userConfig.Name, Name: limitRange.Name}, existing)
-	if err != nil && apierrors.IsNotFound(err) {
-		if err := u.Create(ctx, limitRange); err != nil {
-			return fmt.Errorf("failed to create LimitRange: %w", err)
+// limitRangeItem converts the limits of a type. PersistentVolumeClaim limits
+// only bound the storage and never fall back to the default container limits.
+func limitRangeItem(limits myoperatorv1alpha1.LimitRangeLimit, defaultLimits corev1.LimitRangeItem) (corev1.LimitRangeItem, error) {
+	item := corev1.LimitRangeItem{Type: corev1.LimitType(limits.Type)}
+	var err error
+	if item.Type == corev1.LimitTypePersistentVolumeClaim {
+		if item.Max, err = parseResourceList(limits.Max, nil); err != nil {
+			return item, fmt.Errorf("max: %w", err)
 		}
-	} else if err != nil {
-		return fmt.Errorf("failed to get LimitRange: %w", err)
-	} else {
-		existing.Spec = limitRange.Spec
-		if err := u.Update(ctx, existing); err != nil {
-			return fmt.Errorf("failed to update LimitRange: %w", err)
+		if item.Min, err = parseResourceList(limits.Min, nil); err != nil {
+			return item, fmt.Errorf("min: %w", err)
 		}
+		return item, nil
 	}
 
-	return nil
+	if item.Max, err = parseResourceList(limits.Max, defaultLimits.Max); err != nil {
+		return item, fmt.Errorf("max: %w", err)
+	}
+	if item.Min, err = parseResourceList(limits.Min, defaultLimits.Min); err != nil {
+		return item, fmt.Errorf("min: %w", err)
+	}
+	if item.MaxLimitRequestRatio, err = parseResourceList(limits.MaxLimitRequestRatio, nil); err != nil {
+		return item, fmt.Errorf("maxLimitRequestRatio: %w", err)
+	}
+	// Pods have no defaults, they come from the containers
+	if item.Type != corev1.LimitTypePod {
+		if item.Default, err = parseResourceList(limits.Default, defaultLimits.Default); err != nil {
+			return item, fmt.Errorf("default: %w", err)
+		}
+		if item.DefaultRequest, err = parseResourceList(limits.DefaultRequest, defaultLimits.DefaultRequest); err != nil {
+			return item, fmt.Errorf("defaultRequest: %w", err)
+		}
+	}
+	return item, nil
 }
 
-func parseResourceList(input *myoperatorv1alpha1.Resources, fallback corev1.ResourceList) corev1.ResourceList {
+// parseResourceList converts the quantities set in input, returning fallback when input is nil
+func parseResourceList(input *myoperatorv1alpha1.Resources, fallback corev1.ResourceList) (corev1.ResourceList, error) {
 	if input == nil {
-		return fallback
+		return fallback, nil
 	}
-	return corev1.ResourceList{
-		corev1.ResourceCPU:    resource.MustParse(input.CPU),
-		corev1.ResourceMemory: resource.MustParse(input.Memory),
+	list := corev1.ResourceList{}
+	for name, value := range map[corev1.ResourceName]string{
+		corev1.ResourceCPU:              input.CPU,
+		corev1.ResourceMemory:           input.Memory,
+		corev1.ResourceEphemeralStorage: input.EphemeralStorage,
+		corev1.ResourceStorage:          input.Storage,
+	} {
+		if value == "" {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantity %q of %s: %w", value, name, err)
+		}
+		list[name] = quantity
 	}
+	return list, nil
 }
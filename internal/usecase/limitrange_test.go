@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+)
+
+var _ = Describe("limit ranges", func() {
+	It("maps the PersistentVolumeClaim, ephemeral storage and ratio limits", func() {
+		spec, err := limitRangeSpec(&myoperatorv1alpha1.LimitRange{Limits: []myoperatorv1alpha1.LimitRangeLimit{
+			{
+				Type:                 "Container",
+				Max:                  &myoperatorv1alpha1.Resources{CPU: "2", EphemeralStorage: "4Gi"},
+				Default:              &myoperatorv1alpha1.Resources{EphemeralStorage: "1Gi"},
+				MaxLimitRequestRatio: &myoperatorv1alpha1.Resources{CPU: "4"},
+			},
+			{
+				Type: "PersistentVolumeClaim",
+				Min:  &myoperatorv1alpha1.Resources{Storage: "1Gi"},
+				Max:  &myoperatorv1alpha1.Resources{Storage: "100Gi"},
+			},
+		}}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spec.Limits).To(HaveLen(2))
+
+		container := spec.Limits[0]
+		Expect(container.Max.Cpu().String()).To(Equal("2"))
+		Expect(container.Max.StorageEphemeral().String()).To(Equal("4Gi"))
+		Expect(container.Max).NotTo(HaveKey(corev1.ResourceMemory))
+		Expect(container.Default).To(HaveLen(1))
+		Expect(container.MaxLimitRequestRatio.Cpu().String()).To(Equal("4"))
+		// Unset limits keep the default container limits
+		Expect(container.DefaultRequest.Cpu().String()).To(Equal("250m"))
+
+		pvc := spec.Limits[1]
+		Expect(pvc.Type).To(Equal(corev1.LimitTypePersistentVolumeClaim))
+		Expect(pvc.Min.Storage().String()).To(Equal("1Gi"))
+		Expect(pvc.Max.Storage().String()).To(Equal("100Gi"))
+		Expect(pvc.Default).To(BeNil())
+		Expect(pvc.DefaultRequest).To(BeNil())
+	})
+
+	It("reports invalid quantities instead of panicking", func() {
+		_, err := limitRangeSpec(&myoperatorv1alpha1.LimitRange{Limits: []myoperatorv1alpha1.LimitRangeLimit{
+			{Type: "Container", Max: &myoperatorv1alpha1.Resources{CPU: "1.2.3"}},
+		}}, nil)
+		Expect(err).To(MatchError(ContainSubstring(`Container limits: max: invalid quantity "1.2.3" of cpu`)))
+	})
+})
@@ -0,0 +1,182 @@
+package usecase
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+)
+
+// Labels and annotations put on every object managed for a UserConfig
+const (
+	LabelManagedBy      = "app.kubernetes.io/managed-by"
+	LabelUserConfigName = "userconfig.myoperator.01cloud.io/name"
+	LabelTeamName       = "team.myoperator.01cloud.io/name"
+	LabelAccessGrant    = "userconfig.myoperator.01cloud.io/access-grant"
+	LabelElevation      = "userconfig.myoperator.01cloud.io/elevation"
+	ManagedByValue      = "userconfig-operator"
+
+	AnnotationSpecHash        = "userconfig.myoperator.01cloud.io/spec-hash"
+	AnnotationOperatorVersion = "userconfig.myoperator.01cloud.io/operator-version"
+	AnnotationTokenIssued     = "userconfig.myoperator.01cloud.io/token-issued"
+	AnnotationTokenExpiry     = "userconfig.myoperator.01cloud.io/token-expiry"
+)
+
+// OperatorVersion is recorded on every managed object. It is set at build time with
+// -ldflags "-X 01cloud/zoperator/internal/usecase.OperatorVersion=<version>"
+var OperatorVersion = "dev"
+
+// managedLabels returns the labels identifying objects managed for uc
+func managedLabels(uc *myoperatorv1alpha1.UserConfig) map[string]string {
+	return map[string]string{
+		LabelManagedBy:      ManagedByValue,
+		LabelUserConfigName: uc.Name,
+	}
+}
+
+// managedAnnotations returns the annotations recording which spec and operator produced an object
+func managedAnnotations(uc *myoperatorv1alpha1.UserConfig) map[string]string {
+	return map[string]string{
+		AnnotationSpecHash:        specHash(uc),
+		AnnotationOperatorVersion: OperatorVersion,
+	}
+}
+
+// reservedMetadataPrefixes are the label and annotation key prefixes users
+// can't set through spec.namespaceMetadata or identity labels
+var reservedMetadataPrefixes = []string{
+	"app.kubernetes.io/managed-by",
+	"userconfig.myoperator.01cloud.io/",
+	"team.myoperator.01cloud.io/",
+	"pod-security.kubernetes.io/",
+	"kubernetes.io/",
+	"k8s.io/",
+}
+
+// isReservedMetadataKey reports whether key is owned by the operator or Kubernetes
+func isReservedMetadataKey(key string) bool {
+	for _, prefix := range reservedMetadataPrefixes {
+		if key == prefix || strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// customMetadata returns the labels and annotations requested by uc through
+// identity labels and spec.namespaceMetadata, the latter winning on conflicts.
+// Reserved or invalid keys are left out and returned as ignored.
+func customMetadata(uc *myoperatorv1alpha1.UserConfig) (labels, annotations map[string]string, ignored []string) {
+	labels = map[string]string{}
+	annotations = map[string]string{}
+
+	requested := map[string]string{}
+	for _, pair := range uc.Spec.Identity.Labels {
+		key, value, _ := strings.Cut(pair, "=")
+		requested[key] = value
+	}
+	if md := uc.Spec.NamespaceMetadata; md != nil {
+		for key, value := range md.Labels {
+			requested[key] = value
+		}
+		for key, value := range md.Annotations {
+			if isReservedMetadataKey(key) || len(validation.IsQualifiedName(key)) > 0 {
+				ignored = append(ignored, key)
+				continue
+			}
+			annotations[key] = value
+		}
+	}
+	for key, value := range requested {
+		if isReservedMetadataKey(key) || len(validation.IsQualifiedName(key)) > 0 || len(validation.IsValidLabelValue(value)) > 0 {
+			ignored = append(ignored, key)
+			continue
+		}
+		labels[key] = value
+	}
+	sort.Strings(ignored)
+
+	return labels, annotations, ignored
+}
+
+// objectMeta builds the metadata for a new object managed for uc
+func objectMeta(uc *myoperatorv1alpha1.UserConfig, name, namespace string) metav1.ObjectMeta {
+	labels, annotations, _ := customMetadata(uc)
+	return metav1.ObjectMeta{
+		Name:        name,
+		Namespace:   namespace,
+		Labels:      mergeStringMaps(labels, managedLabels(uc)),
+		Annotations: mergeStringMaps(annotations, managedAnnotations(uc)),
+	}
+}
+
+// setManagedMetadata merges the custom and managed labels and annotations into
+// obj and makes uc its controller. It is used on both freshly built and
+// existing objects so drifted metadata is restored on update.
+func (u *UserConfigUseCase) setManagedMetadata(uc *myoperatorv1alpha1.UserConfig, obj client.Object) error {
+	labels, annotations, _ := customMetadata(uc)
+	obj.SetLabels(mergeStringMaps(mergeStringMaps(obj.GetLabels(), labels), managedLabels(uc)))
+	obj.SetAnnotations(mergeStringMaps(mergeStringMaps(obj.GetAnnotations(), annotations), managedAnnotations(uc)))
+	if err := controllerutil.SetControllerReference(uc, obj, u.Scheme); err != nil {
+		return fmt.Errorf("failed to set controller reference on %s: %w", obj.GetName(), err)
+	}
+	return nil
+}
+
+// specHash returns a short stable hash of the UserConfig spec
+func specHash(uc *myoperatorv1alpha1.UserConfig) string {
+	return hashOf(uc.Spec)
+}
+
+// hashOf returns a short stable hash of the JSON encoding of v
+func hashOf(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// mergeStringMaps returns base with every entry of overrides applied on top
+func mergeStringMaps(base, overrides map[string]string) map[string]string {
+	merged := make(map[string]string, len(base)+len(overrides))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	return merged
+}
+
+// isManagedFor reports whether obj carries the managed labels of uc and has uc
+// as its controller, i.e. it was created by the operator for uc
+func isManagedFor(uc *myoperatorv1alpha1.UserConfig, obj client.Object) bool {
+	labels := obj.GetLabels()
+	return labels[LabelManagedBy] == ManagedByValue && labels[LabelUserConfigName] == uc.Name && metav1.IsControlledBy(obj, uc)
+}
+
+// updateManagedMetadata applies the managed metadata to an existing object
+// and reports whether anything changed and the object needs an update
+func (u *UserConfigUseCase) updateManagedMetadata(uc *myoperatorv1alpha1.UserConfig, existing client.Object) (bool, error) {
+	labels, annotations, owners := existing.GetLabels(), existing.GetAnnotations(), existing.GetOwnerReferences()
+	if err := u.setManagedMetadata(uc, existing); err != nil {
+		return false, err
+	}
+	changed := !reflect.DeepEqual(labels, existing.GetLabels()) ||
+		!reflect.DeepEqual(annotations, existing.GetAnnotations()) ||
+		!reflect.DeepEqual(owners, existing.GetOwnerReferences())
+	return changed, nil
+}
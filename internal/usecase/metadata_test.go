@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+)
+
+var _ = Describe("custom metadata", func() {
+	DescribeTable("isReservedMetadataKey",
+		func(key string, reserved bool) {
+			Expect(isReservedMetadataKey(key)).To(Equal(reserved))
+		},
+		Entry("operator label", LabelUserConfigName, true),
+		Entry("managed-by label", LabelManagedBy, true),
+		Entry("team label", LabelTeamName, true),
+		Entry("Pod Security label", LabelPodSecurityEnforce, true),
+		Entry("Kubernetes label", corev1.LabelMetadataName, true),
+		Entry("k8s.io label", "k8s.io/owner", true),
+		Entry("other app.kubernetes.io label", "app.kubernetes.io/part-of", false),
+		Entry("custom label", "cost-center", false),
+		Entry("custom prefixed label", "example.com/team", false),
+	)
+
+	DescribeTable("customMetadata",
+		func(identity []string, md *myoperatorv1alpha1.NamespaceMetadata, labels, annotations map[string]string, ignored []string) {
+			uc := &myoperatorv1alpha1.UserConfig{ObjectMeta: metav1.ObjectMeta{Name: "alice"}}
+			uc.Spec.Identity.Labels = identity
+			uc.Spec.NamespaceMetadata = md
+
+			gotLabels, gotAnnotations, gotIgnored := customMetadata(uc)
+			Expect(gotLabels).To(Equal(labels))
+			Expect(gotAnnotations).To(Equal(annotations))
+			Expect(gotIgnored).To(Equal(ignored))
+		},
+		Entry("nothing requested", nil, nil, map[string]string{}, map[string]string{}, nil),
+		Entry("identity labels as key=value pairs", []string{"team=payments", "oncall"}, nil,
+			map[string]string{"team": "payments", "oncall": ""}, map[string]string{}, nil),
+		Entry("namespace metadata wins over identity labels", []string{"team=payments"},
+			&myoperatorv1alpha1.NamespaceMetadata{
+				Labels:      map[string]string{"team": "billing"},
+				Annotations: map[string]string{"example.com/owner": "alice"},
+			},
+			map[string]string{"team": "billing"}, map[string]string{"example.com/owner": "alice"}, nil),
+		Entry("reserved and invalid keys ignored", []string{"pod-security.kubernetes.io/enforce=privileged"},
+			&myoperatorv1alpha1.NamespaceMetadata{
+				Labels:      map[string]string{LabelManagedBy: "me", "bad key": "x", "cost-center": "invalid value!"},
+				Annotations: map[string]string{AnnotationSpecHash: "0", "kubernetes.io/description": "x", "note": "kept"},
+			},
+			map[string]string{}, map[string]string{"note": "kept"},
+			[]string{LabelManagedBy, "bad key", "cost-center", "kubernetes.io/description", "pod-security.kubernetes.io/enforce", AnnotationSpecHash}),
+	)
+
+	It("never lets custom metadata override the managed one", func() {
+		u := &UserConfigUseCase{Scheme: testScheme}
+		uc := &myoperatorv1alpha1.UserConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "alice", UID: "alice-uid"},
+			Spec: myoperatorv1alpha1.UserConfigSpec{
+				NamespaceMetadata: &myoperatorv1alpha1.NamespaceMetadata{Labels: map[string]string{"team": "payments"}},
+			},
+		}
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "alice", Labels: map[string]string{
+			LabelUserConfigName: "mallory",
+			"team":              "billing",
+			"unrelated":         "kept",
+		}}}
+
+		changed, err := u.updateManagedMetadata(uc, namespace)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(namespace.Labels).To(Equal(map[string]string{
+			LabelManagedBy:      ManagedByValue,
+			LabelUserConfigName: "alice",
+			"team":              "payments",
+			"unrelated":         "kept",
+		}))
+		Expect(namespace.Annotations).To(HaveKeyWithValue(AnnotationSpecHash, specHash(uc)))
+		Expect(isManagedFor(uc, namespace)).To(BeTrue())
+
+		changed, err = u.updateManagedMetadata(uc, namespace)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeFalse())
+	})
+})
@@ -3,42 +3,227 @@ package usecase
 import (
 	"context"
 	"fmt"
-
-	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+	"reflect"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+	"01cloud/zoperator/internal/metrics"
 )
 
+// tenantNamespace is a namespace provisioned for a UserConfig together with
+// the settings applied to it
+type tenantNamespace struct {
+	Name           string
+	ResourceQuotas *myoperatorv1alpha1.ResourceQuota
+	LimitRange     *myoperatorv1alpha1.LimitRange
+	NetworkPolicy  []myoperatorv1alpha1.NetworkPolicy
+}
+
+// tenantNamespaces returns the primary namespace named after the UserConfig
+// followed by spec.namespaces. Unset overrides fall back to the UserConfig
+// settings and duplicate names are skipped.
+func tenantNamespaces(uc *myoperatorv1alpha1.UserConfig) []tenantNamespace {
+	namespaces := []tenantNamespace{{
+		Name:           uc.Name,
+		ResourceQuotas: uc.Spec.ResourceQuotas,
+		LimitRange:     uc.Spec.LimitRange,
+		NetworkPolicy:  uc.Spec.NetworkPolicy,
+	}}
+	seen := map[string]bool{uc.Name: true}
+
+	for _, extra := range uc.Spec.Namespaces {
+		ns := tenantNamespace{
+			Name:           extra.Name,
+			ResourceQuotas: uc.Spec.ResourceQuotas,
+			LimitRange:     uc.Spec.LimitRange,
+			NetworkPolicy:  uc.Spec.NetworkPolicy,
+		}
+		if extra.Suffix != "" {
+			ns.Name = fmt.Sprintf("%s-%s", uc.Name, extra.Suffix)
+		}
+		if ns.Name == "" || seen[ns.Name] {
+			continue
+		}
+		seen[ns.Name] = true
+
+		if extra.ResourceQuotas != nil {
+			ns.ResourceQuotas = extra.ResourceQuotas
+		}
+		if extra.LimitRange != nil {
+			ns.LimitRange = extra.LimitRange
+		}
+		if extra.NetworkPolicy != nil {
+			ns.NetworkPolicy = extra.NetworkPolicy
+		}
+		namespaces = append(namespaces, ns)
+	}
+
+	return namespaces
+}
+
+// NamespaceNames returns the names of every namespace provisioned for uc
+func NamespaceNames(uc *myoperatorv1alpha1.UserConfig) []string {
+	var names []string
+	for _, ns := range tenantNamespaces(uc) {
+		names = append(names, ns.Name)
+	}
+	return names
+}
+
+// NamespaceLabels returns the labels of a namespace managed for uc under the
+// OperatorConfig defaults, Pod Security labels aside
+func NamespaceLabels(uc *myoperatorv1alpha1.UserConfig, defaults myoperatorv1alpha1.OperatorConfigSpec) map[string]string {
+	return namespaceLabels(uc, defaults.NamespaceLabels)
+}
+
+// namespaceLabels returns the OperatorConfig default labels overridden by the
+// custom and managed labels of uc
+func namespaceLabels(uc *myoperatorv1alpha1.UserConfig, defaultLabels map[string]string) map[string]string {
+	return mergeStringMaps(defaultLabels, objectMeta(uc, "", "").Labels)
+}
+
 func (u *UserConfigUseCase) ReconcileNamespace(ctx context.Context, uc *myoperatorv1alpha1.UserConfig) error {
+	if protected := u.ProtectedNamespaces(uc); len(protected) > 0 {
+		return fmt.Errorf("namespaces %s are protected by OperatorConfig %s", strings.Join(protected, ", "), myoperatorv1alpha1.OperatorConfigName)
+	}
+
+	podSecurityLabels := u.podSecurityLabels(uc.Spec.PodSecurity)
+	defaultLabels := u.Defaults.Spec().NamespaceLabels
+	var violations []string
+	tightened := false
+	for _, ns := range tenantNamespaces(uc) {
+		warnings, checked, err := u.reconcileNamespace(ctx, uc, ns.Name, defaultLabels, podSecurityLabels)
+		if err != nil {
+			return err
+		}
+		tightened = tightened || checked
+		violations = append(violations, warnings...)
+	}
+	uc.Status.PodSecurityViolations = violations
+	if tightened && len(violations) > 0 {
+		u.Recorder.Eventf(uc, corev1.EventTypeWarning, EventReasonPodSecurityViolations,
+			"Existing pods violate Pod Security level %s: %s", podSecurityLabels[LabelPodSecurityEnforce], strings.Join(violations, "; "))
+	}
+
+	if _, _, ignored := customMetadata(uc); len(ignored) > 0 {
+		u.Recorder.Eventf(uc, corev1.EventTypeWarning, EventReasonMetadataIgnored,
+			"Ignored reserved or invalid metadata keys: %s", strings.Join(ignored, ", "))
+	}
+
+	// Remove namespaces dropped from spec.namespaces
+	if err := u.pruneNamespaces(ctx, uc); err != nil {
+		return err
+	}
+	uc.Status.Namespaces = NamespaceNames(uc)
+
+	// Attach the ResourceQuota
+	if err := u.ReconcileResourceQuota(ctx, uc); err != nil {
+		return fmt.Errorf("failed to reconcile ResourceQuota: %w", err)
+	}
+
+	return nil
+}
+
+// reconcileNamespace creates or updates the namespace name with the
+// OperatorConfig default labels, overridden by the UserConfig ones, and the Pod
+// Security labels. When the enforced level is tightened on an existing
+// namespace, the pods violating it are returned along with checked set.
+func (u *UserConfigUseCase) reconcileNamespace(ctx context.Context, uc *myoperatorv1alpha1.UserConfig, name string, defaultLabels, podSecurityLabels map[string]string) (violations []string, checked bool, err error) {
 	namespace := &corev1.Namespace{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: uc.Name,
-			Labels: map[string]string{
-				"app.kubernetes.io/managed-by":          "userconfig-operator",
-				"userconfig.myoperator.01cloud.io/name": uc.Name,
-			},
-		},
+		ObjectMeta: objectMeta(uc, name, ""),
 	}
+	namespace.Labels = mergeStringMaps(namespaceLabels(uc, defaultLabels), podSecurityLabels)
 
 	// Set ownership reference
-	if err := controllerutil.SetControllerReference(uc, namespace, u.Scheme); err != nil {
-		return fmt.Errorf("failed to set controller reference: %w", err)
+	if err := u.setManagedMetadata(uc, namespace); err != nil {
+		return nil, false, err
 	}
 
 	// Create namespace if it doesn't exist
-	if err := u.Create(ctx, namespace); err != nil && !apierrors.IsAlreadyExists(err) {
-		return fmt.Errorf("failed to create namespace: %w", err)
+	if err := u.Create(ctx, namespace); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return nil, false, fmt.Errorf("failed to create namespace %s: %w", name, err)
+		}
+
+		existing := &corev1.Namespace{}
+		if err := u.Get(ctx, client.ObjectKey{Name: name}, existing); err != nil {
+			return nil, false, fmt.Errorf("failed to get existing namespace %s: %w", name, err)
+		}
+		// Never take over a namespace the operator didn't create for uc
+		if !isManagedFor(uc, existing) {
+			return nil, false, fmt.Errorf("namespace %s already exists and isn't managed for UserConfig %s", name, uc.Name)
+		}
+
+		// Report the existing pods a stricter enforce level would reject before applying it
+		if tightensPodSecurity(existing, podSecurityLabels) {
+			checked = true
+			violations, err = u.dryRunPodSecurity(ctx, existing, podSecurityLabels)
+			if err != nil {
+				log.FromContext(ctx).Error(err, "Pod Security dry-run failed", "namespace", name)
+			}
+		}
+
+		// Restore drifted labels, annotations and owner reference
+		labels := mergeStringMaps(mergeStringMaps(existing.Labels, defaultLabels), podSecurityLabels)
+		labelsChanged := !reflect.DeepEqual(existing.Labels, labels)
+		existing.Labels = labels
+		changed, err := u.updateManagedMetadata(uc, existing)
+		if err != nil {
+			return nil, false, err
+		}
+		if changed || labelsChanged {
+			if err := u.Update(ctx, existing); err != nil {
+				return nil, false, fmt.Errorf("failed to update namespace %s: %w", name, err)
+			}
+		}
+	} else {
+		u.Recorder.Eventf(uc, corev1.EventTypeNormal, EventReasonNamespaceCreated, "Created namespace %s", namespace.Name)
 	}
 
-	// Attach the ResourceQuota
-	if err := u.ReconcileResourceQuota(ctx, uc); err != nil {
-		return fmt.Errorf("failed to reconcile ResourceQuota for namespace %s: %w", uc.Name, err)
+	return violations, checked, nil
+}
+
+// pruneNamespaces deletes the namespaces created for uc that are no longer listed in its spec
+func (u *UserConfigUseCase) pruneNamespaces(ctx context.Context, uc *myoperatorv1alpha1.UserConfig) error {
+	namespaces, err := u.listManagedNamespaces(ctx, uc)
+	if err != nil {
+		return err
+	}
+
+	desired := map[string]bool{}
+	for _, name := range NamespaceNames(uc) {
+		desired[name] = true
+	}
+	defaults := u.Defaults.Spec()
+	for i := range namespaces.Items {
+		namespace := &namespaces.Items[i]
+		if desired[namespace.Name] || isProtectedNamespace(defaults, namespace.Name) || !isManagedFor(uc, namespace) {
+			continue
+		}
+		metrics.DeleteNamespaceQuota(uc.Name, namespace.Name)
+		if !namespace.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if err := u.Delete(ctx, namespace); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete namespace %s: %w", namespace.Name, err)
+		}
+		u.Recorder.Eventf(uc, corev1.EventTypeNormal, EventReasonNamespaceDeleted, "Deleted namespace %s", namespace.Name)
 	}
 
 	return nil
 }
+
+// listManagedNamespaces lists the namespaces labelled as managed for uc. The
+// labels alone don't prove the operator created them, see isManagedFor.
+func (u *UserConfigUseCase) listManagedNamespaces(ctx context.Context, uc *myoperatorv1alpha1.UserConfig) (*corev1.NamespaceList, error) {
+	namespaces := &corev1.NamespaceList{}
+	if err := u.List(ctx, namespaces, client.MatchingLabels(managedLabels(uc))); err != nil {
+		return nil, fmt.Errorf("failed to list namespaces of UserConfig %s: %w", uc.Name, err)
+	}
+	return namespaces, nil
+}
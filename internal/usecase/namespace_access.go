@@ -0,0 +1,233 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+)
+
+// accessGrantName returns the name of the Role and RoleBinding granting uc
+// access to the namespace of another UserConfig
+func accessGrantName(uc *myoperatorv1alpha1.UserConfig) string {
+	return fmt.Sprintf("userconfig:%s", uc.Name)
+}
+
+// ReconcileNamespaceAccess creates a Role and RoleBinding in the namespaces of
+// every UserConfig targeted by spec.permissions.namespaces that lists uc in its
+// spec.allowAccessFrom. Grants no longer requested or allowed are removed, both
+// the ones held by uc and the ones other UserConfigs hold on the namespaces of uc.
+func (u *UserConfigUseCase) ReconcileNamespaceAccess(ctx context.Context, uc *myoperatorv1alpha1.UserConfig) error {
+	// Entries resolving to the same namespace merge their rules into one grant
+	grants := map[string][]rbacv1.PolicyRule{}
+	filtered := map[string]bool{}
+	policy := u.settings().RBACPolicy
+	var denied []string
+
+	for _, perm := range uc.Spec.Permissions.Namespaces {
+		targets, err := u.accessTargets(ctx, uc, perm)
+		if err != nil {
+			return err
+		}
+		if len(targets) == 0 && perm.UserConfig != "" {
+			denied = append(denied, fmt.Sprintf("%s: UserConfig not found", perm.UserConfig))
+			continue
+		}
+
+		rules, removed, err := policy.Apply(uc.Spec.Identity.Groups, policyRules(perm.Resources))
+		if err != nil {
+			return err
+		}
+		for _, permission := range removed {
+			filtered[permission] = true
+		}
+
+		for i := range targets {
+			target := &targets[i]
+			if !allowsAccessFrom(target, uc.Name) {
+				denied = append(denied, fmt.Sprintf("%s: access not allowed by target", target.Name))
+				continue
+			}
+			for _, namespace := range NamespaceNames(target) {
+				grants[namespace] = append(grants[namespace], rules...)
+			}
+		}
+	}
+
+	granted := map[string]bool{}
+	var namespaces []string
+	for namespace := range grants {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	for _, namespace := range namespaces {
+		if err := u.applyAccessGrant(ctx, uc, namespace, grants[namespace]); err != nil {
+			return fmt.Errorf("failed to grant access to namespace %s: %w", namespace, err)
+		}
+		granted[namespace] = true
+	}
+
+	if err := u.pruneAccessGrants(ctx, uc, granted); err != nil {
+		return err
+	}
+	if err := u.revokeAccessGrants(ctx, uc); err != nil {
+		return err
+	}
+
+	uc.Status.AccessGrants = namespaces
+	sort.Strings(denied)
+	uc.Status.DeniedAccess = denied
+
+	var filteredPermissions []string
+	for permission := range filtered {
+		filteredPermissions = append(filteredPermissions, permission)
+	}
+	sort.Strings(filteredPermissions)
+	if len(filteredPermissions) > 0 && !reflect.DeepEqual(filteredPermissions, uc.Status.FilteredAccessPermissions) {
+		u.Recorder.Eventf(uc, corev1.EventTypeWarning, EventReasonPermissionsFiltered,
+			"Namespace access permissions filtered by RBAC policy: %s", strings.Join(filteredPermissions, "; "))
+	}
+	uc.Status.FilteredAccessPermissions = filteredPermissions
+
+	return nil
+}
+
+// accessTargets resolves the UserConfigs targeted by perm, never including uc itself
+func (u *UserConfigUseCase) accessTargets(ctx context.Context, uc *myoperatorv1alpha1.UserConfig, perm myoperatorv1alpha1.NamespacePermission) ([]myoperatorv1alpha1.UserConfig, error) {
+	var targets []myoperatorv1alpha1.UserConfig
+	if perm.UserConfig != "" {
+		target := &myoperatorv1alpha1.UserConfig{}
+		if err := u.Get(ctx, client.ObjectKey{Name: perm.UserConfig}, target); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to get UserConfig %s: %w", perm.UserConfig, err)
+		}
+		targets = append(targets, *target)
+	} else {
+		selector, err := metav1.LabelSelectorAsSelector(perm.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespace permission selector: %w", err)
+		}
+		list := &myoperatorv1alpha1.UserConfigList{}
+		if err := u.List(ctx, list, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return nil, fmt.Errorf("failed to list UserConfigs: %w", err)
+		}
+		targets = list.Items
+	}
+
+	filtered := targets[:0]
+	for _, target := range targets {
+		if target.Name != uc.Name && target.DeletionTimestamp.IsZero() {
+			filtered = append(filtered, target)
+		}
+	}
+	return filtered, nil
+}
+
+// allowsAccessFrom reports whether target opted in to access from the UserConfig name
+func allowsAccessFrom(target *myoperatorv1alpha1.UserConfig, name string) bool {
+	for _, allowed := range target.Spec.AllowAccessFrom {
+		if allowed == name {
+			return true
+		}
+	}
+	return false
+}
+
+// applyAccessGrant creates or updates the Role and RoleBinding granting uc rules in namespace
+func (u *UserConfigUseCase) applyAccessGrant(ctx context.Context, uc *myoperatorv1alpha1.UserConfig, namespace string, rules []rbacv1.PolicyRule) error {
+	name := accessGrantName(uc)
+	setMetadata := func(obj client.Object) error {
+		obj.SetLabels(mergeStringMaps(obj.GetLabels(), map[string]string{LabelAccessGrant: uc.Name}))
+		return u.setManagedMetadata(uc, obj)
+	}
+
+	role := &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	result, err := controllerutil.CreateOrUpdate(ctx, u.Client, role, func() error {
+		role.Rules = rules
+		return setMetadata(role)
+	})
+	if err != nil {
+		return err
+	}
+	if result == controllerutil.OperationResultCreated {
+		u.Recorder.Eventf(uc, corev1.EventTypeNormal, EventReasonRoleCreated, "Created Role %s/%s", namespace, name)
+	}
+
+	roleBinding := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	_, err = controllerutil.CreateOrUpdate(ctx, u.Client, roleBinding, func() error {
+		roleBinding.Subjects = userSubjects(uc)
+		roleBinding.RoleRef = rbacv1.RoleRef{
+			Kind:     "Role",
+			Name:     name,
+			APIGroup: "rbac.authorization.k8s.io",
+		}
+		return setMetadata(roleBinding)
+	})
+	return err
+}
+
+// pruneAccessGrants deletes the grants held by uc outside the granted namespaces
+func (u *UserConfigUseCase) pruneAccessGrants(ctx context.Context, uc *myoperatorv1alpha1.UserConfig, granted map[string]bool) error {
+	return u.deleteRoleGrants(ctx, []client.ListOption{client.MatchingLabels{LabelAccessGrant: uc.Name}}, func(obj client.Object) bool {
+		return !granted[obj.GetNamespace()]
+	})
+}
+
+// revokeAccessGrants deletes the grants on the namespaces of uc held by
+// UserConfigs that are no longer listed in spec.allowAccessFrom
+func (u *UserConfigUseCase) revokeAccessGrants(ctx context.Context, uc *myoperatorv1alpha1.UserConfig) error {
+	for _, namespace := range NamespaceNames(uc) {
+		err := u.deleteRoleGrants(ctx, []client.ListOption{client.InNamespace(namespace), client.HasLabels{LabelAccessGrant}}, func(obj client.Object) bool {
+			return !allowsAccessFrom(uc, obj.GetLabels()[LabelAccessGrant])
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteRoleGrants deletes the RoleBindings and Roles matching opts for which stale returns true
+func (u *UserConfigUseCase) deleteRoleGrants(ctx context.Context, opts []client.ListOption, stale func(client.Object) bool) error {
+	roleBindings := &rbacv1.RoleBindingList{}
+	if err := u.List(ctx, roleBindings, opts...); err != nil {
+		return fmt.Errorf("failed to list rolebindings: %w", err)
+	}
+	for i := range roleBindings.Items {
+		if !stale(&roleBindings.Items[i]) {
+			continue
+		}
+		if err := u.Delete(ctx, &roleBindings.Items[i]); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete rolebinding %s/%s: %w", roleBindings.Items[i].Namespace, roleBindings.Items[i].Name, err)
+		}
+	}
+
+	roles := &rbacv1.RoleList{}
+	if err := u.List(ctx, roles, opts...); err != nil {
+		return fmt.Errorf("failed to list roles: %w", err)
+	}
+	for i := range roles.Items {
+		if !stale(&roles.Items[i]) {
+			continue
+		}
+		if err := u.Delete(ctx, &roles.Items[i]); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete role %s/%s: %w", roles.Items[i].Namespace, roles.Items[i].Name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+)
+
+var _ = Describe("namespace access", func() {
+	var (
+		ctx      context.Context
+		u        *UserConfigUseCase
+		recorder *record.FakeRecorder
+		alice    *myoperatorv1alpha1.UserConfig
+		bob      *myoperatorv1alpha1.UserConfig
+	)
+
+	// grantRules returns the rules of the Role granting alice access to namespace
+	grantRules := func(namespace string) []rbacv1.PolicyRule {
+		role := &rbacv1.Role{}
+		Expect(u.Get(ctx, client.ObjectKey{Name: "userconfig:alice", Namespace: namespace}, role)).To(Succeed())
+		return role.Rules
+	}
+
+	// events drains the recorded events
+	events := func() []string {
+		var recorded []string
+		for len(recorder.Events) > 0 {
+			recorded = append(recorded, <-recorder.Events)
+		}
+		return recorded
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		alice = &myoperatorv1alpha1.UserConfig{ObjectMeta: metav1.ObjectMeta{Name: "alice", UID: "alice-uid"}}
+		bob = &myoperatorv1alpha1.UserConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "bob", UID: "bob-uid", Labels: map[string]string{"team": "payments"}},
+			Spec: myoperatorv1alpha1.UserConfigSpec{
+				Namespaces:      []myoperatorv1alpha1.UserNamespace{{Suffix: "dev"}},
+				AllowAccessFrom: []string{"alice"},
+			},
+		}
+		u, recorder = newTestUseCase(alice, bob)
+	})
+
+	It("only grants access to the UserConfigs that opted in", func() {
+		carol := &myoperatorv1alpha1.UserConfig{ObjectMeta: metav1.ObjectMeta{Name: "carol", Labels: map[string]string{"team": "payments"}}}
+		Expect(u.Create(ctx, carol)).To(Succeed())
+		alice.Spec.Permissions.Namespaces = []myoperatorv1alpha1.NamespacePermission{
+			{UserConfig: "dave", Resources: []myoperatorv1alpha1.ResourcePermission{{Resource: "pod", Operation: "R"}}},
+			{
+				Selector:  &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}},
+				Resources: []myoperatorv1alpha1.ResourcePermission{{Resource: "pod", Operation: "R"}},
+			},
+		}
+		Expect(u.ReconcileNamespaceAccess(ctx, alice)).To(Succeed())
+		Expect(alice.Status.AccessGrants).To(Equal([]string{"bob", "bob-dev"}))
+		Expect(alice.Status.DeniedAccess).To(Equal([]string{"carol: access not allowed by target", "dave: UserConfig not found"}))
+		Expect(u.Get(ctx, client.ObjectKey{Name: "userconfig:alice", Namespace: "carol"}, &rbacv1.Role{})).NotTo(Succeed())
+
+		binding := &rbacv1.RoleBinding{}
+		Expect(u.Get(ctx, client.ObjectKey{Name: "userconfig:alice", Namespace: "bob"}, binding)).To(Succeed())
+		Expect(binding.Subjects).To(Equal(userSubjects(alice)))
+	})
+
+	It("removes the grants revoked by the target or no longer requested", func() {
+		alice.Spec.Permissions.Namespaces = []myoperatorv1alpha1.NamespacePermission{
+			{UserConfig: "bob", Resources: []myoperatorv1alpha1.ResourcePermission{{Resource: "pod", Operation: "R"}}},
+		}
+		Expect(u.ReconcileNamespaceAccess(ctx, alice)).To(Succeed())
+		Expect(alice.Status.AccessGrants).To(HaveLen(2))
+
+		// bob revokes the access from the target side
+		bob.Spec.AllowAccessFrom = nil
+		Expect(u.ReconcileNamespaceAccess(ctx, bob)).To(Succeed())
+		for _, namespace := range []string{"bob", "bob-dev"} {
+			Expect(apierrors.IsNotFound(u.Get(ctx, client.ObjectKey{Name: "userconfig:alice", Namespace: namespace}, &rbacv1.Role{}))).To(BeTrue())
+			Expect(apierrors.IsNotFound(u.Get(ctx, client.ObjectKey{Name: "userconfig:alice", Namespace: namespace}, &rbacv1.RoleBinding{}))).To(BeTrue())
+		}
+
+		// alice drops the request once bob opted in again
+		bob.Spec.AllowAccessFrom = []string{"alice"}
+		Expect(u.Update(ctx, bob)).To(Succeed())
+		Expect(u.ReconcileNamespaceAccess(ctx, alice)).To(Succeed())
+		Expect(u.Get(ctx, client.ObjectKey{Name: "userconfig:alice", Namespace: "bob"}, &rbacv1.Role{})).To(Succeed())
+		alice.Spec.Permissions.Namespaces = nil
+		Expect(u.ReconcileNamespaceAccess(ctx, alice)).To(Succeed())
+		Expect(alice.Status.AccessGrants).To(BeEmpty())
+		Expect(apierrors.IsNotFound(u.Get(ctx, client.ObjectKey{Name: "userconfig:alice", Namespace: "bob"}, &rbacv1.Role{}))).To(BeTrue())
+	})
+
+	It("merges the rules of the entries resolving to the same namespace", func() {
+		alice.Spec.Permissions.Namespaces = []myoperatorv1alpha1.NamespacePermission{
+			{UserConfig: "bob", Resources: []myoperatorv1alpha1.ResourcePermission{{Resource: "deployment", Operation: "R"}}},
+			{
+				Selector:  &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}},
+				Resources: []myoperatorv1alpha1.ResourcePermission{{Resource: "configmap", Operation: "R"}},
+			},
+		}
+		Expect(u.ReconcileNamespaceAccess(ctx, alice)).To(Succeed())
+		Expect(alice.Status.AccessGrants).To(Equal([]string{"bob", "bob-dev"}))
+
+		for _, namespace := range alice.Status.AccessGrants {
+			var resources []string
+			for _, rule := range grantRules(namespace) {
+				resources = append(resources, rule.Resources...)
+			}
+			Expect(resources).To(ConsistOf("deployments", "configmaps"), namespace)
+		}
+	})
+
+	It("reports the verbs removed by the RBAC policy once", func() {
+		alice.Spec.Permissions.Namespaces = []myoperatorv1alpha1.NamespacePermission{{
+			UserConfig: "bob",
+			Resources: []myoperatorv1alpha1.ResourcePermission{
+				{Resource: "rolebinding", Operation: "CRUD"},
+				{Resource: "deployment", Operation: "R"},
+			},
+		}}
+		Expect(u.ReconcileNamespaceAccess(ctx, alice)).To(Succeed())
+		Expect(alice.Status.FilteredAccessPermissions).To(HaveLen(1))
+		Expect(alice.Status.FilteredAccessPermissions[0]).To(HavePrefix("rolebindings: "))
+		Expect(events()).To(ContainElement(ContainSubstring("PermissionsFiltered Namespace access permissions filtered by RBAC policy: rolebindings")))
+
+		Expect(u.ReconcileNamespaceAccess(ctx, alice)).To(Succeed())
+		Expect(events()).To(BeEmpty())
+
+		for _, rule := range grantRules("bob") {
+			if rule.Resources[0] == "rolebindings" {
+				Expect(rule.Verbs).To(ConsistOf("get", "list", "watch"))
+			}
+		}
+	})
+})
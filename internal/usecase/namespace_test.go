@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+	"01cloud/zoperator/internal/metrics"
+)
+
+var _ = Describe("namespaces", func() {
+	var (
+		ctx context.Context
+		u   *UserConfigUseCase
+		uc  *myoperatorv1alpha1.UserConfig
+	)
+
+	// unowned is an existing namespace nobody created for a UserConfig
+	unowned := func(name string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"team": "observability"}}}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		uc = &myoperatorv1alpha1.UserConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "alice", UID: "alice-uid", Finalizers: []string{"myoperator.01cloud.io/finalizer"}},
+			Spec: myoperatorv1alpha1.UserConfigSpec{
+				Namespaces: []myoperatorv1alpha1.UserNamespace{{Suffix: "dev"}},
+			},
+		}
+		u, _ = newTestUseCase(uc, unowned("monitoring"))
+	})
+
+	It("prunes only the namespaces dropped from spec.namespaces, each with its own quota", func() {
+		uc.Spec.ResourceQuotas = &myoperatorv1alpha1.ResourceQuota{CPU: "2"}
+		uc.Spec.Namespaces = []myoperatorv1alpha1.UserNamespace{
+			{Suffix: "dev"},
+			{Name: "alice-staging", ResourceQuotas: &myoperatorv1alpha1.ResourceQuota{CPU: "4"}},
+		}
+		Expect(u.ReconcileNamespace(ctx, uc)).To(Succeed())
+		Expect(uc.Status.Namespaces).To(Equal([]string{"alice", "alice-dev", "alice-staging"}))
+		for namespace, cpu := range map[string]string{"alice": "2", "alice-dev": "2", "alice-staging": "4"} {
+			quota := &corev1.ResourceQuota{}
+			Expect(u.Get(ctx, client.ObjectKey{Name: "alice", Namespace: namespace}, quota)).To(Succeed())
+			Expect(quota.Spec.Hard.Cpu().String()).To(Equal(cpu), namespace)
+		}
+
+		namespace := &corev1.Namespace{}
+		Expect(u.Get(ctx, client.ObjectKey{Name: "alice-staging"}, namespace)).To(Succeed())
+		Expect(isManagedFor(uc, namespace)).To(BeTrue())
+
+		for _, namespace := range []string{"alice-dev", "alice-staging"} {
+			hard := corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")}
+			metrics.SetQuotaUsage("alice", namespace, hard, corev1.ResourceList{})
+		}
+
+		uc.Spec.Namespaces = uc.Spec.Namespaces[:1]
+		Expect(u.ReconcileNamespace(ctx, uc)).To(Succeed())
+		Expect(uc.Status.Namespaces).To(Equal([]string{"alice", "alice-dev"}))
+		Expect(apierrors.IsNotFound(u.Get(ctx, client.ObjectKey{Name: "alice-staging"}, &corev1.Namespace{}))).To(BeTrue())
+		Expect(u.Get(ctx, client.ObjectKey{Name: "alice-dev"}, &corev1.Namespace{})).To(Succeed())
+		// Only the quota series of the pruned namespace are dropped
+		Expect(metrics.QuotaHard.DeleteLabelValues("alice", "alice-staging", "cpu")).To(BeFalse())
+		Expect(metrics.QuotaHard.DeleteLabelValues("alice", "alice-dev", "cpu")).To(BeTrue())
+	})
+
+	DescribeTable("tenantNamespaces",
+		func(namespaces []myoperatorv1alpha1.UserNamespace, names []string) {
+			uc.Spec.Namespaces = namespaces
+			Expect(NamespaceNames(uc)).To(Equal(names))
+		},
+		Entry("only the primary namespace", nil, []string{"alice"}),
+		Entry("suffixed and named namespaces", []myoperatorv1alpha1.UserNamespace{{Suffix: "dev"}, {Name: "sandbox"}},
+			[]string{"alice", "alice-dev", "sandbox"}),
+		Entry("duplicates and empty names skipped", []myoperatorv1alpha1.UserNamespace{{Name: "alice"}, {Suffix: "dev"}, {Name: "alice-dev"}, {}},
+			[]string{"alice", "alice-dev"}),
+	)
+
+	It("falls back to the UserConfig settings for the unset overrides", func() {
+		limits := &myoperatorv1alpha1.LimitRange{Limits: []myoperatorv1alpha1.LimitRangeLimit{{Type: "Container"}}}
+		uc.Spec.ResourceQuotas = &myoperatorv1alpha1.ResourceQuota{CPU: "2"}
+		uc.Spec.LimitRange = limits
+		uc.Spec.Namespaces = []myoperatorv1alpha1.UserNamespace{{Suffix: "dev", ResourceQuotas: &myoperatorv1alpha1.ResourceQuota{CPU: "1"}}}
+
+		namespaces := tenantNamespaces(uc)
+		Expect(namespaces).To(HaveLen(2))
+		Expect(namespaces[1].ResourceQuotas.CPU).To(Equal("1"))
+		Expect(namespaces[1].LimitRange).To(Equal(limits))
+	})
+
+	It("refuses to adopt an existing namespace it didn't create", func() {
+		uc.Spec.Namespaces = []myoperatorv1alpha1.UserNamespace{{Name: "monitoring"}}
+		err := u.ReconcileNamespace(ctx, uc)
+		Expect(err).To(MatchError(ContainSubstring("namespace monitoring already exists and isn't managed for UserConfig alice")))
+
+		namespace := &corev1.Namespace{}
+		Expect(u.Get(ctx, client.ObjectKey{Name: "monitoring"}, namespace)).To(Succeed())
+		Expect(namespace.Labels).To(Equal(map[string]string{"team": "observability"}))
+		Expect(namespace.OwnerReferences).To(BeEmpty())
+	})
+
+	It("only deletes the namespaces it created along with the UserConfig", func() {
+		Expect(u.ReconcileNamespace(ctx, uc)).To(Succeed())
+
+		// Labels copied onto a foreign namespace don't make it deletable
+		forged := unowned("monitoring")
+		Expect(u.Get(ctx, client.ObjectKey{Name: "monitoring"}, forged)).To(Succeed())
+		forged.Labels = managedLabels(uc)
+		Expect(u.Update(ctx, forged)).To(Succeed())
+
+		Expect(u.HandleDeletion(ctx, uc)).Error().NotTo(HaveOccurred())
+		Expect(controllerutil.ContainsFinalizer(uc, "myoperator.01cloud.io/finalizer")).To(BeFalse())
+		for _, name := range []string{"alice", "alice-dev"} {
+			Expect(apierrors.IsNotFound(u.Get(ctx, client.ObjectKey{Name: name}, &corev1.Namespace{}))).To(BeTrue(), name)
+		}
+		Expect(u.Get(ctx, client.ObjectKey{Name: "monitoring"}, &corev1.Namespace{})).To(Succeed())
+	})
+
+	It("clears the Pod Security violations of an earlier tightening", func() {
+		uc.Spec.PodSecurity = &myoperatorv1alpha1.PodSecurity{Enforce: "privileged"}
+		Expect(u.ReconcileNamespace(ctx, uc)).To(Succeed())
+
+		uc.Spec.PodSecurity.Enforce = "restricted"
+		uc.Status.PodSecurityViolations = []string{"existing pod alice/web violates restricted"}
+		Expect(u.ReconcileNamespace(ctx, uc)).To(Succeed())
+		Expect(uc.Status.PodSecurityViolations).To(BeEmpty())
+
+		namespace := &corev1.Namespace{}
+		Expect(u.Get(ctx, client.ObjectKey{Name: "alice-dev"}, namespace)).To(Succeed())
+		Expect(namespace.Labels).To(HaveKeyWithValue(LabelPodSecurityEnforce, "restricted"))
+	})
+})
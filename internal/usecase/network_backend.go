@@ -0,0 +1,297 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+)
+
+// Network policy backends rendering the FQDN egress rules NetworkPolicy can't express
+const (
+	// NetworkPolicyBackendNone only creates NetworkPolicies, like the empty name
+	NetworkPolicyBackendNone   = "none"
+	NetworkPolicyBackendCilium = "cilium"
+	NetworkPolicyBackendCalico = "calico"
+)
+
+// networkPolicyBackend renders FQDN egress rules into the policy object of a CNI
+type networkPolicyBackend interface {
+	// groupVersionKind is the kind of the rendered policies
+	groupVersionKind() schema.GroupVersionKind
+
+	// render returns the spec allowing the pods of podSelector to reach the rules
+	render(podSelector metav1.LabelSelector, rules []fqdnRule) (map[string]interface{}, error)
+}
+
+// networkPolicyBackends lists the backends selectable with Config.NetworkPolicyBackend
+var networkPolicyBackends = map[string]networkPolicyBackend{
+	NetworkPolicyBackendCilium: ciliumBackend{},
+	NetworkPolicyBackendCalico: calicoBackend{},
+}
+
+// IsNetworkPolicyBackend reports whether name is a known backend. The empty
+// name and none select plain NetworkPolicy.
+func IsNetworkPolicyBackend(name string) bool {
+	_, ok := networkPolicyBackends[name]
+	return ok || name == "" || name == NetworkPolicyBackendNone
+}
+
+// fqdnRule allows egress to FQDNs on ports, every port when empty
+type fqdnRule struct {
+	FQDNs []string
+	Ports []myoperatorv1alpha1.NetworkPolicyPort
+}
+
+// fqdnPolicy groups the FQDN rules generated next to a NetworkPolicy
+type fqdnPolicy struct {
+	Name        string
+	PodSelector metav1.LabelSelector
+	Rules       []fqdnRule
+}
+
+// fqdnPolicies returns the FQDN rules of policies grouped like the generated
+// NetworkPolicies: the unnamed entries under baseline and the named entries
+// under <baseline>-<name>. Groups without FQDNs are left out.
+func fqdnPolicies(baseline string, policies []myoperatorv1alpha1.NetworkPolicy) []fqdnPolicy {
+	// Named policies use the pod selector of the first entry of the name
+	selectors := map[string]metav1.LabelSelector{}
+	for _, policy := range policies {
+		if _, ok := selectors[policy.Name]; !ok && policy.PodSelector != nil {
+			selectors[policy.Name] = *policy.PodSelector
+		}
+	}
+
+	var groups []fqdnPolicy
+	index := map[string]int{}
+	for _, policy := range policies {
+		if policy.AllowTrafficTo == nil || len(policy.AllowTrafficTo.FQDNs) == 0 {
+			continue
+		}
+		i, ok := index[policy.Name]
+		if !ok {
+			i = len(groups)
+			index[policy.Name] = i
+			group := fqdnPolicy{Name: baseline, PodSelector: selectors[policy.Name]}
+			if policy.Name != "" {
+				group.Name = fmt.Sprintf("%s-%s", baseline, policy.Name)
+			}
+			groups = append(groups, group)
+		}
+		groups[i].Rules = append(groups[i].Rules, fqdnRule{
+			FQDNs: policy.AllowTrafficTo.FQDNs,
+			Ports: policy.AllowTrafficTo.Ports,
+		})
+	}
+
+	return groups
+}
+
+// ciliumBackend renders CiliumNetworkPolicies
+type ciliumBackend struct{}
+
+func (ciliumBackend) groupVersionKind() schema.GroupVersionKind {
+	return schema.GroupVersionKind{Group: "cilium.io", Version: "v2", Kind: "CiliumNetworkPolicy"}
+}
+
+func (ciliumBackend) render(podSelector metav1.LabelSelector, rules []fqdnRule) (map[string]interface{}, error) {
+	endpointSelector, err := unstructuredSelector(podSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cilium learns the FQDN addresses by proxying the DNS queries
+	egress := []interface{}{map[string]interface{}{
+		"toEndpoints": []interface{}{map[string]interface{}{
+			"matchLabels": map[string]interface{}{
+				"k8s:io.kubernetes.pod.namespace": "kube-system",
+				"k8s:k8s-app":                     "kube-dns",
+			},
+		}},
+		"toPorts": []interface{}{map[string]interface{}{
+			"ports": []interface{}{map[string]interface{}{"port": "53", "protocol": "ANY"}},
+			"rules": map[string]interface{}{
+				"dns": []interface{}{map[string]interface{}{"matchPattern": "*"}},
+			},
+		}},
+	}}
+
+	for _, rule := range rules {
+		var toFQDNs []interface{}
+		for _, fqdn := range rule.FQDNs {
+			if strings.HasPrefix(fqdn, "*.") {
+				toFQDNs = append(toFQDNs, map[string]interface{}{"matchPattern": fqdn})
+			} else {
+				toFQDNs = append(toFQDNs, map[string]interface{}{"matchName": fqdn})
+			}
+		}
+		entry := map[string]interface{}{"toFQDNs": toFQDNs}
+
+		if len(rule.Ports) > 0 {
+			var ports []interface{}
+			for _, port := range rule.Ports {
+				p := map[string]interface{}{
+					"port":     port.Port.String(),
+					"protocol": protocolOrTCP(port.Protocol),
+				}
+				if port.EndPort != nil {
+					p["endPort"] = int64(*port.EndPort)
+				}
+				ports = append(ports, p)
+			}
+			entry["toPorts"] = []interface{}{map[string]interface{}{"ports": ports}}
+		}
+		egress = append(egress, entry)
+	}
+
+	return map[string]interface{}{
+		"endpointSelector": endpointSelector,
+		"egress":           egress,
+	}, nil
+}
+
+// calicoBackend renders Calico NetworkPolicies
+type calicoBackend struct{}
+
+func (calicoBackend) groupVersionKind() schema.GroupVersionKind {
+	return schema.GroupVersionKind{Group: "projectcalico.org", Version: "v3", Kind: "NetworkPolicy"}
+}
+
+func (calicoBackend) render(podSelector metav1.LabelSelector, rules []fqdnRule) (map[string]interface{}, error) {
+	selector, err := calicoSelector(podSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	var egress []interface{}
+	for _, rule := range rules {
+		domains := make([]interface{}, 0, len(rule.FQDNs))
+		for _, fqdn := range rule.FQDNs {
+			domains = append(domains, fqdn)
+		}
+		if len(rule.Ports) == 0 {
+			egress = append(egress, map[string]interface{}{
+				"action":      "Allow",
+				"destination": map[string]interface{}{"domains": domains},
+			})
+			continue
+		}
+
+		// Calico rules hold a single protocol
+		byProtocol := map[string][]interface{}{}
+		for _, port := range rule.Ports {
+			var p interface{} = port.Port.String()
+			switch {
+			case port.EndPort != nil:
+				p = fmt.Sprintf("%s:%d", port.Port.String(), *port.EndPort)
+			case port.Port.Type == intstr.Int:
+				p = int64(port.Port.IntValue())
+			}
+			protocol := protocolOrTCP(port.Protocol)
+			byProtocol[protocol] = append(byProtocol[protocol], p)
+		}
+		protocols := make([]string, 0, len(byProtocol))
+		for protocol := range byProtocol {
+			protocols = append(protocols, protocol)
+		}
+		sort.Strings(protocols)
+		for _, protocol := range protocols {
+			egress = append(egress, map[string]interface{}{
+				"action":   "Allow",
+				"protocol": protocol,
+				"destination": map[string]interface{}{
+					"domains": domains,
+					"ports":   byProtocol[protocol],
+				},
+			})
+		}
+	}
+
+	return map[string]interface{}{
+		"selector": selector,
+		"types":    []interface{}{"Egress"},
+		"egress":   egress,
+	}, nil
+}
+
+// protocolOrTCP defaults an empty protocol to TCP
+func protocolOrTCP(protocol string) string {
+	if protocol == "" {
+		return "TCP"
+	}
+	return protocol
+}
+
+// unstructuredSelector converts selector to its unstructured form
+func unstructuredSelector(selector metav1.LabelSelector) (map[string]interface{}, error) {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert pod selector: %w", err)
+	}
+	return obj, nil
+}
+
+// calicoSelector converts selector to the Calico selector syntax
+func calicoSelector(selector metav1.LabelSelector) (string, error) {
+	var terms []string
+	keys := make([]string, 0, len(selector.MatchLabels))
+	for key := range selector.MatchLabels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		terms = append(terms, fmt.Sprintf("%s == '%s'", key, selector.MatchLabels[key]))
+	}
+
+	for _, req := range selector.MatchExpressions {
+		values := make([]string, 0, len(req.Values))
+		for _, value := range req.Values {
+			values = append(values, fmt.Sprintf("'%s'", value))
+		}
+		switch req.Operator {
+		case metav1.LabelSelectorOpIn:
+			terms = append(terms, fmt.Sprintf("%s in { %s }", req.Key, strings.Join(values, ", ")))
+		case metav1.LabelSelectorOpNotIn:
+			terms = append(terms, fmt.Sprintf("%s not in { %s }", req.Key, strings.Join(values, ", ")))
+		case metav1.LabelSelectorOpExists:
+			terms = append(terms, fmt.Sprintf("has(%s)", req.Key))
+		case metav1.LabelSelectorOpDoesNotExist:
+			terms = append(terms, fmt.Sprintf("!has(%s)", req.Key))
+		default:
+			return "", fmt.Errorf("unsupported selector operator %s", req.Operator)
+		}
+	}
+
+	if len(terms) == 0 {
+		return "all()", nil
+	}
+	return strings.Join(terms, " && "), nil
+}
+
+// hasOnlyFQDNs reports whether peer selects nothing but FQDNs, which plain
+// NetworkPolicy would turn into a rule allowing every destination
+func hasOnlyFQDNs(peer *myoperatorv1alpha1.NetworkPolicyPeer, translated []networkingv1.NetworkPolicyPeer) bool {
+	return len(peer.FQDNs) > 0 && len(translated) == 0
+}
+
+// newFQDNPolicy builds the unstructured policy of backend for group
+func newFQDNPolicy(backend networkPolicyBackend, namespace string, group fqdnPolicy) (*unstructured.Unstructured, error) {
+	spec, err := backend.render(group.PodSelector, group.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render %s %s: %w", backend.groupVersionKind().Kind, group.Name, err)
+	}
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	obj.SetGroupVersionKind(backend.groupVersionKind())
+	obj.SetName(group.Name)
+	obj.SetNamespace(namespace)
+	return obj, nil
+}
@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+)
+
+var _ = Describe("network policy backends", func() {
+	https := []myoperatorv1alpha1.NetworkPolicyPort{{Port: intstr.FromInt32(443)}}
+	policies := []myoperatorv1alpha1.NetworkPolicy{
+		{AllowTrafficTo: &myoperatorv1alpha1.NetworkPolicyPeer{FQDNs: []string{"api.github.com"}, Ports: https}},
+		{AllowTrafficTo: &myoperatorv1alpha1.NetworkPolicyPeer{Pods: []map[string]string{{"app": "db"}}}},
+		{
+			Name:           "builder",
+			PodSelector:    &metav1.LabelSelector{MatchLabels: map[string]string{"app": "builder"}},
+			AllowTrafficTo: &myoperatorv1alpha1.NetworkPolicyPeer{FQDNs: []string{"*.docker.io"}},
+		},
+	}
+
+	It("groups the FQDN rules like the generated NetworkPolicies", func() {
+		groups := fqdnPolicies("tenant", policies)
+		Expect(groups).To(HaveLen(2))
+		Expect(groups[0].Name).To(Equal("tenant"))
+		Expect(groups[0].PodSelector).To(Equal(metav1.LabelSelector{}))
+		Expect(groups[0].Rules).To(Equal([]fqdnRule{{FQDNs: []string{"api.github.com"}, Ports: https}}))
+		Expect(groups[1].Name).To(Equal("tenant-builder"))
+		Expect(groups[1].PodSelector.MatchLabels).To(HaveKeyWithValue("app", "builder"))
+	})
+
+	It("keeps FQDN only entries out of the NetworkPolicy", func() {
+		spec := networkPolicySpec(policies, nil)
+		// The pods entry and the DNS rule, the FQDN entry would allow every destination
+		Expect(spec.Egress).To(HaveLen(2))
+		Expect(spec.Egress[0].To).NotTo(BeEmpty())
+	})
+
+	It("renders CiliumNetworkPolicies", func() {
+		spec, err := ciliumBackend{}.render(metav1.LabelSelector{}, []fqdnRule{
+			{FQDNs: []string{"api.github.com", "*.github.com"}, Ports: https},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		egress := spec["egress"].([]interface{})
+		Expect(egress).To(HaveLen(2))
+		Expect(egress[1]).To(Equal(map[string]interface{}{
+			"toFQDNs": []interface{}{
+				map[string]interface{}{"matchName": "api.github.com"},
+				map[string]interface{}{"matchPattern": "*.github.com"},
+			},
+			"toPorts": []interface{}{map[string]interface{}{
+				"ports": []interface{}{map[string]interface{}{"port": "443", "protocol": "TCP"}},
+			}},
+		}))
+	})
+
+	It("renders Calico NetworkPolicies", func() {
+		endPort := int32(8100)
+		spec, err := calicoBackend{}.render(
+			metav1.LabelSelector{MatchLabels: map[string]string{"app": "builder"}},
+			[]fqdnRule{{FQDNs: []string{"api.github.com"}, Ports: []myoperatorv1alpha1.NetworkPolicyPort{
+				{Port: intstr.FromInt32(443)},
+				{Port: intstr.FromInt32(8000), EndPort: &endPort, Protocol: "UDP"},
+			}}},
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spec["selector"]).To(Equal("app == 'builder'"))
+		Expect(spec["egress"]).To(Equal([]interface{}{
+			map[string]interface{}{
+				"action":      "Allow",
+				"protocol":    "TCP",
+				"destination": map[string]interface{}{"domains": []interface{}{"api.github.com"}, "ports": []interface{}{int64(443)}},
+			},
+			map[string]interface{}{
+				"action":      "Allow",
+				"protocol":    "UDP",
+				"destination": map[string]interface{}{"domains": []interface{}{"api.github.com"}, "ports": []interface{}{"8000:8100"}},
+			},
+		}))
+	})
+
+	It("converts label selectors to Calico selectors", func() {
+		selector, err := calicoSelector(metav1.LabelSelector{
+			MatchLabels: map[string]string{"tier": "web"},
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "app", Operator: metav1.LabelSelectorOpIn, Values: []string{"a", "b"}},
+				{Key: "legacy", Operator: metav1.LabelSelectorOpDoesNotExist},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(selector).To(Equal("tier == 'web' && app in { 'a', 'b' } && !has(legacy)"))
+
+		selector, err = calicoSelector(metav1.LabelSelector{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(selector).To(Equal("all()"))
+	})
+})
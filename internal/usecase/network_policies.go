@@ -9,6 +9,7 @@ import (
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -17,166 +18,345 @@ import (
 	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
 )
 
+// ReconcileNetworkPolicies creates in every namespace of uc the baseline
+// NetworkPolicy, denying the traffic not allowed by the unnamed entries, and
+// one NetworkPolicy per named entry. Managed policies of removed entries are deleted.
 func (u *UserConfigUseCase) ReconcileNetworkPolicies(ctx context.Context, uc *myoperatorv1alpha1.UserConfig) error {
-	netpol := &networkingv1.NetworkPolicy{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      uc.Name,
-			Namespace: uc.Name,
-			Labels: map[string]string{
-				"app.kubernetes.io/managed-by":          "userconfig-operator",
-				"userconfig.myoperator.01cloud.io/name": uc.Name,
-			},
-		},
-		Spec: networkingv1.NetworkPolicySpec{
-			PodSelector: metav1.LabelSelector{}, // Applies to all pods in namespace
-			PolicyTypes: []networkingv1.PolicyType{
-				networkingv1.PolicyTypeIngress,
-				networkingv1.PolicyTypeEgress,
-			},
-		},
+	for _, ns := range tenantNamespaces(uc) {
+		if err := u.reconcileNetworkPolicy(ctx, uc, ns); err != nil {
+			return fmt.Errorf("namespace %s: %w", ns.Name, err)
+		}
 	}
+	return nil
+}
 
-	if len(uc.Spec.NetworkPolicy) == 0 {
-		// Create a default deny policy
-		// Empty ingress and egress arrays = deny all
-		netpol.Spec.Ingress = []networkingv1.NetworkPolicyIngressRule{}
-		netpol.Spec.Egress = []networkingv1.NetworkPolicyEgressRule{}
-	} else {
-		// Combine all allowed traffic rules into a single policy
-		var ingressRules []networkingv1.NetworkPolicyIngressRule
-		var egressRules []networkingv1.NetworkPolicyEgressRule
-
-		for _, policy := range uc.Spec.NetworkPolicy {
-			// Configure ingress rules if allowTrafficFrom is specified
-			if policy.AllowTrafficFrom != nil {
-				ingressRule := networkingv1.NetworkPolicyIngressRule{}
-
-				// Configure pod selector
-				if len(policy.AllowTrafficFrom.Pods) > 0 {
-					for _, podSelector := range policy.AllowTrafficFrom.Pods {
-						ingressRule.From = append(ingressRule.From, networkingv1.NetworkPolicyPeer{
-							PodSelector: &metav1.LabelSelector{
-								MatchLabels: podSelector,
-							},
-						})
-					}
-				}
-
-				// Configure namespace selector
-				if len(policy.AllowTrafficFrom.Namespaces) > 0 {
-					for _, nsSelector := range policy.AllowTrafficFrom.Namespaces {
-						ingressRule.From = append(ingressRule.From, networkingv1.NetworkPolicyPeer{
-							NamespaceSelector: &metav1.LabelSelector{
-								MatchLabels: nsSelector,
-							},
-						})
-					}
-				}
-
-				// Configure ports if specified
-				if len(policy.AllowTrafficFrom.Ports) > 0 {
-					for _, port := range policy.AllowTrafficFrom.Ports {
-						portNumber := intstr.FromInt(port.Port)
-						protocol := corev1.Protocol(port.Protocol)
-						if protocol == "" {
-							protocol = corev1.ProtocolTCP // Default to TCP if not specified
-						}
-
-						ingressRule.Ports = append(ingressRule.Ports, networkingv1.NetworkPolicyPort{
-							Port:     &portNumber,
-							Protocol: &protocol,
-						})
-					}
-				}
-
-				ingressRules = append(ingressRules, ingressRule)
-			}
+// NetworkPolicies returns the NetworkPolicies generated in every namespace of
+// uc, without metadata other than name and namespace
+func NetworkPolicies(uc *myoperatorv1alpha1.UserConfig) []networkingv1.NetworkPolicy {
+	var policies []networkingv1.NetworkPolicy
+	for _, ns := range tenantNamespaces(uc) {
+		policies = append(policies, namespaceNetworkPolicies(uc, ns)...)
+	}
+	return policies
+}
 
-			// Configure egress rules if allowTrafficTo is specified
-			if policy.AllowTrafficTo != nil {
-				egressRule := networkingv1.NetworkPolicyEgressRule{}
-
-				// Configure pod selector
-				if len(policy.AllowTrafficTo.Pods) > 0 {
-					for _, podSelector := range policy.AllowTrafficTo.Pods {
-						egressRule.To = append(egressRule.To, networkingv1.NetworkPolicyPeer{
-							PodSelector: &metav1.LabelSelector{
-								MatchLabels: podSelector,
-							},
-						})
-					}
-				}
-
-				// Configure namespace selector
-				if len(policy.AllowTrafficTo.Namespaces) > 0 {
-					for _, nsSelector := range policy.AllowTrafficTo.Namespaces {
-						egressRule.To = append(egressRule.To, networkingv1.NetworkPolicyPeer{
-							NamespaceSelector: &metav1.LabelSelector{
-								MatchLabels: nsSelector,
-							},
-						})
-					}
-				}
-
-				// Configure ports if specified
-				if len(policy.AllowTrafficTo.Ports) > 0 {
-					for _, port := range policy.AllowTrafficTo.Ports {
-						portNumber := intstr.FromInt(port.Port)
-						protocol := corev1.Protocol(port.Protocol)
-						if protocol == "" {
-							protocol = corev1.ProtocolTCP // Default to TCP if not specified
-						}
-
-						egressRule.Ports = append(egressRule.Ports, networkingv1.NetworkPolicyPort{
-							Port:     &portNumber,
-							Protocol: &protocol,
-						})
-					}
-				}
-
-				egressRules = append(egressRules, egressRule)
-			}
+// namespaceNetworkPolicies returns the baseline NetworkPolicy of ns followed by the named ones
+func namespaceNetworkPolicies(uc *myoperatorv1alpha1.UserConfig, ns tenantNamespace) []networkingv1.NetworkPolicy {
+	policies := []networkingv1.NetworkPolicy{{
+		ObjectMeta: metav1.ObjectMeta{Name: uc.Name, Namespace: ns.Name},
+		Spec:       networkPolicySpec(ns.NetworkPolicy, uc.Spec.NetworkDefaults),
+	}}
+	for _, named := range namedNetworkPolicySpecs(ns.NetworkPolicy) {
+		policies = append(policies, networkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("%s-%s", uc.Name, named.Name), Namespace: ns.Name},
+			Spec:       named.Spec,
+		})
+	}
+	return policies
+}
+
+func (u *UserConfigUseCase) reconcileNetworkPolicy(ctx context.Context, uc *myoperatorv1alpha1.UserConfig, ns tenantNamespace) error {
+	desired := map[string]bool{}
+	for _, policy := range namespaceNetworkPolicies(uc, ns) {
+		desired[policy.Name] = true
+		if err := u.applyNetworkPolicy(ctx, uc, ns.Name, policy.Name, policy.Spec); err != nil {
+			return err
 		}
+	}
 
-		// Set the combined rules in the network policy
-		if len(ingressRules) > 0 {
-			netpol.Spec.Ingress = ingressRules
-		} else {
-			// If no ingress rules specified, deny all ingress
-			netpol.Spec.Ingress = []networkingv1.NetworkPolicyIngressRule{}
+	if err := u.reconcileFQDNPolicies(ctx, uc, ns); err != nil {
+		return err
+	}
+
+	// Remove the policies of entries dropped from the spec
+	policies := &networkingv1.NetworkPolicyList{}
+	if err := u.List(ctx, policies, client.InNamespace(ns.Name), client.MatchingLabels(managedLabels(uc))); err != nil {
+		return fmt.Errorf("failed to list NetworkPolicies: %w", err)
+	}
+	for i := range policies.Items {
+		if desired[policies.Items[i].Name] {
+			continue
+		}
+		if err := u.Delete(ctx, &policies.Items[i]); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete NetworkPolicy %s: %w", policies.Items[i].Name, err)
 		}
+	}
 
-		if len(egressRules) > 0 {
-			netpol.Spec.Egress = egressRules
-		} else {
-			// If no egress rules specified, deny all egress
-			netpol.Spec.Egress = []networkingv1.NetworkPolicyEgressRule{}
+	return nil
+}
+
+// reconcileFQDNPolicies renders the FQDN egress rules of ns with the configured
+// network policy backend and deletes the backend policies no longer needed.
+// Without backend the FQDNs are reported as ignored.
+func (u *UserConfigUseCase) reconcileFQDNPolicies(ctx context.Context, uc *myoperatorv1alpha1.UserConfig, ns tenantNamespace) error {
+	groups := fqdnPolicies(uc.Name, ns.NetworkPolicy)
+
+	backend, ok := networkPolicyBackends[u.settings().NetworkPolicyBackend]
+	if !ok {
+		if len(groups) > 0 {
+			u.Recorder.Eventf(uc, corev1.EventTypeWarning, EventReasonFQDNPolicyIgnored,
+				"FQDN egress rules of namespace %s require a network policy backend and were ignored", ns.Name)
+		}
+		return nil
+	}
+
+	desired := map[string]bool{}
+	for _, group := range groups {
+		desired[group.Name] = true
+		policy, err := newFQDNPolicy(backend, ns.Name, group)
+		if err != nil {
+			return err
+		}
+		spec := policy.Object["spec"]
+		existing := &unstructured.Unstructured{}
+		existing.SetGroupVersionKind(policy.GroupVersionKind())
+		existing.SetName(policy.GetName())
+		existing.SetNamespace(policy.GetNamespace())
+		if _, err := controllerutil.CreateOrUpdate(ctx, u.Client, existing, func() error {
+			existing.Object["spec"] = spec
+			return u.setManagedMetadata(uc, existing)
+		}); err != nil {
+			return fmt.Errorf("failed to reconcile %s %s: %w", policy.GetKind(), policy.GetName(), err)
 		}
 	}
 
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(backend.groupVersionKind().GroupVersion().WithKind(backend.groupVersionKind().Kind + "List"))
+	if err := u.List(ctx, list, client.InNamespace(ns.Name), client.MatchingLabels(managedLabels(uc))); err != nil {
+		return fmt.Errorf("failed to list %s: %w", backend.groupVersionKind().Kind, err)
+	}
+	for i := range list.Items {
+		if desired[list.Items[i].GetName()] {
+			continue
+		}
+		if err := u.Delete(ctx, &list.Items[i]); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete %s %s: %w", list.Items[i].GetKind(), list.Items[i].GetName(), err)
+		}
+	}
+
+	return nil
+}
+
+// applyNetworkPolicy creates or updates the NetworkPolicy name in namespace with spec
+func (u *UserConfigUseCase) applyNetworkPolicy(ctx context.Context, uc *myoperatorv1alpha1.UserConfig, namespace, name string, spec networkingv1.NetworkPolicySpec) error {
+	netpol := &networkingv1.NetworkPolicy{
+		ObjectMeta: objectMeta(uc, name, namespace),
+		Spec:       spec,
+	}
+
 	// Set controller reference
-	if err := controllerutil.SetControllerReference(uc, netpol, u.Scheme); err != nil {
+	if err := u.setManagedMetadata(uc, netpol); err != nil {
 		return fmt.Errorf("failed to set controller reference for NetworkPolicy: %w", err)
 	}
 
 	// Create or update the NetworkPolicy
 	existing := &networkingv1.NetworkPolicy{}
-	err := u.Get(ctx, client.ObjectKey{Name: netpol.Name, Namespace: uc.Name}, existing)
+	err := u.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, existing)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			if err := u.Create(ctx, netpol); err != nil {
-				return fmt.Errorf("failed to create NetworkPolicy: %w", err)
+				return fmt.Errorf("failed to create NetworkPolicy %s: %w", name, err)
 			}
 		} else {
-			return fmt.Errorf("failed to get existing NetworkPolicy: %w", err)
+			return fmt.Errorf("failed to get existing NetworkPolicy %s: %w", name, err)
 		}
 	} else {
 		// Update existing policy
 		existing.Spec = netpol.Spec
+		if err := u.setManagedMetadata(uc, existing); err != nil {
+			return fmt.Errorf("failed to set controller reference for NetworkPolicy: %w", err)
+		}
 		if err := u.Update(ctx, existing); err != nil {
-			return fmt.Errorf("failed to update NetworkPolicy: %w", err)
+			return fmt.Errorf("failed to update NetworkPolicy %s: %w", name, err)
 		}
 	}
 
 	return nil
 }
+
+// networkPolicySpec translates the unnamed entries of policies into the
+// baseline NetworkPolicy spec selecting every pod of the namespace. Traffic
+// not allowed by these entries, the named policies or the built-in defaults
+// is denied.
+func networkPolicySpec(policies []myoperatorv1alpha1.NetworkPolicy, defaults *myoperatorv1alpha1.NetworkDefaults) networkingv1.NetworkPolicySpec {
+	spec := networkingv1.NetworkPolicySpec{
+		PodSelector: metav1.LabelSelector{}, // Applies to all pods in namespace
+		PolicyTypes: []networkingv1.PolicyType{
+			networkingv1.PolicyTypeIngress,
+			networkingv1.PolicyTypeEgress,
+		},
+		// Empty ingress and egress arrays = deny all
+		Ingress: []networkingv1.NetworkPolicyIngressRule{},
+		Egress:  []networkingv1.NetworkPolicyEgressRule{},
+	}
+
+	// Combine all unnamed traffic rules into a single policy
+	for _, policy := range policies {
+		if policy.Name == "" {
+			appendNetworkPolicyRules(&spec, policy)
+		}
+	}
+
+	// Append the built-in DNS and intra-namespace rules
+	if defaults == nil || defaults.AllowDNS == nil || *defaults.AllowDNS {
+		spec.Egress = append(spec.Egress, dnsEgressRule())
+	}
+	if defaults != nil && defaults.AllowSameNamespace {
+		sameNamespace := []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}}
+		spec.Ingress = append(spec.Ingress, networkingv1.NetworkPolicyIngressRule{From: sameNamespace})
+		spec.Egress = append(spec.Egress, networkingv1.NetworkPolicyEgressRule{To: sameNamespace})
+	}
+
+	return spec
+}
+
+// namedNetworkPolicy is the spec generated for the entries sharing a name
+type namedNetworkPolicy struct {
+	Name string
+	Spec networkingv1.NetworkPolicySpec
+}
+
+// namedNetworkPolicySpecs translates the named entries of policies, in order
+// of first appearance. The pod selector of the first entry of a name is used.
+// Only the directions with rules are listed in the policy types so a named
+// policy never denies more than the baseline.
+func namedNetworkPolicySpecs(policies []myoperatorv1alpha1.NetworkPolicy) []namedNetworkPolicy {
+	var named []namedNetworkPolicy
+	index := map[string]int{}
+
+	for _, policy := range policies {
+		if policy.Name == "" {
+			continue
+		}
+		i, ok := index[policy.Name]
+		if !ok {
+			i = len(named)
+			index[policy.Name] = i
+			named = append(named, namedNetworkPolicy{Name: policy.Name})
+			if policy.PodSelector != nil {
+				named[i].Spec.PodSelector = *policy.PodSelector
+			}
+		}
+		appendNetworkPolicyRules(&named[i].Spec, policy)
+	}
+
+	for i := range named {
+		spec := &named[i].Spec
+		if len(spec.Ingress) > 0 {
+			spec.PolicyTypes = append(spec.PolicyTypes, networkingv1.PolicyTypeIngress)
+		}
+		if len(spec.Egress) > 0 {
+			spec.PolicyTypes = append(spec.PolicyTypes, networkingv1.PolicyTypeEgress)
+		}
+	}
+
+	return named
+}
+
+// appendNetworkPolicyRules appends the ingress and egress rules of policy to spec
+func appendNetworkPolicyRules(spec *networkingv1.NetworkPolicySpec, policy myoperatorv1alpha1.NetworkPolicy) {
+	// Configure ingress rules if allowTrafficFrom is specified
+	if policy.AllowTrafficFrom != nil {
+		spec.Ingress = append(spec.Ingress, networkingv1.NetworkPolicyIngressRule{
+			From:  networkPolicyPeers(policy.AllowTrafficFrom),
+			Ports: networkPolicyPorts(policy.AllowTrafficFrom.Ports),
+		})
+	}
+
+	// Configure egress rules if allowTrafficTo is specified. FQDNs are left to
+	// the network policy backend.
+	if policy.AllowTrafficTo != nil {
+		peers := networkPolicyPeers(policy.AllowTrafficTo)
+		if hasOnlyFQDNs(policy.AllowTrafficTo, peers) {
+			return
+		}
+		spec.Egress = append(spec.Egress, networkingv1.NetworkPolicyEgressRule{
+			To:    peers,
+			Ports: networkPolicyPorts(policy.AllowTrafficTo.Ports),
+		})
+	}
+}
+
+// networkPolicyPeers translates the sources or destinations of peer. Entries of
+// pods and namespaces become separate peers, entries of peers keep their pod and
+// namespace selectors together.
+func networkPolicyPeers(peer *myoperatorv1alpha1.NetworkPolicyPeer) []networkingv1.NetworkPolicyPeer {
+	var peers []networkingv1.NetworkPolicyPeer
+
+	// Configure pod selector
+	for _, podSelector := range peer.Pods {
+		peers = append(peers, networkingv1.NetworkPolicyPeer{
+			PodSelector: &metav1.LabelSelector{MatchLabels: podSelector},
+		})
+	}
+
+	// Configure namespace selector
+	for _, nsSelector := range peer.Namespaces {
+		peers = append(peers, networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: nsSelector},
+		})
+	}
+
+	// Configure combined selectors
+	for _, p := range peer.Peers {
+		translated := networkingv1.NetworkPolicyPeer{
+			PodSelector:       p.PodSelector,
+			NamespaceSelector: p.NamespaceSelector,
+		}
+		if p.IPBlock != nil {
+			translated.IPBlock = ipBlock(*p.IPBlock)
+		}
+		peers = append(peers, translated)
+	}
+
+	// Configure CIDR ranges
+	for _, block := range peer.IPBlocks {
+		peers = append(peers, networkingv1.NetworkPolicyPeer{IPBlock: ipBlock(block)})
+	}
+
+	return peers
+}
+
+// ipBlock translates block to its NetworkPolicy form
+func ipBlock(block myoperatorv1alpha1.IPBlock) *networkingv1.IPBlock {
+	return &networkingv1.IPBlock{CIDR: block.CIDR, Except: block.Except}
+}
+
+// networkPolicyPorts translates ports, defaulting the protocol to TCP
+func networkPolicyPorts(ports []myoperatorv1alpha1.NetworkPolicyPort) []networkingv1.NetworkPolicyPort {
+	var translated []networkingv1.NetworkPolicyPort
+	for _, port := range ports {
+		portNumber := port.Port
+		protocol := corev1.Protocol(port.Protocol)
+		if protocol == "" {
+			protocol = corev1.ProtocolTCP // Default to TCP if not specified
+		}
+
+		translated = append(translated, networkingv1.NetworkPolicyPort{
+			Port:     &portNumber,
+			EndPort:  port.EndPort,
+			Protocol: &protocol,
+		})
+	}
+	return translated
+}
+
+// dnsEgressRule allows DNS queries to the kube-dns pods of kube-system
+func dnsEgressRule() networkingv1.NetworkPolicyEgressRule {
+	udp, tcp := corev1.ProtocolUDP, corev1.ProtocolTCP
+	port := intstr.FromInt32(53)
+	return networkingv1.NetworkPolicyEgressRule{
+		To: []networkingv1.NetworkPolicyPeer{{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{corev1.LabelMetadataName: "kube-system"},
+			},
+			PodSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"k8s-app": "kube-dns"},
+			},
+		}},
+		Ports: []networkingv1.NetworkPolicyPort{
+			{Protocol: &udp, Port: &port},
+			{Protocol: &tcp, Port: &port},
+		},
+	}
+}
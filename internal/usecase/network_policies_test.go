@@ -0,0 +1,272 @@
+package usecase
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+)
+
+// loadExamples decodes the UserConfigs of examples/<name>, skipping other kinds
+func loadExamples(name string) []*myoperatorv1alpha1.UserConfig {
+	decoder := serializer.NewCodecFactory(testScheme).UniversalDeserializer()
+
+	data, err := os.ReadFile(filepath.Join("..", "..", "examples", name))
+	Expect(err).NotTo(HaveOccurred())
+
+	var userConfigs []*myoperatorv1alpha1.UserConfig
+	for _, doc := range strings.Split(string(data), "\n---\n") {
+		obj, _, err := decoder.Decode([]byte(doc), nil, nil)
+		Expect(err).NotTo(HaveOccurred(), name)
+		if uc, ok := obj.(*myoperatorv1alpha1.UserConfig); ok {
+			userConfigs = append(userConfigs, uc)
+		}
+	}
+	return userConfigs
+}
+
+// loadExample decodes the only UserConfig of examples/<name>
+func loadExample(name string) *myoperatorv1alpha1.UserConfig {
+	userConfigs := loadExamples(name)
+	Expect(userConfigs).To(HaveLen(1), name)
+	return userConfigs[0]
+}
+
+var _ = Describe("networkPolicySpec", func() {
+	namespaceName := func(name string) *metav1.LabelSelector {
+		return &metav1.LabelSelector{MatchLabels: map[string]string{corev1.LabelMetadataName: name}}
+	}
+	tcpPort := func(port int32) networkingv1.NetworkPolicyPort {
+		protocol := corev1.ProtocolTCP
+		number := intstr.FromInt32(port)
+		return networkingv1.NetworkPolicyPort{Port: &number, Protocol: &protocol}
+	}
+
+	It("translates every example", func() {
+		files, err := filepath.Glob(filepath.Join("..", "..", "examples", "*.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).NotTo(BeEmpty())
+
+		for _, file := range files {
+			for _, uc := range loadExamples(filepath.Base(file)) {
+				spec := networkPolicySpec(uc.Spec.NetworkPolicy, uc.Spec.NetworkDefaults)
+				Expect(spec.PodSelector).To(Equal(metav1.LabelSelector{}), file)
+				Expect(spec.PolicyTypes).To(ConsistOf(networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress), file)
+				Expect(spec.Egress).To(ContainElement(dnsEgressRule()), file)
+			}
+		}
+	})
+
+	It("keeps the pod and namespace selectors of a peer together", func() {
+		uc := loadExample("advanced-user-config.yaml")
+		spec := networkPolicySpec(uc.Spec.NetworkPolicy, uc.Spec.NetworkDefaults)
+
+		Expect(spec.Ingress).To(Equal([]networkingv1.NetworkPolicyIngressRule{{
+			From: []networkingv1.NetworkPolicyPeer{{
+				NamespaceSelector: namespaceName("frontend-namespace"),
+				PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app": "frontend"}},
+			}},
+			Ports: []networkingv1.NetworkPolicyPort{tcpPort(80), tcpPort(443)},
+		}}))
+
+		Expect(spec.Egress).To(HaveLen(2))
+		Expect(spec.Egress[0]).To(Equal(networkingv1.NetworkPolicyEgressRule{
+			To: []networkingv1.NetworkPolicyPeer{{
+				NamespaceSelector: namespaceName("database-namespace"),
+				PodSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{
+					Key:      "app",
+					Operator: metav1.LabelSelectorOpIn,
+					Values:   []string{"postgres", "redis"},
+				}}},
+			}},
+			Ports: []networkingv1.NetworkPolicyPort{tcpPort(5432), tcpPort(6379)},
+		}))
+	})
+
+	It("splits the pods and namespaces entries into separate peers", func() {
+		spec := networkPolicySpec([]myoperatorv1alpha1.NetworkPolicy{{
+			AllowTrafficFrom: &myoperatorv1alpha1.NetworkPolicyPeer{
+				Pods:       []map[string]string{{"app": "frontend"}},
+				Namespaces: []map[string]string{{corev1.LabelMetadataName: "monitoring"}},
+			},
+		}}, nil)
+
+		Expect(spec.Ingress).To(HaveLen(1))
+		Expect(spec.Ingress[0].From).To(Equal([]networkingv1.NetworkPolicyPeer{
+			{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "frontend"}}},
+			{NamespaceSelector: namespaceName("monitoring")},
+		}))
+	})
+
+	It("only allows namespace wide access from the restricted example", func() {
+		uc := loadExample("restricted-user-config.yaml")
+		spec := networkPolicySpec(uc.Spec.NetworkPolicy, uc.Spec.NetworkDefaults)
+
+		Expect(spec.Ingress).To(Equal([]networkingv1.NetworkPolicyIngressRule{{
+			From: []networkingv1.NetworkPolicyPeer{{NamespaceSelector: namespaceName("monitoring-namespace")}},
+		}}))
+		Expect(spec.Egress).To(Equal([]networkingv1.NetworkPolicyEgressRule{dnsEgressRule()}))
+	})
+
+	It("denies all traffic but DNS without policies", func() {
+		spec := networkPolicySpec(nil, nil)
+		Expect(spec.Ingress).To(BeEmpty())
+		Expect(spec.Ingress).NotTo(BeNil())
+		Expect(spec.Egress).To(Equal([]networkingv1.NetworkPolicyEgressRule{dnsEgressRule()}))
+	})
+
+	It("applies the network defaults", func() {
+		allowDNS := false
+		spec := networkPolicySpec(nil, &myoperatorv1alpha1.NetworkDefaults{AllowDNS: &allowDNS, AllowSameNamespace: true})
+
+		sameNamespace := []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}}
+		Expect(spec.Ingress).To(Equal([]networkingv1.NetworkPolicyIngressRule{{From: sameNamespace}}))
+		Expect(spec.Egress).To(Equal([]networkingv1.NetworkPolicyEgressRule{{To: sameNamespace}}))
+	})
+
+	It("translates ipBlocks, named ports and port ranges", func() {
+		endPort := int32(8100)
+		spec := networkPolicySpec([]myoperatorv1alpha1.NetworkPolicy{{
+			AllowTrafficTo: &myoperatorv1alpha1.NetworkPolicyPeer{
+				IPBlocks: []myoperatorv1alpha1.IPBlock{{CIDR: "10.0.0.0/8", Except: []string{"10.1.0.0/16"}}},
+				Ports: []myoperatorv1alpha1.NetworkPolicyPort{
+					{Port: intstr.FromString("http")},
+					{Port: intstr.FromInt32(8000), EndPort: &endPort, Protocol: "UDP"},
+				},
+			},
+		}}, nil)
+
+		udp, tcp := corev1.ProtocolUDP, corev1.ProtocolTCP
+		http, start := intstr.FromString("http"), intstr.FromInt32(8000)
+		Expect(spec.Egress[0]).To(Equal(networkingv1.NetworkPolicyEgressRule{
+			To: []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/8", Except: []string{"10.1.0.0/16"}}}},
+			Ports: []networkingv1.NetworkPolicyPort{
+				{Port: &http, Protocol: &tcp},
+				{Port: &start, EndPort: &endPort, Protocol: &udp},
+			},
+		}))
+	})
+
+	It("leaves the named entries out of the baseline", func() {
+		spec := networkPolicySpec([]myoperatorv1alpha1.NetworkPolicy{{
+			Name:             "ingress",
+			AllowTrafficFrom: &myoperatorv1alpha1.NetworkPolicyPeer{},
+		}}, nil)
+		Expect(spec.Ingress).To(BeEmpty())
+	})
+})
+
+var _ = Describe("namedNetworkPolicySpecs", func() {
+	It("generates one policy per name with its pod selector", func() {
+		ingress := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "ingress"}}
+		https := intstr.FromInt32(443)
+		named := namedNetworkPolicySpecs([]myoperatorv1alpha1.NetworkPolicy{
+			{Name: "ingress", PodSelector: ingress, AllowTrafficFrom: &myoperatorv1alpha1.NetworkPolicyPeer{
+				Ports: []myoperatorv1alpha1.NetworkPolicyPort{{Port: https}},
+			}},
+			{AllowTrafficTo: &myoperatorv1alpha1.NetworkPolicyPeer{}},
+			{Name: "metrics", AllowTrafficTo: &myoperatorv1alpha1.NetworkPolicyPeer{}},
+			{Name: "ingress", AllowTrafficTo: &myoperatorv1alpha1.NetworkPolicyPeer{}},
+		})
+
+		Expect(named).To(HaveLen(2))
+		Expect(named[0].Name).To(Equal("ingress"))
+		Expect(named[0].Spec.PodSelector).To(Equal(*ingress))
+		Expect(named[0].Spec.Ingress).To(HaveLen(1))
+		Expect(named[0].Spec.Egress).To(HaveLen(1))
+		Expect(named[0].Spec.PolicyTypes).To(Equal([]networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress}))
+
+		Expect(named[1].Name).To(Equal("metrics"))
+		Expect(named[1].Spec.PodSelector).To(Equal(metav1.LabelSelector{}))
+		Expect(named[1].Spec.Ingress).To(BeEmpty())
+		Expect(named[1].Spec.PolicyTypes).To(Equal([]networkingv1.PolicyType{networkingv1.PolicyTypeEgress}))
+	})
+})
+
+var _ = Describe("ReconcileNetworkPolicies", func() {
+	var (
+		ctx context.Context
+		u   *UserConfigUseCase
+		uc  *myoperatorv1alpha1.UserConfig
+	)
+
+	// baseline returns the baseline NetworkPolicy of namespace
+	baseline := func(namespace string) *networkingv1.NetworkPolicy {
+		policy := &networkingv1.NetworkPolicy{}
+		Expect(u.Get(ctx, client.ObjectKey{Name: "alice", Namespace: namespace}, policy)).To(Succeed())
+		return policy
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		uc = &myoperatorv1alpha1.UserConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "alice", UID: "alice-uid"},
+			Spec: myoperatorv1alpha1.UserConfigSpec{
+				Namespaces: []myoperatorv1alpha1.UserNamespace{{Suffix: "dev"}},
+			},
+		}
+		u, _ = newTestUseCase(uc)
+	})
+
+	It("lets every namespace resolve DNS and updates the baseline with the defaults", func() {
+		Expect(u.ReconcileNetworkPolicies(ctx, uc)).To(Succeed())
+		for _, namespace := range []string{"alice", "alice-dev"} {
+			policy := baseline(namespace)
+			Expect(policy.Spec.Egress).To(Equal([]networkingv1.NetworkPolicyEgressRule{dnsEgressRule()}), namespace)
+			Expect(isManagedFor(uc, policy)).To(BeTrue(), namespace)
+		}
+
+		allowDNS := false
+		uc.Spec.NetworkDefaults = &myoperatorv1alpha1.NetworkDefaults{AllowDNS: &allowDNS, AllowSameNamespace: true}
+		Expect(u.ReconcileNetworkPolicies(ctx, uc)).To(Succeed())
+		policy := baseline("alice-dev")
+		Expect(policy.Spec.Egress).To(HaveLen(1))
+		Expect(policy.Spec.Egress[0].Ports).To(BeEmpty())
+		Expect(policy.Spec.Ingress).To(HaveLen(1))
+	})
+
+	It("allows every source on the listed ports without peers", func() {
+		https := intstr.FromInt32(443)
+		uc.Spec.NetworkPolicy = []myoperatorv1alpha1.NetworkPolicy{{
+			AllowTrafficFrom: &myoperatorv1alpha1.NetworkPolicyPeer{Ports: []myoperatorv1alpha1.NetworkPolicyPort{{Port: https}}},
+		}}
+		Expect(u.ReconcileNetworkPolicies(ctx, uc)).To(Succeed())
+
+		tcp := corev1.ProtocolTCP
+		Expect(baseline("alice").Spec.Ingress).To(Equal([]networkingv1.NetworkPolicyIngressRule{{
+			Ports: []networkingv1.NetworkPolicyPort{{Port: &https, Protocol: &tcp}},
+		}}))
+	})
+
+	It("keeps the selectors of a peer together and the CIDR ranges apart", func() {
+		frontend := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "frontend"}}
+		web := &metav1.LabelSelector{MatchLabels: map[string]string{"team": "web"}}
+		uc.Spec.NetworkPolicy = []myoperatorv1alpha1.NetworkPolicy{{
+			AllowTrafficFrom: &myoperatorv1alpha1.NetworkPolicyPeer{
+				Peers:    []myoperatorv1alpha1.NetworkPeer{{PodSelector: frontend, NamespaceSelector: web}},
+				IPBlocks: []myoperatorv1alpha1.IPBlock{{CIDR: "10.0.0.0/8", Except: []string{"10.1.0.0/16"}}},
+			},
+		}}
+		Expect(u.ReconcileNetworkPolicies(ctx, uc)).To(Succeed())
+
+		Expect(baseline("alice").Spec.Ingress).To(Equal([]networkingv1.NetworkPolicyIngressRule{{
+			From: []networkingv1.NetworkPolicyPeer{
+				{PodSelector: frontend, NamespaceSelector: web},
+				{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/8", Except: []string{"10.1.0.0/16"}}},
+			},
+		}}))
+	})
+})
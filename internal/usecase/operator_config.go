@@ -0,0 +1,190 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+)
+
+// defaultKubeconfigTokenLifetime is the token lifetime used when the OperatorConfig doesn't set one
+const defaultKubeconfigTokenLifetime = 365 * 24 * time.Hour
+
+// minKubeconfigTokenLifetime is the shortest lifetime the TokenRequest API accepts
+const minKubeconfigTokenLifetime = 10 * time.Minute
+
+// defaultMaxElevationDuration is the longest AccessElevation allowed when the OperatorConfig doesn't set one
+const defaultMaxElevationDuration = 24 * time.Hour
+
+// builtinProtectedNamespaces are protected whatever the OperatorConfig lists
+var builtinProtectedNamespaces = []string{"default", "kube-system", "kube-public", "kube-node-lease"}
+
+// OperatorDefaults holds the OperatorConfig spec applied by the operator. It is
+// read by every reconcile and replaced by the OperatorConfig controller, so it
+// is safe for concurrent use. A nil OperatorDefaults holds the built-in defaults.
+type OperatorDefaults struct {
+	mu   sync.RWMutex
+	spec myoperatorv1alpha1.OperatorConfigSpec
+}
+
+// NewOperatorDefaults returns OperatorDefaults holding the built-in defaults
+func NewOperatorDefaults() *OperatorDefaults {
+	return &OperatorDefaults{}
+}
+
+// Spec returns a copy of the applied spec
+func (d *OperatorDefaults) Spec() myoperatorv1alpha1.OperatorConfigSpec {
+	if d == nil {
+		return myoperatorv1alpha1.OperatorConfigSpec{}
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return *d.spec.DeepCopy()
+}
+
+// Set applies spec and reports whether it differs from the previous one
+func (d *OperatorDefaults) Set(spec myoperatorv1alpha1.OperatorConfigSpec) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if equality.Semantic.DeepEqual(d.spec, spec) {
+		return false
+	}
+	d.spec = *spec.DeepCopy()
+	return true
+}
+
+// Load applies the OperatorConfig singleton read from reader, keeping the
+// built-in defaults when it doesn't exist
+func (d *OperatorDefaults) Load(ctx context.Context, reader client.Reader) error {
+	config := &myoperatorv1alpha1.OperatorConfig{}
+	if err := reader.Get(ctx, client.ObjectKey{Name: myoperatorv1alpha1.OperatorConfigName}, config); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get OperatorConfig %s: %w", myoperatorv1alpha1.OperatorConfigName, err)
+	}
+	if err := ValidateOperatorConfig(&config.Spec); err != nil {
+		return fmt.Errorf("invalid OperatorConfig %s: %w", config.Name, err)
+	}
+	d.Set(config.Spec)
+	return nil
+}
+
+// ValidateOperatorConfig checks the defaults of spec can be applied
+func ValidateOperatorConfig(spec *myoperatorv1alpha1.OperatorConfigSpec) error {
+	var errs []error
+	if rq := spec.DefaultResourceQuota; rq != nil {
+		if _, err := resourceQuotaSpec(rq, nil); err != nil {
+			errs = append(errs, fmt.Errorf("defaultResourceQuota: %w", err))
+		}
+		if rq.Autoscale != nil || len(rq.Additional) > 0 {
+			errs = append(errs, errors.New("defaultResourceQuota: autoscale and additional quotas aren't supported"))
+		}
+	}
+	if _, err := limitRangeSpec(spec.DefaultLimitRange, nil); err != nil {
+		errs = append(errs, fmt.Errorf("defaultLimitRange: %w", err))
+	}
+	if lifetime := spec.KubeconfigTokenLifetime; lifetime != nil && lifetime.Duration < minKubeconfigTokenLifetime {
+		errs = append(errs, fmt.Errorf("kubeconfigTokenLifetime: must be at least %s", minKubeconfigTokenLifetime))
+	}
+	if limit := spec.MaxElevationDuration; limit != nil && limit.Duration <= 0 {
+		errs = append(errs, errors.New("maxElevationDuration: must be positive"))
+	}
+	for _, name := range spec.ProtectedNamespaces {
+		if msgs := validation.IsDNS1123Label(name); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("protectedNamespaces: %q: %s", name, msgs[0]))
+		}
+	}
+
+	if level := spec.DefaultPodSecurityLevel; level != "" && !IsPodSecurityLevel(level) {
+		errs = append(errs, fmt.Errorf("defaultPodSecurityLevel: unknown level %q", level))
+	}
+	if backend := spec.NetworkPolicyBackend; !IsNetworkPolicyBackend(backend) {
+		errs = append(errs, fmt.Errorf("networkPolicyBackend: unknown backend %q", backend))
+	}
+
+	if policy := spec.Approval; policy != nil {
+		for name, value := range policy.ResourceQuota {
+			if _, err := resource.ParseQuantity(value); err != nil {
+				errs = append(errs, fmt.Errorf("approval: invalid resourceQuota threshold %q of %s", value, name))
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(spec.NamespaceLabels))
+	for key := range spec.NamespaceLabels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if isReservedMetadataKey(key) || len(validation.IsQualifiedName(key)) > 0 || len(validation.IsValidLabelValue(spec.NamespaceLabels[key])) > 0 {
+			errs = append(errs, fmt.Errorf("namespaceLabels: %q is reserved or invalid", key))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// settings returns the Config of the use case with the fields the OperatorConfig
+// sets, which take precedence over the operator flags
+func (u *UserConfigUseCase) settings() Config {
+	config := u.Config
+	spec := u.Defaults.Spec()
+	if spec.DefaultPodSecurityLevel != "" {
+		config.PodSecurityLevel = spec.DefaultPodSecurityLevel
+	}
+	if len(spec.ClusterReadResources) > 0 {
+		config.ClusterReadResources = spec.ClusterReadResources
+	}
+	if len(spec.PrivilegedGroups) > 0 {
+		config.RBACPolicy.PrivilegedGroups = spec.PrivilegedGroups
+	}
+	if spec.NetworkPolicyBackend != "" {
+		config.NetworkPolicyBackend = spec.NetworkPolicyBackend
+	}
+	return config
+}
+
+// kubeconfigTokenLifetime returns the lifetime of the kubeconfig tokens set by spec
+func kubeconfigTokenLifetime(spec myoperatorv1alpha1.OperatorConfigSpec) time.Duration {
+	if spec.KubeconfigTokenLifetime == nil {
+		return defaultKubeconfigTokenLifetime
+	}
+	return spec.KubeconfigTokenLifetime.Duration
+}
+
+// maxElevationDuration returns the longest AccessElevation allowed by spec
+func maxElevationDuration(spec myoperatorv1alpha1.OperatorConfigSpec) time.Duration {
+	if spec.MaxElevationDuration == nil {
+		return defaultMaxElevationDuration
+	}
+	return spec.MaxElevationDuration.Duration
+}
+
+// isProtectedNamespace reports whether name can't be managed for a UserConfig
+func isProtectedNamespace(spec myoperatorv1alpha1.OperatorConfigSpec, name string) bool {
+	return slices.Contains(builtinProtectedNamespaces, name) || slices.Contains(spec.ProtectedNamespaces, name)
+}
+
+// ProtectedNamespaces returns the namespaces of uc that can't be managed for a UserConfig
+func (u *UserConfigUseCase) ProtectedNamespaces(uc *myoperatorv1alpha1.UserConfig) []string {
+	spec := u.Defaults.Spec()
+	var protected []string
+	for _, name := range NamespaceNames(uc) {
+		if isProtectedNamespace(spec, name) {
+			protected = append(protected, name)
+		}
+	}
+	return protected
+}
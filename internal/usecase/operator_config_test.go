@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+)
+
+var _ = Describe("operator config", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("loads the singleton and keeps the built-in defaults without it", func() {
+		defaults := NewOperatorDefaults()
+		u, _ := newTestUseCase()
+		Expect(defaults.Load(ctx, u)).To(Succeed())
+		Expect(kubeconfigTokenLifetime(defaults.Spec())).To(Equal(365 * 24 * time.Hour))
+
+		config := &myoperatorv1alpha1.OperatorConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: myoperatorv1alpha1.OperatorConfigName},
+			Spec: myoperatorv1alpha1.OperatorConfigSpec{
+				KubeconfigTokenLifetime: &metav1.Duration{Duration: 24 * time.Hour},
+			},
+		}
+		u, _ = newTestUseCase(config)
+		Expect(defaults.Load(ctx, u)).To(Succeed())
+		Expect(kubeconfigTokenLifetime(defaults.Spec())).To(Equal(24 * time.Hour))
+
+		// Applying the same spec again isn't a change
+		Expect(defaults.Set(config.Spec)).To(BeFalse())
+		Expect(defaults.Set(myoperatorv1alpha1.OperatorConfigSpec{})).To(BeTrue())
+	})
+
+	It("reports every invalid default", func() {
+		err := ValidateOperatorConfig(&myoperatorv1alpha1.OperatorConfigSpec{
+			DefaultResourceQuota:    &myoperatorv1alpha1.ResourceQuota{Memory: "lots"},
+			KubeconfigTokenLifetime: &metav1.Duration{Duration: time.Minute},
+			ProtectedNamespaces:     []string{"Platform"},
+			NamespaceLabels:         map[string]string{"team": "core", "kubernetes.io/metadata.name": "x"},
+			DefaultPodSecurityLevel: "strict",
+			NetworkPolicyBackend:    "flannel",
+		})
+		Expect(err).To(MatchError(ContainSubstring(`defaultResourceQuota: invalid quantity "lots" of memory`)))
+		Expect(err).To(MatchError(ContainSubstring("kubeconfigTokenLifetime: must be at least 10m0s")))
+		Expect(err).To(MatchError(ContainSubstring(`protectedNamespaces: "Platform"`)))
+		Expect(err).To(MatchError(ContainSubstring(`namespaceLabels: "kubernetes.io/metadata.name" is reserved or invalid`)))
+		Expect(err).To(MatchError(ContainSubstring(`defaultPodSecurityLevel: unknown level "strict"`)))
+		Expect(err).To(MatchError(ContainSubstring(`networkPolicyBackend: unknown backend "flannel"`)))
+		Expect(err.Error()).NotTo(ContainSubstring(`"team"`))
+	})
+
+	It("overrides the operator flags with the settings it sets", func() {
+		u, _ := newTestUseCase()
+		u.Config.NetworkPolicyBackend = NetworkPolicyBackendCilium
+		Expect(u.settings()).To(Equal(u.Config))
+
+		u.Defaults = NewOperatorDefaults()
+		u.Defaults.Set(myoperatorv1alpha1.OperatorConfigSpec{
+			DefaultPodSecurityLevel: "baseline",
+			ClusterReadResources:    []string{"nodes"},
+			PrivilegedGroups:        []string{"platform"},
+			NetworkPolicyBackend:    NetworkPolicyBackendNone,
+		})
+		settings := u.settings()
+		Expect(settings.PodSecurityLevel).To(Equal("baseline"))
+		Expect(settings.ClusterReadResources).To(Equal([]string{"nodes"}))
+		Expect(settings.RBACPolicy.PrivilegedGroups).To(Equal([]string{"platform"}))
+		Expect(u.podSecurityLabels(nil)).To(HaveKeyWithValue("pod-security.kubernetes.io/enforce", "baseline"))
+		Expect(networkPolicyBackends).NotTo(HaveKey(settings.NetworkPolicyBackend))
+		// The flags themselves are left untouched
+		Expect(u.Config.PodSecurityLevel).To(Equal("restricted"))
+	})
+
+	It("falls back to the default quota and limits", func() {
+		quota, err := resourceQuotaSpec(nil, &myoperatorv1alpha1.ResourceQuota{Pods: "5"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(quota.Hard).To(HaveLen(1))
+		Expect(quota.Hard.Pods().String()).To(Equal("5"))
+
+		defaults := &myoperatorv1alpha1.LimitRange{Limits: []myoperatorv1alpha1.LimitRangeLimit{
+			{Type: "Container", Max: &myoperatorv1alpha1.Resources{CPU: "1", Memory: "2Gi"}},
+		}}
+		spec, err := limitRangeSpec(nil, defaults)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spec.Limits).To(HaveLen(1))
+		Expect(spec.Limits[0].Max.Cpu().String()).To(Equal("1"))
+		// Unset default limits keep the built-in ones
+		Expect(spec.Limits[0].DefaultRequest.Cpu().String()).To(Equal("250m"))
+
+		// The default container limits fill the fields a UserConfig leaves unset
+		spec, err = limitRangeSpec(&myoperatorv1alpha1.LimitRange{Limits: []myoperatorv1alpha1.LimitRangeLimit{
+			{Type: "Container", Min: &myoperatorv1alpha1.Resources{CPU: "100m"}},
+		}}, defaults)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spec.Limits[0].Min.Cpu().String()).To(Equal("100m"))
+		Expect(spec.Limits[0].Max.Memory().String()).To(Equal("2Gi"))
+	})
+
+	It("labels the namespaces and refuses the protected ones", func() {
+		defaults := NewOperatorDefaults()
+		defaults.Set(myoperatorv1alpha1.OperatorConfigSpec{
+			ProtectedNamespaces: []string{"platform"},
+			NamespaceLabels:     map[string]string{"cost-center": "shared", "team": "platform"},
+		})
+		uc := &myoperatorv1alpha1.UserConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "alice", UID: "alice-uid"},
+			Spec: myoperatorv1alpha1.UserConfigSpec{
+				NamespaceMetadata: &myoperatorv1alpha1.NamespaceMetadata{Labels: map[string]string{"team": "web"}},
+			},
+		}
+		u, _ := newTestUseCase(uc)
+		u.Defaults = defaults
+
+		Expect(u.ReconcileNamespace(ctx, uc)).To(Succeed())
+		namespace := &corev1.Namespace{}
+		Expect(u.Get(ctx, client.ObjectKey{Name: "alice"}, namespace)).To(Succeed())
+		Expect(namespace.Labels).To(HaveKeyWithValue("cost-center", "shared"))
+		Expect(namespace.Labels).To(HaveKeyWithValue("team", "web"))
+
+		uc.Spec.Namespaces = []myoperatorv1alpha1.UserNamespace{{Name: "platform"}, {Name: "kube-system"}}
+		Expect(u.ProtectedNamespaces(uc)).To(Equal([]string{"platform", "kube-system"}))
+		Expect(u.ReconcileNamespace(ctx, uc)).To(MatchError("namespaces platform, kube-system are protected by OperatorConfig cluster"))
+	})
+})
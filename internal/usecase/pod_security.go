@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/client-go/rest"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+)
+
+// Pod Security Admission namespace labels
+const (
+	LabelPodSecurityEnforce        = "pod-security.kubernetes.io/enforce"
+	LabelPodSecurityEnforceVersion = "pod-security.kubernetes.io/enforce-version"
+	LabelPodSecurityAudit          = "pod-security.kubernetes.io/audit"
+	LabelPodSecurityAuditVersion   = "pod-security.kubernetes.io/audit-version"
+	LabelPodSecurityWarn           = "pod-security.kubernetes.io/warn"
+	LabelPodSecurityWarnVersion    = "pod-security.kubernetes.io/warn-version"
+)
+
+// podSecurityRank orders the Pod Security levels from the least to the most restrictive
+var podSecurityRank = map[string]int{
+	"privileged": 0,
+	"baseline":   1,
+	"restricted": 2,
+}
+
+// IsPodSecurityLevel reports whether level is a Pod Security level
+func IsPodSecurityLevel(level string) bool {
+	_, ok := podSecurityRank[level]
+	return ok
+}
+
+// podSecurityLabels returns the Pod Security Admission labels for ps, unset
+// fields falling back to the operator defaults
+func (u *UserConfigUseCase) podSecurityLabels(ps *myoperatorv1alpha1.PodSecurity) map[string]string {
+	config := u.settings()
+	enforce, audit, warn := config.PodSecurityLevel, config.PodSecurityLevel, config.PodSecurityLevel
+	version := config.PodSecurityVersion
+	if ps != nil {
+		if ps.Enforce != "" {
+			enforce = ps.Enforce
+		}
+		if ps.Audit != "" {
+			audit = ps.Audit
+		}
+		if ps.Warn != "" {
+			warn = ps.Warn
+		}
+		if ps.Version != "" {
+			version = ps.Version
+		}
+	}
+
+	return map[string]string{
+		LabelPodSecurityEnforce:        enforce,
+		LabelPodSecurityEnforceVersion: version,
+		LabelPodSecurityAudit:          audit,
+		LabelPodSecurityAuditVersion:   version,
+		LabelPodSecurityWarn:           warn,
+		LabelPodSecurityWarnVersion:    version,
+	}
+}
+
+// tightensPodSecurity reports whether labels enforce a stricter level than namespace.
+// A namespace without enforce label admits privileged pods.
+func tightensPodSecurity(namespace *corev1.Namespace, labels map[string]string) bool {
+	current, ok := namespace.Labels[LabelPodSecurityEnforce]
+	if !ok {
+		current = "privileged"
+	}
+	return podSecurityRank[labels[LabelPodSecurityEnforce]] > podSecurityRank[current]
+}
+
+// dryRunPodSecurity applies labels to namespace with a server side dry-run and
+// returns the warnings of the Pod Security admission about existing pods
+// violating the new enforce level. The warnings are only collected when the
+// REST config of the manager is set, the dry-run goes through the client otherwise.
+func (u *UserConfigUseCase) dryRunPodSecurity(ctx context.Context, namespace *corev1.Namespace, labels map[string]string) ([]string, error) {
+	c := u.Client
+	collector := &warningCollector{}
+	if u.Config.RESTConfig != nil {
+		config := rest.CopyConfig(u.Config.RESTConfig)
+		config.WarningHandler = collector
+		var err error
+		c, err = client.New(config, client.Options{Scheme: u.Scheme, Mapper: u.RESTMapper()})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create dry-run client: %w", err)
+		}
+	}
+
+	dryRun := namespace.DeepCopy()
+	dryRun.Labels = mergeStringMaps(dryRun.Labels, labels)
+	if err := c.Update(ctx, dryRun, client.DryRunAll); err != nil {
+		return nil, fmt.Errorf("failed to dry-run Pod Security labels on namespace %s: %w", namespace.Name, err)
+	}
+	return collector.warnings, nil
+}
+
+// warningCollector records the warning headers returned by the API server
+type warningCollector struct {
+	mu       sync.Mutex
+	warnings []string
+}
+
+func (c *warningCollector) HandleWarningHeader(code int, _ string, text string) {
+	if code != 299 || text == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.warnings = append(c.warnings, text)
+}
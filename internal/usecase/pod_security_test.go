@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+)
+
+var _ = Describe("Pod Security", func() {
+	u := &UserConfigUseCase{Config: DefaultConfig()}
+
+	DescribeTable("podSecurityLabels",
+		func(ps *myoperatorv1alpha1.PodSecurity, enforce, audit, warn, version string) {
+			Expect(u.podSecurityLabels(ps)).To(Equal(map[string]string{
+				LabelPodSecurityEnforce:        enforce,
+				LabelPodSecurityEnforceVersion: version,
+				LabelPodSecurityAudit:          audit,
+				LabelPodSecurityAuditVersion:   version,
+				LabelPodSecurityWarn:           warn,
+				LabelPodSecurityWarnVersion:    version,
+			}))
+		},
+		Entry("operator defaults", nil, "restricted", "restricted", "restricted", "latest"),
+		Entry("partial override", &myoperatorv1alpha1.PodSecurity{Enforce: "baseline"}, "baseline", "restricted", "restricted", "latest"),
+		Entry("full override", &myoperatorv1alpha1.PodSecurity{Enforce: "privileged", Audit: "baseline", Warn: "baseline", Version: "v1.31"},
+			"privileged", "baseline", "baseline", "v1.31"),
+	)
+
+	DescribeTable("tightensPodSecurity",
+		func(current, next string, tightens bool) {
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "alice"}}
+			if current != "" {
+				namespace.Labels = map[string]string{LabelPodSecurityEnforce: current}
+			}
+			Expect(tightensPodSecurity(namespace, map[string]string{LabelPodSecurityEnforce: next})).To(Equal(tightens))
+		},
+		Entry("unlabelled namespace to restricted", "", "restricted", true),
+		Entry("unlabelled namespace to privileged", "", "privileged", false),
+		Entry("baseline to restricted", "baseline", "restricted", true),
+		Entry("same level", "baseline", "baseline", false),
+		Entry("loosened", "restricted", "baseline", false),
+	)
+
+	It("collects only the warning headers", func() {
+		collector := &warningCollector{}
+		collector.HandleWarningHeader(299, "", `existing pods in namespace "alice" violate the new PodSecurity enforce level "restricted:latest"`)
+		collector.HandleWarningHeader(299, "", "")
+		collector.HandleWarningHeader(199, "", "miscellaneous")
+		Expect(collector.warnings).To(Equal([]string{`existing pods in namespace "alice" violate the new PodSecurity enforce level "restricted:latest"`}))
+	})
+
+	It("accepts only the Pod Security levels", func() {
+		for _, level := range []string{"privileged", "baseline", "restricted"} {
+			Expect(IsPodSecurityLevel(level)).To(BeTrue(), level)
+		}
+		Expect(IsPodSecurityLevel("strict")).To(BeFalse())
+		Expect(IsPodSecurityLevel("")).To(BeFalse())
+	})
+})
@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// RBACPolicy guards the generated Roles against privilege escalation
+type RBACPolicy struct {
+	// Reject fails the reconciliation when a rule is dangerous instead of
+	// stripping the dangerous verbs
+	Reject bool
+
+	// PrivilegedGroups lists the identity groups exempt from the policy
+	PrivilegedGroups []string
+}
+
+var (
+	// escalationVerbs allow privilege escalation on any resource
+	escalationVerbs = map[string]bool{"bind": true, "escalate": true, "impersonate": true, "*": true}
+
+	// writeVerbs modify a resource
+	writeVerbs = map[string]bool{"create": true, "update": true, "patch": true, "delete": true, "deletecollection": true, "*": true}
+
+	// rbacResources are the RBAC objects a user must not write
+	rbacResources = map[string]bool{"roles": true, "rolebindings": true, "clusterroles": true, "clusterrolebindings": true, "*": true}
+)
+
+// exempt reports whether one of groups is a privileged group
+func (p RBACPolicy) exempt(groups []string) bool {
+	for _, group := range groups {
+		for _, privileged := range p.PrivilegedGroups {
+			if group == privileged {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Apply returns rules without their dangerous verbs along with a description
+// of every removed verb, e.g. "rolebindings: create, delete". Rules left
+// without verbs are dropped. In reject mode an error is returned instead.
+func (p RBACPolicy) Apply(groups []string, rules []rbacv1.PolicyRule) ([]rbacv1.PolicyRule, []string, error) {
+	if p.exempt(groups) {
+		return rules, nil, nil
+	}
+
+	allowed := []rbacv1.PolicyRule{}
+	var filtered []string
+	for _, rule := range rules {
+		var verbs, removed []string
+		for _, verb := range rule.Verbs {
+			if dangerousVerb(rule, verb) {
+				removed = append(removed, verb)
+				continue
+			}
+			verbs = append(verbs, verb)
+		}
+		if len(removed) > 0 {
+			filtered = append(filtered, fmt.Sprintf("%s: %s", strings.Join(rule.Resources, ","), strings.Join(removed, ", ")))
+		}
+		if len(verbs) == 0 {
+			continue
+		}
+		rule.Verbs = verbs
+		allowed = append(allowed, rule)
+	}
+	sort.Strings(filtered)
+
+	if p.Reject && len(filtered) > 0 {
+		return nil, filtered, fmt.Errorf("permissions rejected by RBAC policy: %s", strings.Join(filtered, "; "))
+	}
+	return allowed, filtered, nil
+}
+
+// dangerousVerb reports whether granting verb on the resources of rule allows privilege escalation
+func dangerousVerb(rule rbacv1.PolicyRule, verb string) bool {
+	if escalationVerbs[verb] {
+		return true
+	}
+	for _, resource := range rule.Resources {
+		if writeVerbs[verb] && rbacResources[resource] && ruleHasGroup(rule, rbacv1.GroupName) {
+			return true
+		}
+		if verb == "create" && resource == "serviceaccounts/token" {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleHasGroup reports whether rule applies to group
+func ruleHasGroup(rule rbacv1.PolicyRule, group string) bool {
+	for _, g := range rule.APIGroups {
+		if g == group || g == "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+)
+
+var _ = Describe("RBACPolicy", func() {
+	rule := func(group, resource string, verbs ...string) rbacv1.PolicyRule {
+		return rbacv1.PolicyRule{APIGroups: []string{group}, Resources: []string{resource}, Verbs: verbs}
+	}
+	policy := RBACPolicy{PrivilegedGroups: []string{"admin"}}
+
+	DescribeTable("Apply",
+		func(groups []string, rules, allowed []rbacv1.PolicyRule, filtered []string) {
+			kept, removed, err := policy.Apply(groups, rules)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(kept).To(Equal(allowed))
+			Expect(removed).To(Equal(filtered))
+		},
+		Entry("keeps harmless rules",
+			nil,
+			[]rbacv1.PolicyRule{rule("apps", "deployments", "get", "create")},
+			[]rbacv1.PolicyRule{rule("apps", "deployments", "get", "create")}, nil),
+		Entry("strips RBAC writes but keeps reads",
+			nil,
+			[]rbacv1.PolicyRule{rule(rbacv1.GroupName, "rolebindings", "get", "create", "delete")},
+			[]rbacv1.PolicyRule{rule(rbacv1.GroupName, "rolebindings", "get")}, []string{"rolebindings: create, delete"}),
+		Entry("ignores RBAC resource names of other groups",
+			nil,
+			[]rbacv1.PolicyRule{rule("apps", "roles", "create")},
+			[]rbacv1.PolicyRule{rule("apps", "roles", "create")}, nil),
+		Entry("drops the rules left without verbs",
+			nil,
+			[]rbacv1.PolicyRule{rule("", "serviceaccounts/token", "create"), rule("", "pods", "bind", "impersonate")},
+			[]rbacv1.PolicyRule{}, []string{"pods: bind, impersonate", "serviceaccounts/token: create"}),
+		Entry("strips wildcard verbs",
+			nil,
+			[]rbacv1.PolicyRule{rule("", "secrets", "*")},
+			[]rbacv1.PolicyRule{}, []string{"secrets: *"}),
+		Entry("exempts the privileged groups",
+			[]string{"dev", "admin"},
+			[]rbacv1.PolicyRule{rule("*", "*", "*")},
+			[]rbacv1.PolicyRule{rule("*", "*", "*")}, nil),
+	)
+
+	It("rejects dangerous rules instead of stripping them in reject mode", func() {
+		reject := RBACPolicy{Reject: true}
+		_, filtered, err := reject.Apply(nil, []rbacv1.PolicyRule{rule(rbacv1.GroupName, "roles", "escalate")})
+		Expect(err).To(MatchError("permissions rejected by RBAC policy: roles: escalate"))
+		Expect(filtered).To(Equal([]string{"roles: escalate"}))
+
+		allowed, _, err := reject.Apply(nil, []rbacv1.PolicyRule{rule("", "pods", "get")})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(allowed).To(HaveLen(1))
+	})
+
+	It("filters the Role of every namespace and reports the removed verbs", func() {
+		ctx := context.Background()
+		uc := &myoperatorv1alpha1.UserConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "alice", UID: "alice-uid"},
+			Spec: myoperatorv1alpha1.UserConfigSpec{
+				Namespaces: []myoperatorv1alpha1.UserNamespace{{Suffix: "dev"}},
+				Permissions: myoperatorv1alpha1.Permissions{Resources: []myoperatorv1alpha1.ResourcePermission{
+					{Resource: "role", Operation: "CR"},
+					{Resource: "deployment", Operation: "CRUD"},
+				}},
+			},
+		}
+		u, recorder := newTestUseCase(uc)
+
+		Expect(u.ReconcileRole(ctx, uc)).To(Succeed())
+		Expect(uc.Status.FilteredPermissions).To(Equal([]string{"roles: create"}))
+		Expect(recorder.Events).To(Receive(ContainSubstring("PermissionsFiltered Permissions filtered by RBAC policy: roles: create")))
+		for _, namespace := range []string{"alice", "alice-dev"} {
+			role := &rbacv1.Role{}
+			Expect(u.Get(ctx, client.ObjectKey{Name: "alice", Namespace: namespace}, role)).To(Succeed())
+			Expect(role.Rules).To(HaveLen(2), namespace)
+			Expect(role.Rules[0].Verbs).NotTo(ContainElement("create"), namespace)
+		}
+	})
+})
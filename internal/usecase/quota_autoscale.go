@@ -0,0 +1,326 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+)
+
+// Pressures of an autoscaled quota resource
+const (
+	quotaPressureHigh = "High"
+	quotaPressureLow  = "Low"
+)
+
+// maxQuotaHistory is the number of adjustments kept in status.quotaHistory
+const maxQuotaHistory = 20
+
+// Autoscale settings applied when the spec leaves them unset, matching the CRD defaults
+const (
+	defaultScaleUpThreshold    = 80
+	defaultScaleDownThreshold  = 30
+	defaultStepPercent         = 25
+	defaultStabilizationWindow = 10 * time.Minute
+)
+
+// integerQuotaResources are the quota resources counting objects, which the
+// ResourceQuota API only accepts as whole numbers
+var integerQuotaResources = map[corev1.ResourceName]bool{
+	corev1.ResourcePods:                   true,
+	corev1.ResourceServices:               true,
+	corev1.ResourceReplicationControllers: true,
+	corev1.ResourceQuotas:                 true,
+	corev1.ResourceSecrets:                true,
+	corev1.ResourceConfigMaps:             true,
+	corev1.ResourcePersistentVolumeClaims: true,
+	corev1.ResourceServicesNodePorts:      true,
+	corev1.ResourceServicesLoadBalancers:  true,
+}
+
+// IsIntegerQuotaResource reports whether the quota resource name counts objects
+func IsIntegerQuotaResource(name string) bool {
+	return integerQuotaResources[corev1.ResourceName(name)] || strings.HasPrefix(name, "count/")
+}
+
+// autoscaleBounds parses the bounds of an autoscaled resource
+func autoscaleBounds(res myoperatorv1alpha1.AutoscaledResource) (resource.Quantity, resource.Quantity, error) {
+	minimum, err := resource.ParseQuantity(res.Min)
+	if err != nil {
+		return minimum, minimum, fmt.Errorf("invalid min %q of %s: %w", res.Min, res.Name, err)
+	}
+	maximum, err := resource.ParseQuantity(res.Max)
+	if err != nil {
+		return minimum, maximum, fmt.Errorf("invalid max %q of %s: %w", res.Max, res.Name, err)
+	}
+	return minimum, maximum, nil
+}
+
+// clampQuantity returns q bounded by minimum and maximum
+func clampQuantity(q, minimum, maximum resource.Quantity) resource.Quantity {
+	if q.Cmp(minimum) < 0 {
+		return minimum
+	}
+	if q.Cmp(maximum) > 0 {
+		return maximum
+	}
+	return q
+}
+
+// findAutoscaledQuota returns the autoscaling state of resource in namespace
+func findAutoscaledQuota(states []myoperatorv1alpha1.AutoscaledQuota, namespace, resource string) *myoperatorv1alpha1.AutoscaledQuota {
+	for i := range states {
+		if states[i].Namespace == namespace && states[i].Resource == resource {
+			return &states[i]
+		}
+	}
+	return nil
+}
+
+// applyAutoscaledHard replaces the hard limits of the autoscaled resources of
+// spec by their current value recorded in the status of uc, or by the spec
+// value bounded by min and max before the first adjustment
+func applyAutoscaledHard(spec *corev1.ResourceQuotaSpec, autoscale *myoperatorv1alpha1.QuotaAutoscale, uc *myoperatorv1alpha1.UserConfig, namespace string) error {
+	if autoscale == nil {
+		return nil
+	}
+	for _, res := range autoscale.Resources {
+		minimum, maximum, err := autoscaleBounds(res)
+		if err != nil {
+			return err
+		}
+		hard, ok := spec.Hard[corev1.ResourceName(res.Name)]
+		if !ok {
+			hard = minimum
+		}
+		if state := findAutoscaledQuota(uc.Status.QuotaAutoscale, namespace, res.Name); state != nil {
+			if current, err := resource.ParseQuantity(state.Hard); err == nil {
+				hard = current
+			}
+		}
+		spec.Hard[corev1.ResourceName(res.Name)] = clampQuantity(hard, minimum, maximum)
+	}
+	return nil
+}
+
+// withAutoscaleDefaults returns a copy of autoscale with the unset settings defaulted
+func withAutoscaleDefaults(autoscale *myoperatorv1alpha1.QuotaAutoscale) *myoperatorv1alpha1.QuotaAutoscale {
+	settings := autoscale.DeepCopy()
+	if settings.ScaleUpThreshold == 0 {
+		settings.ScaleUpThreshold = defaultScaleUpThreshold
+	}
+	// Zero is a valid scale down threshold, it is only defaulted along with the
+	// scale up threshold
+	if settings.ScaleDownThreshold == 0 && autoscale.ScaleUpThreshold == 0 {
+		settings.ScaleDownThreshold = defaultScaleDownThreshold
+	}
+	if settings.StepPercent == 0 {
+		settings.StepPercent = defaultStepPercent
+	}
+	if settings.StabilizationWindow == nil {
+		settings.StabilizationWindow = &metav1.Duration{Duration: defaultStabilizationWindow}
+	}
+	return settings
+}
+
+// scaledQuantity returns current grown, or shrunk, by step percent. Only CPU
+// resources move by fractions of a unit, the others by at least one unit.
+func scaledQuantity(name string, current resource.Quantity, step int32, up bool) resource.Quantity {
+	delta := current.MilliValue() * int64(step) / 100
+	if !strings.Contains(name, "cpu") {
+		delta = (delta + 999) / 1000 * 1000
+	}
+	if delta == 0 {
+		delta = 1
+	}
+	next := current.MilliValue() + delta
+	if !up {
+		next = current.MilliValue() - delta
+	}
+	if next < 0 {
+		next = 0
+	}
+	return *resource.NewMilliQuantity(next, current.Format)
+}
+
+// AutoscaleQuotas grows or shrinks the autoscaled hard limits of the main
+// quota of every namespace of uc from the observed usage, updating the
+// ResourceQuotas right away. Growth is capped by the headroom left in the
+// TenantBudgets. The state and the adjustments are recorded in the status of
+// uc; the caller is responsible for persisting it.
+func (u *UserConfigUseCase) AutoscaleQuotas(ctx context.Context, uc *myoperatorv1alpha1.UserConfig, now time.Time) error {
+	resolved, err := u.ResolveTemplate(ctx, uc)
+	if err != nil {
+		return err
+	}
+
+	// headroom is only computed once a limit grows, it lists every UserConfig
+	var headroom corev1.ResourceList
+	headroomLoaded := false
+
+	var states []myoperatorv1alpha1.AutoscaledQuota
+	for _, ns := range tenantNamespaces(resolved) {
+		if ns.ResourceQuotas == nil || ns.ResourceQuotas.Autoscale == nil {
+			continue
+		}
+		autoscale := withAutoscaleDefaults(ns.ResourceQuotas.Autoscale)
+
+		quota := &corev1.ResourceQuota{}
+		if err := u.Get(ctx, client.ObjectKey{Name: uc.Name, Namespace: ns.Name}, quota); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("failed to get ResourceQuota in namespace %s: %w", ns.Name, err)
+		}
+
+		changed := false
+		for _, res := range autoscale.Resources {
+			minimum, maximum, err := autoscaleBounds(res)
+			if err != nil {
+				return fmt.Errorf("invalid autoscale of namespace %s: %w", ns.Name, err)
+			}
+			state := myoperatorv1alpha1.AutoscaledQuota{Namespace: ns.Name, Resource: res.Name}
+			if previous := findAutoscaledQuota(uc.Status.QuotaAutoscale, ns.Name, res.Name); previous != nil {
+				state = *previous
+			}
+
+			name := corev1.ResourceName(res.Name)
+			current, ok := quota.Spec.Hard[name]
+			if !ok {
+				current = minimum
+			}
+			next, utilization := nextAutoscaledHard(&state, autoscale, res.Name, current, quota.Status.Used[name], now)
+			next = clampQuantity(next, minimum, maximum)
+			if next.Cmp(current) > 0 {
+				if !headroomLoaded {
+					if headroom, err = u.budgetHeadroom(ctx, uc); err != nil {
+						return err
+					}
+					headroomLoaded = true
+				}
+				next = capToHeadroom(ctx, headroom, uc, ns.Name, name, current, next)
+			}
+			state.Hard = next.String()
+			states = append(states, state)
+			if next.Cmp(current) == 0 {
+				continue
+			}
+
+			changed = true
+			state.LastScaleTime = &metav1.Time{Time: now}
+			state.PressureSince = &metav1.Time{Time: now}
+			states[len(states)-1] = state
+			if quota.Spec.Hard == nil {
+				quota.Spec.Hard = corev1.ResourceList{}
+			}
+			quota.Spec.Hard[name] = next
+
+			uc.Status.QuotaHistory = append(uc.Status.QuotaHistory, myoperatorv1alpha1.QuotaAdjustment{
+				Time:        metav1.Time{Time: now},
+				Namespace:   ns.Name,
+				Resource:    res.Name,
+				From:        current.String(),
+				To:          next.String(),
+				Utilization: utilization,
+			})
+			reason, verb := EventReasonQuotaScaledUp, "Raised"
+			if next.Cmp(current) < 0 {
+				reason, verb = EventReasonQuotaScaledDown, "Lowered"
+			}
+			u.Recorder.Eventf(uc, corev1.EventTypeNormal, reason, "%s the %s quota of namespace %s from %s to %s at %d%% usage",
+				verb, res.Name, ns.Name, current.String(), next.String(), utilization)
+		}
+
+		if changed {
+			if err := u.Update(ctx, quota); err != nil {
+				return fmt.Errorf("failed to update ResourceQuota in namespace %s: %w", ns.Name, err)
+			}
+		}
+	}
+
+	uc.Status.QuotaAutoscale = states
+	if len(uc.Status.QuotaHistory) > maxQuotaHistory {
+		uc.Status.QuotaHistory = uc.Status.QuotaHistory[len(uc.Status.QuotaHistory)-maxQuotaHistory:]
+	}
+	return nil
+}
+
+// capToHeadroom bounds the growth of the quota resource name from current to
+// next by the budget headroom left, and consumes the headroom of the growth
+func capToHeadroom(ctx context.Context, headroom corev1.ResourceList, uc *myoperatorv1alpha1.UserConfig,
+	namespace string, name corev1.ResourceName, current, next resource.Quantity) resource.Quantity {
+	budgetName, ok := budgetResourceOf(name)
+	if !ok {
+		return next
+	}
+	left, ok := headroom[budgetName]
+	if !ok {
+		return next
+	}
+	growth := next.DeepCopy()
+	growth.Sub(current)
+	if growth.Cmp(left) > 0 {
+		log.FromContext(ctx).Info("Quota growth capped by the tenant budgets", "userConfig", uc.Name,
+			"namespace", namespace, "resource", name, "wanted", next.String(), "headroom", left.String())
+		growth = left
+		if growth.Sign() < 0 {
+			growth = resource.Quantity{}
+		}
+		next = current.DeepCopy()
+		next.Add(growth)
+	}
+	left.Sub(growth)
+	headroom[budgetName] = left
+	return next
+}
+
+// nextAutoscaledHard updates the pressure of state from the usage and returns
+// the hard limit to apply, which is current until the pressure lasted for the
+// stabilization window, along with the used percentage of current
+func nextAutoscaledHard(state *myoperatorv1alpha1.AutoscaledQuota, autoscale *myoperatorv1alpha1.QuotaAutoscale,
+	name string, current, used resource.Quantity, now time.Time) (resource.Quantity, int32) {
+	utilization := quotaUtilization(current, used)
+	pressure := ""
+	switch {
+	case utilization >= autoscale.ScaleUpThreshold:
+		pressure = quotaPressureHigh
+	case utilization <= autoscale.ScaleDownThreshold:
+		pressure = quotaPressureLow
+	}
+	if pressure != state.Pressure {
+		state.Pressure = pressure
+		state.PressureSince = nil
+		if pressure != "" {
+			state.PressureSince = &metav1.Time{Time: now}
+		}
+	}
+	if pressure == "" {
+		return current, utilization
+	}
+
+	window := autoscale.StabilizationWindow.Duration
+	if now.Sub(state.PressureSince.Time) < window {
+		return current, utilization
+	}
+	if state.LastScaleTime != nil && now.Sub(state.LastScaleTime.Time) < window {
+		return current, utilization
+	}
+
+	next := scaledQuantity(name, current, autoscale.StepPercent, pressure == quotaPressureHigh)
+	if pressure == quotaPressureLow && next.Cmp(used) < 0 {
+		// Never shrink below the current usage
+		next = used
+	}
+	return next, utilization
+}
@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+)
+
+var _ = Describe("quota autoscaling", func() {
+	var (
+		ctx      context.Context
+		u        *UserConfigUseCase
+		recorder *record.FakeRecorder
+		uc       *myoperatorv1alpha1.UserConfig
+		start    time.Time
+	)
+
+	// setUsage records used as the requests.cpu usage of the quota
+	setUsage := func(used string) {
+		quota := &corev1.ResourceQuota{}
+		Expect(u.Get(ctx, client.ObjectKey{Name: "alice", Namespace: "alice"}, quota)).To(Succeed())
+		quota.Status.Used = corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse(used)}
+		Expect(u.Status().Update(ctx, quota)).To(Succeed())
+	}
+
+	// autoscaleAt runs the autoscaler minutes after start and returns the requests.cpu hard limit
+	autoscaleAt := func(minutes int) string {
+		Expect(u.AutoscaleQuotas(ctx, uc, start.Add(time.Duration(minutes)*time.Minute))).To(Succeed())
+		quota := &corev1.ResourceQuota{}
+		Expect(u.Get(ctx, client.ObjectKey{Name: "alice", Namespace: "alice"}, quota)).To(Succeed())
+		hard := quota.Spec.Hard[corev1.ResourceRequestsCPU]
+		return hard.String()
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		uc = &myoperatorv1alpha1.UserConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "alice"},
+			Spec: myoperatorv1alpha1.UserConfigSpec{
+				ResourceQuotas: &myoperatorv1alpha1.ResourceQuota{
+					RequestsCPU: "2",
+					Autoscale: &myoperatorv1alpha1.QuotaAutoscale{
+						Resources:           []myoperatorv1alpha1.AutoscaledResource{{Name: "requests.cpu", Min: "1", Max: "4"}},
+						ScaleUpThreshold:    80,
+						ScaleDownThreshold:  30,
+						StepPercent:         50,
+						StabilizationWindow: &metav1.Duration{Duration: 10 * time.Minute},
+					},
+				},
+			},
+		}
+		quota := &corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "alice", Namespace: "alice"},
+			Spec:       corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("2")}},
+		}
+		u, recorder = newTestUseCase(uc, quota)
+	})
+
+	It("grows the limit once the usage stayed high for the stabilization window", func() {
+		setUsage("1900m")
+		Expect(autoscaleAt(0)).To(Equal("2"))
+		Expect(uc.Status.QuotaAutoscale).To(HaveLen(1))
+		Expect(uc.Status.QuotaAutoscale[0].Pressure).To(Equal("High"))
+		Expect(autoscaleAt(5)).To(Equal("2"))
+
+		Expect(autoscaleAt(10)).To(Equal("3"))
+		Expect(uc.Status.QuotaAutoscale[0].Hard).To(Equal("3"))
+		Expect(uc.Status.QuotaHistory).To(HaveLen(1))
+		Expect(uc.Status.QuotaHistory[0]).To(MatchFields(IgnoreExtras, Fields{
+			"Resource":    Equal("requests.cpu"),
+			"From":        Equal("2"),
+			"To":          Equal("3"),
+			"Utilization": BeEquivalentTo(95),
+		}))
+		Expect(recorder.Events).To(Receive(ContainSubstring("QuotaScaledUp Raised the requests.cpu quota of namespace alice from 2 to 3 at 95% usage")))
+
+		// 63% of the new limit is within the thresholds
+		Expect(autoscaleAt(30)).To(Equal("3"))
+		Expect(uc.Status.QuotaAutoscale[0].Pressure).To(BeEmpty())
+	})
+
+	It("keeps the limit within the bounds and above the usage", func() {
+		setUsage("1900m")
+		autoscaleAt(0)
+		Expect(autoscaleAt(10)).To(Equal("3"))
+		setUsage("2900m")
+		autoscaleAt(20)
+		Expect(autoscaleAt(30)).To(Equal("4"))
+
+		setUsage("1")
+		autoscaleAt(40)
+		Expect(autoscaleAt(50)).To(Equal("2"))
+		Expect(uc.Status.QuotaHistory).To(HaveLen(3))
+		Expect(recorder.Events).To(HaveLen(3))
+	})
+
+	It("keeps the autoscaled limit when the UserConfig is reconciled", func() {
+		setUsage("1900m")
+		autoscaleAt(0)
+		autoscaleAt(10)
+
+		spec, err := resourceQuotaSpec(uc.Spec.ResourceQuotas, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(applyAutoscaledHard(&spec, uc.Spec.ResourceQuotas.Autoscale, uc, "alice")).To(Succeed())
+		Expect(spec.Hard.Name(corev1.ResourceRequestsCPU, resource.DecimalSI).String()).To(Equal("3"))
+	})
+
+	It("counts the autoscaled limit in the tenant budgets and grows within their headroom", func() {
+		budget := &myoperatorv1alpha1.TenantBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Spec:       myoperatorv1alpha1.TenantBudgetSpec{CPU: "2500m"},
+		}
+		Expect(u.Create(ctx, budget)).To(Succeed())
+
+		setUsage("1900m")
+		autoscaleAt(0)
+		Expect(autoscaleAt(10)).To(Equal("2500m"))
+		Expect(uc.Status.QuotaAutoscale[0].Hard).To(Equal("2500m"))
+
+		quota, err := userConfigQuota(uc, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(quota.Cpu().String()).To(Equal("2500m"))
+
+		setUsage("2400m")
+		autoscaleAt(20)
+		Expect(autoscaleAt(30)).To(Equal("2500m"))
+	})
+})
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+	"01cloud/zoperator/internal/metrics"
+)
+
+// cpuQuotaKeys and memoryQuotaKeys are checked in order to pick the quota
+// resource reported in the CPU and Memory printer columns
+var (
+	cpuQuotaKeys    = []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceRequestsCPU, corev1.ResourceLimitsCPU}
+	memoryQuotaKeys = []corev1.ResourceName{corev1.ResourceMemory, corev1.ResourceRequestsMemory, corev1.ResourceLimitsMemory}
+)
+
+// ReconcileQuotaStatus mirrors the status of the managed ResourceQuotas into
+// the UserConfig status. The CPU and Memory columns report the primary
+// namespace. The caller is responsible for persisting the status.
+func (u *UserConfigUseCase) ReconcileQuotaStatus(ctx context.Context, uc *myoperatorv1alpha1.UserConfig) error {
+	var usages []myoperatorv1alpha1.ResourceQuotaUsage
+	var exhausted []string
+	for _, namespace := range NamespaceNames(uc) {
+		quota := &corev1.ResourceQuota{}
+		if err := u.Get(ctx, client.ObjectKey{Name: uc.Name, Namespace: namespace}, quota); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("failed to get ResourceQuota in namespace %s: %w", namespace, err)
+		}
+
+		metrics.SetQuotaUsage(uc.Name, quota.Namespace, quota.Status.Hard, quota.Status.Used)
+
+		for name, hard := range quota.Status.Hard {
+			used := quota.Status.Used[name]
+			utilization := quotaUtilization(hard, used)
+			usages = append(usages, myoperatorv1alpha1.ResourceQuotaUsage{
+				Namespace:   namespace,
+				Resource:    string(name),
+				Hard:        hard.String(),
+				Used:        used.String(),
+				Utilization: utilization,
+			})
+			if utilization >= u.Config.QuotaUsageThreshold {
+				exhausted = append(exhausted, fmt.Sprintf("%s/%s (%d%%)", namespace, name, utilization))
+			}
+		}
+
+		if namespace == uc.Name {
+			uc.Status.CPUUtilization = utilizationColumn(quota.Status, cpuQuotaKeys)
+			uc.Status.MemoryUtilization = utilizationColumn(quota.Status, memoryQuotaKeys)
+		}
+	}
+	if usages == nil {
+		return nil
+	}
+	sort.SliceStable(usages, func(i, j int) bool {
+		if usages[i].Namespace != usages[j].Namespace {
+			return usages[i].Namespace < usages[j].Namespace
+		}
+		return usages[i].Resource < usages[j].Resource
+	})
+	sort.Strings(exhausted)
+
+	uc.Status.Quota = usages
+
+	condition := metav1.Condition{
+		Type:               myoperatorv1alpha1.QuotaNearlyExhaustedCondition,
+		Status:             metav1.ConditionFalse,
+		Reason:             "WithinThreshold",
+		Message:            fmt.Sprintf("All quota resources are below %d%% usage", u.Config.QuotaUsageThreshold),
+		ObservedGeneration: uc.Generation,
+	}
+	if len(exhausted) > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "ThresholdExceeded"
+		condition.Message = fmt.Sprintf("Quota usage crossed %d%%: %s", u.Config.QuotaUsageThreshold, strings.Join(exhausted, ", "))
+	}
+	meta.SetStatusCondition(&uc.Status.Conditions, condition)
+
+	return nil
+}
+
+// quotaUtilization returns used as a percentage of hard
+func quotaUtilization(hard, used resource.Quantity) int32 {
+	if hard.IsZero() {
+		if used.IsZero() {
+			return 0
+		}
+		return 100
+	}
+	return int32(used.AsApproximateFloat64() * 100 / hard.AsApproximateFloat64())
+}
+
+// utilizationColumn formats the utilization of the first quota key present in status
+func utilizationColumn(status corev1.ResourceQuotaStatus, keys []corev1.ResourceName) string {
+	for _, key := range keys {
+		hard, ok := status.Hard[key]
+		if !ok {
+			continue
+		}
+		return fmt.Sprintf("%d%%", quotaUtilization(hard, status.Used[key]))
+	}
+	return ""
+}
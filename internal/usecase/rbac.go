@@ -3,6 +3,7 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"sort"
 	"strings"
 
@@ -10,10 +11,8 @@ import (
 	rbacv1 "k8s.io/api/rbac/v1"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
@@ -40,26 +39,32 @@ func (u *UserConfigUseCase) ReconcileRBAC(ctx context.Context, uc *myoperatorv1a
 }
 
 func (u *UserConfigUseCase) ReconcileRole(ctx context.Context, uc *myoperatorv1alpha1.UserConfig) error {
-	role := &rbacv1.Role{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      uc.Name,
-			Namespace: uc.Name,
-		},
-		Rules: []rbacv1.PolicyRule{},
+	// Strip or reject the rules allowing privilege escalation
+	rules, filtered, err := u.settings().RBACPolicy.Apply(uc.Spec.Identity.Groups, policyRules(uc.Spec.Permissions.Resources))
+	uc.Status.FilteredPermissions = filtered
+	if len(filtered) > 0 {
+		u.Recorder.Eventf(uc, corev1.EventTypeWarning, EventReasonPermissionsFiltered, "Permissions filtered by RBAC policy: %s", strings.Join(filtered, "; "))
+	}
+	if err != nil {
+		return err
 	}
 
-	// Map CRUD to Kubernetes verbs
-	for _, perm := range uc.Spec.Permissions.Resources {
-		rule := rbacv1.PolicyRule{
-			APIGroups: getAPIGroup(perm.Resource),
-			Resources: []string{mapActualResource(perm.Resource)},
-			Verbs:     mapCRUDToVerbs(perm.Operation),
+	for _, ns := range tenantNamespaces(uc) {
+		if err := u.reconcileRole(ctx, uc, ns.Name, rules); err != nil {
+			return err
 		}
-		role.Rules = append(role.Rules, rule)
+	}
+	return nil
+}
+
+func (u *UserConfigUseCase) reconcileRole(ctx context.Context, uc *myoperatorv1alpha1.UserConfig, namespace string, rules []rbacv1.PolicyRule) error {
+	role := &rbacv1.Role{
+		ObjectMeta: objectMeta(uc, uc.Name, namespace),
+		Rules:      rules,
 	}
 
 	// Set controller reference
-	if err := controllerutil.SetControllerReference(uc, role, u.Scheme); err != nil {
+	if err := u.setManagedMetadata(uc, role); err != nil {
 		return fmt.Errorf("failed to set role owner reference: %w", err)
 	}
 
@@ -71,14 +76,26 @@ func (u *UserConfigUseCase) ReconcileRole(ctx context.Context, uc *myoperatorv1a
 
 		// Get existing role for update
 		existing := &rbacv1.Role{}
-		if err := u.Get(ctx, client.ObjectKey{Name: uc.Name, Namespace: uc.Name}, existing); err != nil {
+		if err := u.Get(ctx, client.ObjectKey{Name: uc.Name, Namespace: namespace}, existing); err != nil {
 			return fmt.Errorf("failed to get existing role: %w", err)
 		}
 
-		existing.Rules = role.Rules
-		if err := u.Update(ctx, existing); err != nil {
-			return fmt.Errorf("failed to update role: %w", err)
+		metadataChanged, err := u.updateManagedMetadata(uc, existing)
+		if err != nil {
+			return fmt.Errorf("failed to set role owner reference: %w", err)
 		}
+		rulesChanged := !reflect.DeepEqual(existing.Rules, role.Rules)
+		if rulesChanged || metadataChanged {
+			existing.Rules = role.Rules
+			if err := u.Update(ctx, existing); err != nil {
+				return fmt.Errorf("failed to update role: %w", err)
+			}
+		}
+		if rulesChanged {
+			u.Recorder.Eventf(uc, corev1.EventTypeNormal, EventReasonRoleUpdated, "Updated Role %s/%s", existing.Namespace, existing.Name)
+		}
+	} else {
+		u.Recorder.Eventf(uc, corev1.EventTypeNormal, EventReasonRoleCreated, "Created Role %s/%s", role.Namespace, role.Name)
 	}
 
 	return nil
@@ -86,18 +103,11 @@ func (u *UserConfigUseCase) ReconcileRole(ctx context.Context, uc *myoperatorv1a
 
 func (u *UserConfigUseCase) ReconcileServiceAccount(ctx context.Context, uc *myoperatorv1alpha1.UserConfig) error {
 	sa := &corev1.ServiceAccount{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      uc.Name,
-			Namespace: uc.Name,
-			Labels: map[string]string{
-				"app.kubernetes.io/managed-by":          "userconfig-operator",
-				"userconfig.myoperator.01cloud.io/name": uc.Name,
-			},
-		},
+		ObjectMeta: objectMeta(uc, uc.Name, uc.Name),
 	}
 
 	// Set controller reference
-	if err := controllerutil.SetControllerReference(uc, sa, u.Scheme); err != nil {
+	if err := u.setManagedMetadata(uc, sa); err != nil {
 		return fmt.Errorf("failed to set serviceaccount owner reference: %w", err)
 	}
 
@@ -114,38 +124,39 @@ func (u *UserConfigUseCase) ReconcileServiceAccount(ctx context.Context, uc *myo
 		}
 
 		// Update labels if needed
-		existing.Labels = sa.Labels
-		if err := u.Update(ctx, existing); err != nil {
-			return fmt.Errorf("failed to update serviceaccount: %w", err)
+		changed, err := u.updateManagedMetadata(uc, existing)
+		if err != nil {
+			return fmt.Errorf("failed to set serviceaccount owner reference: %w", err)
 		}
+		if changed {
+			if err := u.Update(ctx, existing); err != nil {
+				return fmt.Errorf("failed to update serviceaccount: %w", err)
+			}
+		}
+	} else {
+		u.Recorder.Eventf(uc, corev1.EventTypeNormal, EventReasonServiceAccountCreated, "Created ServiceAccount %s/%s", sa.Namespace, sa.Name)
 	}
 
 	return nil
 }
 
 func (u *UserConfigUseCase) ReconcileRoleBinding(ctx context.Context, uc *myoperatorv1alpha1.UserConfig) error {
-	subjects := []rbacv1.Subject{
-		{
-			Kind: "User",
-			Name: uc.Name,
-		},
-		{
-			Kind:      "ServiceAccount",
-			Name:      uc.Name,
-			Namespace: uc.Name,
-		},
+	for _, ns := range tenantNamespaces(uc) {
+		if err := u.reconcileRoleBinding(ctx, uc, ns.Name); err != nil {
+			return err
+		}
 	}
+	return nil
+}
+
+// reconcileRoleBinding binds the user and the ServiceAccount of the primary
+// namespace to the Role in namespace
+func (u *UserConfigUseCase) reconcileRoleBinding(ctx context.Context, uc *myoperatorv1alpha1.UserConfig, namespace string) error {
+	subjects := userSubjects(uc)
 
 	roleBinding := &rbacv1.RoleBinding{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      uc.Name,
-			Namespace: uc.Name,
-			Labels: map[string]string{
-				"app.kubernetes.io/managed-by":          "userconfig-operator",
-				"userconfig.myoperator.01cloud.io/name": uc.Name,
-			},
-		},
-		Subjects: subjects,
+		ObjectMeta: objectMeta(uc, uc.Name, namespace),
+		Subjects:   subjects,
 		RoleRef: rbacv1.RoleRef{
 			Kind:     "Role",
 			Name:     uc.Name,
@@ -154,7 +165,7 @@ func (u *UserConfigUseCase) ReconcileRoleBinding(ctx context.Context, uc *myoper
 	}
 
 	// Set controller reference
-	if err := controllerutil.SetControllerReference(uc, roleBinding, u.Scheme); err != nil {
+	if err := u.setManagedMetadata(uc, roleBinding); err != nil {
 		return fmt.Errorf("failed to set rolebinding owner reference: %w", err)
 	}
 
@@ -166,22 +177,61 @@ func (u *UserConfigUseCase) ReconcileRoleBinding(ctx context.Context, uc *myoper
 
 		// Get existing RoleBinding for update
 		existing := &rbacv1.RoleBinding{}
-		if err := u.Get(ctx, client.ObjectKey{Name: uc.Name, Namespace: uc.Name}, existing); err != nil {
+		if err := u.Get(ctx, client.ObjectKey{Name: uc.Name, Namespace: namespace}, existing); err != nil {
 			return fmt.Errorf("failed to get existing rolebinding: %w", err)
 		}
 
 		// Update subjects and roleRef
+		changed := !reflect.DeepEqual(existing.Subjects, subjects) || existing.RoleRef != roleBinding.RoleRef
 		existing.Subjects = subjects
 		existing.RoleRef = roleBinding.RoleRef
-		existing.Labels = roleBinding.Labels
+		if err := u.setManagedMetadata(uc, existing); err != nil {
+			return fmt.Errorf("failed to set rolebinding owner reference: %w", err)
+		}
 		if err := u.Update(ctx, existing); err != nil {
 			return fmt.Errorf("failed to update rolebinding: %w", err)
 		}
+		if changed {
+			u.Recorder.Eventf(uc, corev1.EventTypeNormal, EventReasonRoleBindingUpdated, "Updated RoleBinding %s/%s", existing.Namespace, existing.Name)
+		}
+	} else {
+		u.Recorder.Eventf(uc, corev1.EventTypeNormal, EventReasonRoleBindingCreated, "Created RoleBinding %s/%s", roleBinding.Namespace, roleBinding.Name)
 	}
 
 	return nil
 }
 
+// userSubjects returns the user of uc and the ServiceAccount of its primary namespace
+func userSubjects(uc *myoperatorv1alpha1.UserConfig) []rbacv1.Subject {
+	return []rbacv1.Subject{
+		{
+			Kind: "User",
+			Name: uc.Name,
+		},
+		{
+			Kind:      "ServiceAccount",
+			Name:      uc.Name,
+			Namespace: uc.Name,
+		},
+	}
+}
+
+// policyRules maps resource permissions to Role rules
+func policyRules(permissions []myoperatorv1alpha1.ResourcePermission) []rbacv1.PolicyRule {
+	rules := []rbacv1.PolicyRule{}
+
+	// Map CRUD to Kubernetes verbs
+	for _, perm := range permissions {
+		rule := rbacv1.PolicyRule{
+			APIGroups: getAPIGroup(perm.Resource),
+			Resources: []string{mapActualResource(perm.Resource)},
+			Verbs:     mapCRUDToVerbs(perm.Operation),
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
 func getAPIGroup(resource string) []string {
 	switch resource {
 	case "deployments", "deployment", "replicasets", "replicaset", "statefulsets", "statefulset", "daemonsets", "daemonset", "scaledeployment", "scalereplicaset":
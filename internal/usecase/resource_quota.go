@@ -2,16 +2,14 @@ package usecase
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
 
-	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
-
 	corev1 "k8s.io/api/core/v1"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -19,108 +17,224 @@ import (
 )
 
 func (u *UserConfigUseCase) ReconcileResourceQuota(ctx context.Context, userConfig *myoperatorv1alpha1.UserConfig) error {
-	resourceQuota := &corev1.ResourceQuota{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      userConfig.Name,
-			Namespace: userConfig.Name,
-		},
+	for _, ns := range tenantNamespaces(userConfig) {
+		if err := u.reconcileResourceQuota(ctx, userConfig, ns); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	// Check if a custom ResourceQuota is specified in the UserConfig
-	if userConfig.Spec.ResourceQuotas != nil {
-		// Convert the custom ResourceQuota struct to ResourceQuota spec
-		hardLimits := make(corev1.ResourceList)
-
-		rq := userConfig.Spec.ResourceQuotas
-
-		// Add each field from the struct to the hardLimits map if it's not empty
-		if rq.CPU != "" {
-			hardLimits[corev1.ResourceName("cpu")] = resource.MustParse(rq.CPU)
-		}
-		if rq.Memory != "" {
-			hardLimits[corev1.ResourceName("memory")] = resource.MustParse(rq.Memory)
-		}
-		if rq.EphemeralStorage != "" {
-			hardLimits[corev1.ResourceName("ephemeral-storage")] = resource.MustParse(rq.EphemeralStorage)
-		}
-		if rq.RequestsCPU != "" {
-			hardLimits[corev1.ResourceName("requests.cpu")] = resource.MustParse(rq.RequestsCPU)
-		}
-		if rq.RequestsMemory != "" {
-			hardLimits[corev1.ResourceName("requests.memory")] = resource.MustParse(rq.RequestsMemory)
-		}
-		if rq.RequestsStorage != "" {
-			hardLimits[corev1.ResourceName("requests.storage")] = resource.MustParse(rq.RequestsStorage)
-		}
-		if rq.RequestsEphemeralStorage != "" {
-			hardLimits[corev1.ResourceName("requests.ephemeral-storage")] = resource.MustParse(rq.RequestsEphemeralStorage)
-		}
-		if rq.LimitsCPU != "" {
-			hardLimits[corev1.ResourceName("limits.cpu")] = resource.MustParse(rq.LimitsCPU)
-		}
-		if rq.LimitsMemory != "" {
-			hardLimits[corev1.ResourceName("limits.memory")] = resource.MustParse(rq.LimitsMemory)
-		}
-		if rq.LimitsEphemeralStorage != "" {
-			hardLimits[corev1.ResourceName("limits.ephemeral-storage")] = resource.MustParse(rq.LimitsEphemeralStorage)
-		}
-		if rq.Pods != "" {
-			hardLimits[corev1.ResourceName("pods")] = resource.MustParse(rq.Pods)
-		}
-		if rq.Services != "" {
-			hardLimits[corev1.ResourceName("services")] = resource.MustParse(rq.Services)
-		}
-		if rq.ReplicationControllers != "" {
-			hardLimits[corev1.ResourceName("replicationcontrollers")] = resource.MustParse(rq.ReplicationControllers)
-		}
-		if rq.Secrets != "" {
-			hardLimits[corev1.ResourceName("secrets")] = resource.MustParse(rq.Secrets)
-		}
-		if rq.ConfigMaps != "" {
-			hardLimits[corev1.ResourceName("configmaps")] = resource.MustParse(rq.ConfigMaps)
-		}
-		if rq.PersistentVolumeClaims != "" {
-			hardLimits[corev1.ResourceName("persistentvolumeclaims")] = resource.MustParse(rq.PersistentVolumeClaims)
-		}
-		if rq.ServicesNodePorts != "" {
-			hardLimits[corev1.ResourceName("services.nodeports")] = resource.MustParse(rq.ServicesNodePorts)
+// reconcileResourceQuota applies the main quota named after the UserConfig and
+// the additional quotas of ns, deleting the additional quotas no longer listed
+func (u *UserConfigUseCase) reconcileResourceQuota(ctx context.Context, userConfig *myoperatorv1alpha1.UserConfig, ns tenantNamespace) error {
+	spec, err := resourceQuotaSpec(ns.ResourceQuotas, u.Defaults.Spec().DefaultResourceQuota)
+	if err != nil {
+		return fmt.Errorf("invalid ResourceQuota of namespace %s: %w", ns.Name, err)
+	}
+	if ns.ResourceQuotas != nil {
+		// Keep the hard limits set by the quota autoscaler
+		if err := applyAutoscaledHard(&spec, ns.ResourceQuotas.Autoscale, userConfig, ns.Name); err != nil {
+			return fmt.Errorf("invalid ResourceQuota of namespace %s: %w", ns.Name, err)
 		}
-		if rq.ServicesLoadBalancers != "" {
-			hardLimits[corev1.ResourceName("services.loadbalancers")] = resource.MustParse(rq.ServicesLoadBalancers)
+	}
+	desired := map[string]bool{userConfig.Name: true}
+	if err := u.applyResourceQuota(ctx, userConfig, ns.Name, userConfig.Name, spec); err != nil {
+		return err
+	}
+
+	if ns.ResourceQuotas != nil {
+		for _, additional := range ns.ResourceQuotas.Additional {
+			name := fmt.Sprintf("%s-%s", userConfig.Name, additional.Name)
+			spec, err := scopedResourceQuotaSpec(additional.Hard, additional.Scopes, additional.ScopeSelector)
+			if err != nil {
+				return fmt.Errorf("invalid ResourceQuota %s of namespace %s: %w", name, ns.Name, err)
+			}
+			desired[name] = true
+			if err := u.applyResourceQuota(ctx, userConfig, ns.Name, name, spec); err != nil {
+				return err
+			}
 		}
+	}
 
-		// Set the resource quota specification
-		resourceQuota.Spec = corev1.ResourceQuotaSpec{
-			Hard: hardLimits,
+	quotas := &corev1.ResourceQuotaList{}
+	if err := u.List(ctx, quotas, client.InNamespace(ns.Name), client.MatchingLabels(managedLabels(userConfig))); err != nil {
+		return fmt.Errorf("failed to list ResourceQuotas in namespace %s: %w", ns.Name, err)
+	}
+	for i := range quotas.Items {
+		if desired[quotas.Items[i].Name] {
+			continue
 		}
-	} else {
-		// Attach the default ResourceQuota if none is specified in the UserConfig
-		resourceQuota.Spec = corev1.ResourceQuotaSpec{
-			Hard: corev1.ResourceList{
-				corev1.ResourcePods:   resource.MustParse("10"),
-				corev1.ResourceCPU:    resource.MustParse("2"),
-				corev1.ResourceMemory: resource.MustParse("4Gi"),
-			},
+		if err := u.Delete(ctx, &quotas.Items[i]); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete ResourceQuota %s in namespace %s: %w", quotas.Items[i].Name, ns.Name, err)
 		}
 	}
 
+	return nil
+}
+
+// applyResourceQuota creates or updates the ResourceQuota name in namespace with spec
+func (u *UserConfigUseCase) applyResourceQuota(ctx context.Context, userConfig *myoperatorv1alpha1.UserConfig, namespace, name string, spec corev1.ResourceQuotaSpec) error {
+	resourceQuota := &corev1.ResourceQuota{
+		ObjectMeta: objectMeta(userConfig, name, namespace),
+		Spec:       spec,
+	}
+
+	// Set controller reference
+	if err := u.setManagedMetadata(userConfig, resourceQuota); err != nil {
+		return err
+	}
+
 	existingQuota := &corev1.ResourceQuota{}
-	err := u.Client.Get(ctx, client.ObjectKey{Name: userConfig.Name, Namespace: userConfig.Name}, existingQuota)
+	err := u.Client.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, existingQuota)
 	if err != nil && apierrors.IsNotFound(err) {
 		// ResourceQuota doesn't exist, create it
 		if err := u.Client.Create(ctx, resourceQuota); err != nil {
-			return fmt.Errorf("failed to create ResourceQuota in namespace %s: %w", userConfig.Name, err)
+			return fmt.Errorf("failed to create ResourceQuota %s in namespace %s: %w", name, namespace, err)
 		}
+		u.Recorder.Eventf(userConfig, corev1.EventTypeNormal, EventReasonResourceQuotaCreated, "Created ResourceQuota %s in namespace %s", name, namespace)
 	} else if err != nil {
-		return fmt.Errorf("failed to get ResourceQuota in namespace %s: %w", userConfig.Name, err)
+		return fmt.Errorf("failed to get ResourceQuota %s in namespace %s: %w", name, namespace, err)
 	} else {
 		// ResourceQuota exists, check if it needs to be updated
-		if !reflect.DeepEqual(existingQuota.Spec, resourceQuota.Spec) {
+		metadataChanged, err := u.updateManagedMetadata(userConfig, existingQuota)
+		if err != nil {
+			return err
+		}
+		specChanged := !reflect.DeepEqual(existingQuota.Spec, resourceQuota.Spec)
+		if specChanged || metadataChanged {
 			existingQuota.Spec = resourceQuota.Spec
 			if err := u.Client.Update(ctx, existingQuota); err != nil {
-				return fmt.Errorf("failed to update ResourceQuota in namespace %s: %w", userConfig.Name, err)
+				return fmt.Errorf("failed to update ResourceQuota %s in namespace %s: %w", name, namespace, err)
 			}
 		}
+		if specChanged {
+			u.Recorder.Eventf(userConfig, corev1.EventTypeNormal, EventReasonResourceQuotaUpdated, "Updated ResourceQuota %s in namespace %s", name, namespace)
+		}
+	}
+	return nil
+}
+
+// resourceQuotaSpec converts the main quota of rq into a ResourceQuota spec,
+// falling back to defaults, the OperatorConfig default quota, when rq is nil
+// and to the built-in default quota when both are
+func resourceQuotaSpec(rq, defaults *myoperatorv1alpha1.ResourceQuota) (corev1.ResourceQuotaSpec, error) {
+	if rq == nil {
+		rq = defaults
+	}
+	if rq == nil {
+		// Attach the default ResourceQuota if none is specified
+		return corev1.ResourceQuotaSpec{
+			Hard: corev1.ResourceList{
+				corev1.ResourcePods:   resource.MustParse("10"),
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+		}, nil
+	}
+
+	// The generic hard limits win over the typed fields
+	hard := TypedQuotaHard(rq)
+	for name, value := range rq.Hard {
+		hard[name] = value
+	}
+	return scopedResourceQuotaSpec(hard, rq.Scopes, rq.ScopeSelector)
+}
+
+// TypedQuotaHard returns the typed quota fields set in rq keyed by their quota
+// resource name, which is the JSON name of the field. The deprecated
+// requests.configmaps spelling is returned as configmaps.
+func TypedQuotaHard(rq *myoperatorv1alpha1.ResourceQuota) map[string]string {
+	hard := map[string]string{}
+	if rq == nil {
+		return hard
+	}
+	data, err := json.Marshal(rq)
+	if err != nil {
+		return hard
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return hard
+	}
+	for name, raw := range fields {
+		// Only the typed fields are strings, hard, scopes and the rest are skipped
+		var value string
+		if json.Unmarshal(raw, &value) == nil {
+			hard[name] = value
+		}
 	}
+
+	if value, ok := hard[legacyConfigMapsQuota]; ok {
+		delete(hard, legacyConfigMapsQuota)
+		if _, ok := hard[string(corev1.ResourceConfigMaps)]; !ok {
+			hard[string(corev1.ResourceConfigMaps)] = value
+		}
+	}
+	return hard
+}
+
+// legacyConfigMapsQuota is the former JSON name of the configmaps quota field
+const legacyConfigMapsQuota = "requests.configmaps"
+
+// normalizeResourceQuota moves the deprecated configmaps spelling of rq to the
+// configmaps field, keeping configmaps when both are set. It reports whether rq changed.
+func normalizeResourceQuota(rq *myoperatorv1alpha1.ResourceQuota) bool {
+	if rq == nil || rq.DeprecatedConfigMaps == "" {
+		return false
+	}
+	if rq.ConfigMaps == "" {
+		rq.ConfigMaps = rq.DeprecatedConfigMaps
+	}
+	rq.DeprecatedConfigMaps = ""
+	return true
+}
+
+// migrateResourceQuotas normalizes quotas, which belong to the spec of obj, and
+// writes obj back when one of them used a deprecated spelling
+func (u *UserConfigUseCase) migrateResourceQuotas(ctx context.Context, obj client.Object, quotas ...*myoperatorv1alpha1.ResourceQuota) error {
+	changed := false
+	for _, quota := range quotas {
+		if normalizeResourceQuota(quota) {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	if err := u.Client.Update(ctx, obj); err != nil {
+		return fmt.Errorf("failed to migrate ResourceQuota of %s: %w", obj.GetName(), err)
+	}
+	u.Recorder.Eventf(obj, corev1.EventTypeNormal, EventReasonResourceQuotaMigrated,
+		"Moved the deprecated %s quota to %s", legacyConfigMapsQuota, corev1.ResourceConfigMaps)
 	return nil
 }
+
+// MigrateResourceQuotas rewrites the stored quotas of userConfig that still use
+// the deprecated requests.configmaps spelling
+func (u *UserConfigUseCase) MigrateResourceQuotas(ctx context.Context, userConfig *myoperatorv1alpha1.UserConfig) error {
+	quotas := []*myoperatorv1alpha1.ResourceQuota{userConfig.Spec.ResourceQuotas}
+	for i := range userConfig.Spec.Namespaces {
+		quotas = append(quotas, userConfig.Spec.Namespaces[i].ResourceQuotas)
+	}
+	return u.migrateResourceQuotas(ctx, userConfig, quotas...)
+}
+
+// scopedResourceQuotaSpec builds a ResourceQuota spec from generic hard limits and scopes
+func scopedResourceQuotaSpec(hard map[string]string, scopes []myoperatorv1alpha1.QuotaScope, scopeSelector *corev1.ScopeSelector) (corev1.ResourceQuotaSpec, error) {
+	spec := corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{}}
+	for name, value := range hard {
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return spec, fmt.Errorf("invalid quantity %q of %s: %w", value, name, err)
+		}
+		spec.Hard[corev1.ResourceName(name)] = quantity
+	}
+	for _, scope := range scopes {
+		spec.Scopes = append(spec.Scopes, corev1.ResourceQuotaScope(scope))
+	}
+	if scopeSelector != nil {
+		spec.ScopeSelector = scopeSelector.DeepCopy()
+	}
+	return spec, nil
+}
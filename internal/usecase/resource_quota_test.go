@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+)
+
+var _ = Describe("resource quotas", func() {
+	It("names the typed fields after their quota resource", func() {
+		spec, err := resourceQuotaSpec(&myoperatorv1alpha1.ResourceQuota{
+			CPU:            "500m",
+			RequestsMemory: "1.5Gi",
+			ConfigMaps:     "20",
+			Hard:           map[string]string{"requests.nvidia.com/gpu": "2", "cpu": "1"},
+		}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spec.Hard).To(Equal(corev1.ResourceList{
+			corev1.ResourceCPU:            resource.MustParse("1"),
+			corev1.ResourceRequestsMemory: resource.MustParse("1.5Gi"),
+			corev1.ResourceConfigMaps:     resource.MustParse("20"),
+			"requests.nvidia.com/gpu":     resource.MustParse("2"),
+		}))
+	})
+
+	It("reports invalid quantities instead of panicking", func() {
+		_, err := resourceQuotaSpec(&myoperatorv1alpha1.ResourceQuota{Memory: "lots"}, nil)
+		Expect(err).To(MatchError(ContainSubstring(`invalid quantity "lots" of memory`)))
+	})
+
+	It("accepts the deprecated configmaps spelling", func() {
+		rq := &myoperatorv1alpha1.ResourceQuota{DeprecatedConfigMaps: "20"}
+		Expect(TypedQuotaHard(rq)).To(Equal(map[string]string{"configmaps": "20"}))
+
+		Expect(normalizeResourceQuota(rq)).To(BeTrue())
+		Expect(rq.ConfigMaps).To(Equal("20"))
+		Expect(rq.DeprecatedConfigMaps).To(BeEmpty())
+		Expect(normalizeResourceQuota(rq)).To(BeFalse())
+	})
+
+	It("keeps configmaps when both spellings are set", func() {
+		rq := &myoperatorv1alpha1.ResourceQuota{ConfigMaps: "10", DeprecatedConfigMaps: "20"}
+		Expect(TypedQuotaHard(rq)).To(Equal(map[string]string{"configmaps": "10"}))
+		Expect(normalizeResourceQuota(rq)).To(BeTrue())
+		Expect(rq.ConfigMaps).To(Equal("10"))
+	})
+
+	It("lets the override win over a template whatever the spelling", func() {
+		merged, err := mergeResourceQuota(
+			&myoperatorv1alpha1.ResourceQuota{ConfigMaps: "20", Pods: "10"},
+			&myoperatorv1alpha1.ResourceQuota{DeprecatedConfigMaps: "5"},
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(TypedQuotaHard(merged)).To(Equal(map[string]string{"configmaps": "5", "pods": "10"}))
+	})
+})
@@ -4,8 +4,6 @@ import (
 	"context"
 	"fmt"
 
-	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
-
 	sealedsecretsv1alpha1 "github.com/bitnami-labs/sealed-secrets/pkg/apis/sealedsecrets/v1alpha1"
 
 	corev1 "k8s.io/api/core/v1"
@@ -14,10 +12,10 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+	"01cloud/zoperator/internal/metrics"
 )
 
 func (u *UserConfigUseCase) ReconcileSealedSecrets(ctx context.Context, uc *myoperatorv1alpha1.UserConfig) error {
@@ -34,16 +32,14 @@ func (u *UserConfigUseCase) ReconcileSealedSecrets(ctx context.Context, uc *myop
 		}
 
 		sealedSecret := &sealedsecretsv1alpha1.SealedSecret{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      secret.Name,
-				Namespace: uc.Name,
-			},
+			ObjectMeta: objectMeta(uc, secret.Name, uc.Name),
 			Spec: sealedsecretsv1alpha1.SealedSecretSpec{
 				EncryptedData: secret.SealedSecret.EncryptedData,
 				Template: sealedsecretsv1alpha1.SecretTemplateSpec{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      secret.Name,
 						Namespace: uc.Name,
+						Labels:    managedLabels(uc),
 					},
 					Type: corev1.SecretTypeOpaque,
 				},
@@ -51,7 +47,7 @@ func (u *UserConfigUseCase) ReconcileSealedSecrets(ctx context.Context, uc *myop
 		}
 
 		// Set ownership reference
-		if err := controllerutil.SetControllerReference(uc, sealedSecret, u.Scheme); err != nil {
+		if err := u.setManagedMetadata(uc, sealedSecret); err != nil {
 			return fmt.Errorf("failed to set controller reference for SealedSecret %s: %w", secret.Name, err)
 		}
 
@@ -60,18 +56,27 @@ func (u *UserConfigUseCase) ReconcileSealedSecrets(ctx context.Context, uc *myop
 		if err := u.Get(ctx, client.ObjectKey{Name: secret.Name, Namespace: uc.Name}, existing); err != nil {
 			if apierrors.IsNotFound(err) {
 				if err := u.Create(ctx, sealedSecret); err != nil {
+					metrics.IncSecretSyncFailure(secret.Type)
+					u.Recorder.Eventf(uc, corev1.EventTypeWarning, EventReasonSecretSyncFailed, "Failed to create SealedSecret %s: %v", secret.Name, err)
 					return fmt.Errorf("failed to create SealedSecret %s: %w", secret.Name, err)
 				}
 				log.Info("Created SealedSecret", "name", secret.Name)
+				u.Recorder.Eventf(uc, corev1.EventTypeNormal, EventReasonSecretSynced, "Created SealedSecret %s/%s", uc.Name, secret.Name)
 			} else {
 				return fmt.Errorf("failed to get existing SealedSecret %s: %w", secret.Name, err)
 			}
 		} else {
 			existing.Spec = sealedSecret.Spec
+			if err := u.setManagedMetadata(uc, existing); err != nil {
+				return fmt.Errorf("failed to set controller reference for SealedSecret %s: %w", secret.Name, err)
+			}
 			if err := u.Update(ctx, existing); err != nil {
+				metrics.IncSecretSyncFailure(secret.Type)
+				u.Recorder.Eventf(uc, corev1.EventTypeWarning, EventReasonSecretSyncFailed, "Failed to update SealedSecret %s: %v", secret.Name, err)
 				return fmt.Errorf("failed to update SealedSecret %s: %w", secret.Name, err)
 			}
 			log.Info("Updated SealedSecret", "name", secret.Name)
+			u.Recorder.Eventf(uc, corev1.EventTypeNormal, EventReasonSecretSynced, "Updated SealedSecret %s/%s", uc.Name, secret.Name)
 		}
 	}
 
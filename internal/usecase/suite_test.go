@@ -0,0 +1,45 @@
+package usecase
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+)
+
+func TestUseCase(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "UseCase Suite")
+}
+
+// testScheme holds the built-in and operator types served by the fake clients
+var testScheme = func() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(myoperatorv1alpha1.AddToScheme(scheme))
+	return scheme
+}()
+
+// newTestUseCase returns a UserConfigUseCase with the default Config on a fake
+// client seeded with objs, along with the recorder collecting its events. Like
+// the API server, the fake client only writes the status of the types with a
+// status subresource through Status().
+func newTestUseCase(objs ...client.Object) (*UserConfigUseCase, *record.FakeRecorder) {
+	c := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(objs...).
+		WithStatusSubresource(&corev1.ResourceQuota{}, &myoperatorv1alpha1.UserConfig{}, &myoperatorv1alpha1.Team{}, &myoperatorv1alpha1.TenantBudget{}).
+		Build()
+	recorder := record.NewFakeRecorder(100)
+	return &UserConfigUseCase{Client: c, Scheme: testScheme, Recorder: recorder, Config: DefaultConfig()}, recorder
+}
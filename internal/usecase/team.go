@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+)
+
+// TeamNamespace returns the name of the shared namespace of team
+func TeamNamespace(team *myoperatorv1alpha1.Team) string {
+	if team.Spec.Namespace != "" {
+		return team.Spec.Namespace
+	}
+	return team.Name
+}
+
+// teamLabels returns the labels identifying objects managed for team
+func teamLabels(team *myoperatorv1alpha1.Team) map[string]string {
+	return map[string]string{
+		LabelManagedBy: ManagedByValue,
+		LabelTeamName:  team.Name,
+	}
+}
+
+// ReconcileTeam provisions the shared namespace of team with its ResourceQuota,
+// LimitRange and, for every team role, a Role and a RoleBinding holding the
+// members given that role. Members whose UserConfig or role doesn't exist are
+// reported in the status. The caller is responsible for persisting the status.
+func (u *UserConfigUseCase) ReconcileTeam(ctx context.Context, team *myoperatorv1alpha1.Team) error {
+	namespace := TeamNamespace(team)
+
+	if err := u.migrateResourceQuotas(ctx, team, team.Spec.ResourceQuotas); err != nil {
+		return err
+	}
+
+	if err := u.checkTeamNamespace(ctx, team, namespace); err != nil {
+		return err
+	}
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+	podSecurityLabels := u.podSecurityLabels(nil)
+	result, err := u.applyTeamObject(ctx, team, ns, func() { ns.Labels = mergeStringMaps(ns.Labels, podSecurityLabels) })
+	if err != nil {
+		return fmt.Errorf("failed to reconcile namespace %s: %w", namespace, err)
+	}
+	if result == controllerutil.OperationResultCreated {
+		u.Recorder.Eventf(team, corev1.EventTypeNormal, EventReasonNamespaceCreated, "Created namespace %s", namespace)
+	}
+
+	quota := &corev1.ResourceQuota{ObjectMeta: metav1.ObjectMeta{Name: team.Name, Namespace: namespace}}
+	quotaSpec, err := resourceQuotaSpec(team.Spec.ResourceQuotas, u.Defaults.Spec().DefaultResourceQuota)
+	if err != nil {
+		return fmt.Errorf("invalid ResourceQuota of team %s: %w", team.Name, err)
+	}
+	if _, err := u.applyTeamObject(ctx, team, quota, func() { quota.Spec = quotaSpec }); err != nil {
+		return fmt.Errorf("failed to reconcile ResourceQuota in namespace %s: %w", namespace, err)
+	}
+
+	limitRange := &corev1.LimitRange{ObjectMeta: metav1.ObjectMeta{Name: team.Name, Namespace: namespace}}
+	limitSpec, err := limitRangeSpec(team.Spec.LimitRange, u.Defaults.Spec().DefaultLimitRange)
+	if err != nil {
+		return fmt.Errorf("invalid LimitRange of team %s: %w", team.Name, err)
+	}
+	if _, err := u.applyTeamObject(ctx, team, limitRange, func() { limitRange.Spec = limitSpec }); err != nil {
+		return fmt.Errorf("failed to reconcile LimitRange in namespace %s: %w", namespace, err)
+	}
+
+	// Group the member subjects by role
+	subjects, missing, err := u.teamSubjects(ctx, team)
+	if err != nil {
+		return err
+	}
+
+	policy := u.settings().RBACPolicy
+	desired := map[string]bool{}
+	var filtered []string
+	for _, teamRole := range team.Spec.Roles {
+		name := fmt.Sprintf("%s-%s", team.Name, teamRole.Name)
+		desired[name] = true
+
+		// Team roles have no identity groups, so the RBAC policy always applies
+		rules, removed, err := policy.Apply(nil, policyRules(teamRole.Resources))
+		for _, r := range removed {
+			filtered = append(filtered, fmt.Sprintf("%s/%s", teamRole.Name, r))
+		}
+		if err != nil {
+			team.Status.FilteredPermissions = filtered
+			return fmt.Errorf("role %s: %w", teamRole.Name, err)
+		}
+
+		role := &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+		if _, err := u.applyTeamObject(ctx, team, role, func() { role.Rules = rules }); err != nil {
+			return fmt.Errorf("failed to reconcile role %s: %w", name, err)
+		}
+
+		roleBinding := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+		if _, err := u.applyTeamObject(ctx, team, roleBinding, func() {
+			roleBinding.Subjects = subjects[teamRole.Name]
+			roleBinding.RoleRef = rbacv1.RoleRef{
+				Kind:     "Role",
+				Name:     name,
+				APIGroup: "rbac.authorization.k8s.io",
+			}
+		}); err != nil {
+			return fmt.Errorf("failed to reconcile rolebinding %s: %w", name, err)
+		}
+	}
+
+	// Remove the Roles and RoleBindings of roles dropped from the spec
+	if err := u.pruneTeamRBAC(ctx, team, namespace, desired); err != nil {
+		return err
+	}
+
+	if len(filtered) > 0 {
+		u.Recorder.Eventf(team, corev1.EventTypeWarning, EventReasonPermissionsFiltered, "Permissions filtered by RBAC policy: %s", strings.Join(filtered, "; "))
+	}
+	team.Status.FilteredPermissions = filtered
+
+	for _, member := range missing {
+		u.Recorder.Eventf(team, corev1.EventTypeWarning, EventReasonTeamMemberMissing, "Team member %s was not bound", member)
+	}
+	team.Status.Namespace = namespace
+	team.Status.Members = int32(len(team.Spec.Members) - len(missing))
+	team.Status.MissingMembers = missing
+
+	return nil
+}
+
+// checkTeamNamespace refuses a protected namespace or an existing namespace
+// the operator didn't create for team, which it would otherwise take over and
+// garbage collect along with the Team
+func (u *UserConfigUseCase) checkTeamNamespace(ctx context.Context, team *myoperatorv1alpha1.Team, namespace string) error {
+	if isProtectedNamespace(u.Defaults.Spec(), namespace) {
+		return fmt.Errorf("namespace %s is protected by OperatorConfig %s", namespace, myoperatorv1alpha1.OperatorConfigName)
+	}
+
+	existing := &corev1.Namespace{}
+	if err := u.Get(ctx, client.ObjectKey{Name: namespace}, existing); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get namespace %s: %w", namespace, err)
+	}
+	if existing.Labels[LabelTeamName] != team.Name || !metav1.IsControlledBy(existing, team) {
+		return fmt.Errorf("namespace %s already exists and isn't managed for team %s", namespace, team.Name)
+	}
+	return nil
+}
+
+// teamSubjects returns the subjects of every member keyed by role name and the
+// members that couldn't be resolved
+func (u *UserConfigUseCase) teamSubjects(ctx context.Context, team *myoperatorv1alpha1.Team) (map[string][]rbacv1.Subject, []string, error) {
+	roles := map[string]bool{}
+	for _, role := range team.Spec.Roles {
+		roles[role.Name] = true
+	}
+
+	subjects := map[string][]rbacv1.Subject{}
+	var missing []string
+	for _, member := range team.Spec.Members {
+		if !roles[member.Role] {
+			missing = append(missing, fmt.Sprintf("%s (unknown role %s)", member.UserConfig, member.Role))
+			continue
+		}
+		uc := &myoperatorv1alpha1.UserConfig{}
+		if err := u.Get(ctx, client.ObjectKey{Name: member.UserConfig}, uc); err != nil {
+			if apierrors.IsNotFound(err) {
+				missing = append(missing, member.UserConfig)
+				continue
+			}
+			return nil, nil, fmt.Errorf("failed to get UserConfig %s: %w", member.UserConfig, err)
+		}
+		subjects[member.Role] = append(subjects[member.Role], userSubjects(uc)...)
+	}
+	sort.Strings(missing)
+
+	return subjects, missing, nil
+}
+
+// pruneTeamRBAC deletes the Roles and RoleBindings of team that are not desired
+func (u *UserConfigUseCase) pruneTeamRBAC(ctx context.Context, team *myoperatorv1alpha1.Team, namespace string, desired map[string]bool) error {
+	selector := []client.ListOption{client.InNamespace(namespace), client.MatchingLabels(teamLabels(team))}
+
+	roleBindings := &rbacv1.RoleBindingList{}
+	if err := u.List(ctx, roleBindings, selector...); err != nil {
+		return fmt.Errorf("failed to list rolebindings of team %s: %w", team.Name, err)
+	}
+	for i := range roleBindings.Items {
+		if desired[roleBindings.Items[i].Name] {
+			continue
+		}
+		if err := u.Delete(ctx, &roleBindings.Items[i]); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete rolebinding %s: %w", roleBindings.Items[i].Name, err)
+		}
+	}
+
+	roles := &rbacv1.RoleList{}
+	if err := u.List(ctx, roles, selector...); err != nil {
+		return fmt.Errorf("failed to list roles of team %s: %w", team.Name, err)
+	}
+	for i := range roles.Items {
+		if desired[roles.Items[i].Name] {
+			continue
+		}
+		if err := u.Delete(ctx, &roles.Items[i]); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete role %s: %w", roles.Items[i].Name, err)
+		}
+	}
+
+	return nil
+}
+
+// applyTeamObject creates or updates obj after applying mutate, the team
+// labels and the team controller reference
+func (u *UserConfigUseCase) applyTeamObject(ctx context.Context, team *myoperatorv1alpha1.Team, obj client.Object, mutate func()) (controllerutil.OperationResult, error) {
+	return controllerutil.CreateOrUpdate(ctx, u.Client, obj, func() error {
+		mutate()
+		obj.SetLabels(mergeStringMaps(obj.GetLabels(), teamLabels(team)))
+		return controllerutil.SetControllerReference(team, obj, u.Scheme)
+	})
+}
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+)
+
+var _ = Describe("teams", func() {
+	var (
+		ctx  context.Context
+		u    *UserConfigUseCase
+		team *myoperatorv1alpha1.Team
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		team = &myoperatorv1alpha1.Team{
+			ObjectMeta: metav1.ObjectMeta{Name: "payments", UID: "payments-uid"},
+			Spec: myoperatorv1alpha1.TeamSpec{
+				Roles: []myoperatorv1alpha1.TeamRole{{
+					Name:      "developer",
+					Resources: []myoperatorv1alpha1.ResourcePermission{{Resource: "deployment", Operation: "CRUD"}},
+				}},
+			},
+		}
+		monitoring := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "monitoring"}}
+		u, _ = newTestUseCase(team, monitoring)
+	})
+
+	It("provisions the shared namespace and keeps managing it", func() {
+		Expect(u.ReconcileTeam(ctx, team)).To(Succeed())
+		namespace := &corev1.Namespace{}
+		Expect(u.Get(ctx, client.ObjectKey{Name: "payments"}, namespace)).To(Succeed())
+		Expect(namespace.Labels).To(HaveKeyWithValue(LabelTeamName, "payments"))
+		Expect(metav1.IsControlledBy(namespace, team)).To(BeTrue())
+
+		Expect(u.ReconcileTeam(ctx, team)).To(Succeed())
+	})
+
+	It("refuses protected namespaces", func() {
+		for _, name := range []string{"kube-system", "default"} {
+			team.Spec.Namespace = name
+			Expect(u.ReconcileTeam(ctx, team)).To(MatchError(ContainSubstring("namespace " + name + " is protected")))
+		}
+
+		defaults := NewOperatorDefaults()
+		defaults.Set(myoperatorv1alpha1.OperatorConfigSpec{ProtectedNamespaces: []string{"ingress-nginx"}})
+		u.Defaults = defaults
+		team.Spec.Namespace = "ingress-nginx"
+		Expect(u.ReconcileTeam(ctx, team)).To(MatchError(ContainSubstring("namespace ingress-nginx is protected")))
+		Expect(u.Get(ctx, client.ObjectKey{Name: "ingress-nginx"}, &corev1.Namespace{})).NotTo(Succeed())
+	})
+
+	It("refuses to take over an existing namespace it didn't create", func() {
+		team.Spec.Namespace = "monitoring"
+		Expect(u.ReconcileTeam(ctx, team)).To(MatchError(ContainSubstring("namespace monitoring already exists and isn't managed for team payments")))
+
+		namespace := &corev1.Namespace{}
+		Expect(u.Get(ctx, client.ObjectKey{Name: "monitoring"}, namespace)).To(Succeed())
+		Expect(namespace.Labels).NotTo(HaveKey(LabelTeamName))
+		Expect(namespace.OwnerReferences).To(BeEmpty())
+
+		quotas := &corev1.ResourceQuotaList{}
+		Expect(u.List(ctx, quotas, client.InNamespace("monitoring"))).To(Succeed())
+		Expect(quotas.Items).To(BeEmpty())
+	})
+})
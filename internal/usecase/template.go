@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+)
+
+// ResolveTemplate returns a copy of uc whose spec has the referenced
+// UserConfigTemplate merged in. Fields set on the UserConfig override the
+// template. The copy must only be used for reconciliation and status updates,
+// never to update the UserConfig spec itself.
+func (u *UserConfigUseCase) ResolveTemplate(ctx context.Context, uc *myoperatorv1alpha1.UserConfig) (*myoperatorv1alpha1.UserConfig, error) {
+	if uc.Spec.TemplateRef == nil {
+		return ApplyTemplate(uc, nil)
+	}
+
+	template := &myoperatorv1alpha1.UserConfigTemplate{}
+	if err := u.Get(ctx, client.ObjectKey{Name: uc.Spec.TemplateRef.Name}, template); err != nil {
+		return nil, fmt.Errorf("failed to get UserConfigTemplate %s: %w", uc.Spec.TemplateRef.Name, err)
+	}
+	return ApplyTemplate(uc, template)
+}
+
+// ApplyTemplate returns a copy of uc with template merged in like
+// ResolveTemplate does, for callers reading the template themselves. A nil
+// template returns uc unchanged.
+func ApplyTemplate(uc *myoperatorv1alpha1.UserConfig, template *myoperatorv1alpha1.UserConfigTemplate) (*myoperatorv1alpha1.UserConfig, error) {
+	resolved := uc.DeepCopy()
+	if template == nil {
+		resolved.Status.Template = nil
+		return resolved, nil
+	}
+
+	spec := &resolved.Spec
+	if template.Spec.Permissions != nil {
+		spec.Permissions.Resources = mergePermissions(template.Spec.Permissions.Resources, spec.Permissions.Resources)
+		spec.Permissions.Namespaces = mergeNamespacePermissions(template.Spec.Permissions.Namespaces, spec.Permissions.Namespaces)
+	}
+	quota, err := mergeResourceQuota(template.Spec.ResourceQuotas, spec.ResourceQuotas)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge resource quota of template %s: %w", template.Name, err)
+	}
+	spec.ResourceQuotas = quota
+	spec.LimitRange = mergeLimitRange(template.Spec.LimitRange, spec.LimitRange)
+	spec.NetworkPolicy = append(append([]myoperatorv1alpha1.NetworkPolicy{}, template.Spec.NetworkPolicy...), spec.NetworkPolicy...)
+
+	resolved.Status.Template = &myoperatorv1alpha1.ResolvedTemplate{
+		Name:       template.Name,
+		Generation: template.Generation,
+	}
+	return resolved, nil
+}
+
+// mergePermissions appends the template permissions for resources the override doesn't mention
+func mergePermissions(template, override []myoperatorv1alpha1.ResourcePermission) []myoperatorv1alpha1.ResourcePermission {
+	overridden := make(map[string]bool, len(override))
+	for _, perm := range override {
+		overridden[perm.Resource] = true
+	}
+	var merged []myoperatorv1alpha1.ResourcePermission
+	for _, perm := range template {
+		if !overridden[perm.Resource] {
+			merged = append(merged, perm)
+		}
+	}
+	return append(merged, override...)
+}
+
+// mergeNamespacePermissions appends the template namespace permissions for the
+// UserConfigs and selectors the override doesn't target
+func mergeNamespacePermissions(template, override []myoperatorv1alpha1.NamespacePermission) []myoperatorv1alpha1.NamespacePermission {
+	overridden := make(map[string]bool, len(override))
+	for _, perm := range override {
+		overridden[namespacePermissionTarget(perm)] = true
+	}
+	var merged []myoperatorv1alpha1.NamespacePermission
+	for _, perm := range template {
+		if !overridden[namespacePermissionTarget(perm)] {
+			merged = append(merged, *perm.DeepCopy())
+		}
+	}
+	return append(merged, override...)
+}
+
+// namespacePermissionTarget identifies the UserConfigs targeted by perm
+func namespacePermissionTarget(perm myoperatorv1alpha1.NamespacePermission) string {
+	if perm.Selector != nil {
+		return "selector/" + metav1.FormatLabelSelector(perm.Selector)
+	}
+	return "userConfig/" + perm.UserConfig
+}
+
+// mergeResourceQuota overlays every field set in override on top of template
+func mergeResourceQuota(template, override *myoperatorv1alpha1.ResourceQuota) (*myoperatorv1alpha1.ResourceQuota, error) {
+	if template == nil {
+		return override, nil
+	}
+	if override == nil {
+		return template.DeepCopy(), nil
+	}
+
+	fields := map[string]json.RawMessage{}
+	for _, quota := range []*myoperatorv1alpha1.ResourceQuota{template, override} {
+		// Normalize first so an overridden configmaps quota wins whatever its spelling
+		quota = quota.DeepCopy()
+		normalizeResourceQuota(quota)
+		data, err := json.Marshal(quota)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, err
+		}
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	merged := &myoperatorv1alpha1.ResourceQuota{}
+	if err := json.Unmarshal(data, merged); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// mergeLimitRange keeps the template limits for every type the override doesn't define
+func mergeLimitRange(template, override *myoperatorv1alpha1.LimitRange) *myoperatorv1alpha1.LimitRange {
+	if template == nil {
+		return override
+	}
+	if override == nil {
+		return template.DeepCopy()
+	}
+
+	overridden := make(map[string]bool, len(override.Limits))
+	for _, limit := range override.Limits {
+		overridden[limit.Type] = true
+	}
+	merged := &myoperatorv1alpha1.LimitRange{}
+	for _, limit := range template.Limits {
+		if !overridden[limit.Type] {
+			merged.Limits = append(merged.Limits, *limit.DeepCopy())
+		}
+	}
+	merged.Limits = append(merged.Limits, override.Limits...)
+	return merged
+}
@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+)
+
+var _ = Describe("templates", func() {
+	peer := func(team string) myoperatorv1alpha1.NetworkPolicy {
+		return myoperatorv1alpha1.NetworkPolicy{AllowTrafficFrom: &myoperatorv1alpha1.NetworkPolicyPeer{
+			Namespaces: []map[string]string{{"team": team}},
+		}}
+	}
+
+	var (
+		ctx context.Context
+		u   *UserConfigUseCase
+		uc  *myoperatorv1alpha1.UserConfig
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		template := &myoperatorv1alpha1.UserConfigTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "developer", Generation: 3},
+			Spec: myoperatorv1alpha1.UserConfigTemplateSpec{
+				Permissions: &myoperatorv1alpha1.Permissions{Resources: []myoperatorv1alpha1.ResourcePermission{
+					{Resource: "deployment", Operation: "CRUD"},
+					{Resource: "secret", Operation: "R"},
+				}},
+				ResourceQuotas: &myoperatorv1alpha1.ResourceQuota{CPU: "4", Memory: "8Gi"},
+				LimitRange: &myoperatorv1alpha1.LimitRange{Limits: []myoperatorv1alpha1.LimitRangeLimit{
+					{Type: "Container", Max: &myoperatorv1alpha1.Resources{CPU: "2"}},
+					{Type: "Pod", Max: &myoperatorv1alpha1.Resources{CPU: "4"}},
+				}},
+				NetworkPolicy: []myoperatorv1alpha1.NetworkPolicy{peer("template")},
+			},
+		}
+		uc = &myoperatorv1alpha1.UserConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "alice"},
+			Spec: myoperatorv1alpha1.UserConfigSpec{
+				TemplateRef:    &myoperatorv1alpha1.TemplateReference{Name: "developer"},
+				Permissions:    myoperatorv1alpha1.Permissions{Resources: []myoperatorv1alpha1.ResourcePermission{{Resource: "secret", Operation: "CRUD"}}},
+				ResourceQuotas: &myoperatorv1alpha1.ResourceQuota{CPU: "2"},
+				LimitRange: &myoperatorv1alpha1.LimitRange{Limits: []myoperatorv1alpha1.LimitRangeLimit{
+					{Type: "Container", Max: &myoperatorv1alpha1.Resources{CPU: "1"}},
+				}},
+				NetworkPolicy: []myoperatorv1alpha1.NetworkPolicy{peer("override")},
+			},
+		}
+		u, _ = newTestUseCase(template, uc)
+	})
+
+	It("lets the UserConfig fields override the template ones", func() {
+		resolved, err := u.ResolveTemplate(ctx, uc)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(resolved.Spec.Permissions.Resources).To(Equal([]myoperatorv1alpha1.ResourcePermission{
+			{Resource: "deployment", Operation: "CRUD"},
+			{Resource: "secret", Operation: "CRUD"},
+		}))
+		Expect(resolved.Spec.ResourceQuotas.CPU).To(Equal("2"))
+		Expect(resolved.Spec.ResourceQuotas.Memory).To(Equal("8Gi"))
+
+		Expect(resolved.Spec.LimitRange.Limits).To(HaveLen(2))
+		Expect(resolved.Spec.LimitRange.Limits[0].Type).To(Equal("Pod"))
+		Expect(resolved.Spec.LimitRange.Limits[1].Max.CPU).To(Equal("1"))
+
+		Expect(resolved.Spec.NetworkPolicy).To(Equal([]myoperatorv1alpha1.NetworkPolicy{peer("template"), peer("override")}))
+		Expect(resolved.Status.Template).To(Equal(&myoperatorv1alpha1.ResolvedTemplate{Name: "developer", Generation: 3}))
+
+		// The UserConfig itself is left untouched
+		Expect(uc.Spec.Permissions.Resources).To(HaveLen(1))
+		Expect(uc.Spec.ResourceQuotas.Memory).To(BeEmpty())
+	})
+
+	It("merges the namespace permissions by target, the UserConfig ones winning", func() {
+		read := []myoperatorv1alpha1.ResourcePermission{{Resource: "pod", Operation: "R"}}
+		write := []myoperatorv1alpha1.ResourcePermission{{Resource: "pod", Operation: "CRUD"}}
+		platform := &metav1.LabelSelector{MatchLabels: map[string]string{"team": "platform"}}
+		template := &myoperatorv1alpha1.UserConfigTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "developer"},
+			Spec: myoperatorv1alpha1.UserConfigTemplateSpec{
+				Permissions: &myoperatorv1alpha1.Permissions{Namespaces: []myoperatorv1alpha1.NamespacePermission{
+					{UserConfig: "bob", Resources: read},
+					{Selector: platform, Resources: read},
+				}},
+			},
+		}
+		uc.Spec.Permissions.Namespaces = []myoperatorv1alpha1.NamespacePermission{
+			{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "platform"}}, Resources: write},
+			{UserConfig: "carol", Resources: read},
+		}
+
+		resolved, err := ApplyTemplate(uc, template)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolved.Spec.Permissions.Namespaces).To(Equal([]myoperatorv1alpha1.NamespacePermission{
+			{UserConfig: "bob", Resources: read},
+			{Selector: platform, Resources: write},
+			{UserConfig: "carol", Resources: read},
+		}))
+	})
+
+	It("uses the template fields the UserConfig leaves unset", func() {
+		uc.Spec.ResourceQuotas = nil
+		uc.Spec.LimitRange = nil
+		resolved, err := u.ResolveTemplate(ctx, uc)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolved.Spec.ResourceQuotas).To(Equal(&myoperatorv1alpha1.ResourceQuota{CPU: "4", Memory: "8Gi"}))
+		Expect(resolved.Spec.LimitRange.Limits).To(HaveLen(2))
+	})
+
+	It("reports a missing template and clears the resolved one without reference", func() {
+		uc.Spec.TemplateRef.Name = "missing"
+		_, err := u.ResolveTemplate(ctx, uc)
+		Expect(err).To(MatchError(ContainSubstring("failed to get UserConfigTemplate missing")))
+
+		uc.Spec.TemplateRef = nil
+		uc.Status.Template = &myoperatorv1alpha1.ResolvedTemplate{Name: "developer", Generation: 3}
+		resolved, err := u.ResolveTemplate(ctx, uc)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolved.Status.Template).To(BeNil())
+		Expect(resolved.Spec).To(Equal(uc.Spec))
+	})
+})
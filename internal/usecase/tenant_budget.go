@@ -0,0 +1,627 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+)
+
+// budgetResource is a TenantBudget limit and the quota resources it caps. When
+// a ResourceQuota sets several of them the smallest one is counted.
+type budgetResource struct {
+	name  corev1.ResourceName
+	quota []corev1.ResourceName
+	limit func(spec *myoperatorv1alpha1.TenantBudgetSpec) string
+}
+
+var budgetResources = []budgetResource{
+	{
+		name:  corev1.ResourceCPU,
+		quota: []corev1.ResourceName{corev1.ResourceRequestsCPU, corev1.ResourceCPU},
+		limit: func(spec *myoperatorv1alpha1.TenantBudgetSpec) string { return spec.CPU },
+	},
+	{
+		name:  corev1.ResourceMemory,
+		quota: []corev1.ResourceName{corev1.ResourceRequestsMemory, corev1.ResourceMemory},
+		limit: func(spec *myoperatorv1alpha1.TenantBudgetSpec) string { return spec.Memory },
+	},
+	{
+		name:  corev1.ResourceStorage,
+		quota: []corev1.ResourceName{corev1.ResourceRequestsStorage},
+		limit: func(spec *myoperatorv1alpha1.TenantBudgetSpec) string { return spec.Storage },
+	},
+	{
+		name:  corev1.ResourcePods,
+		quota: []corev1.ResourceName{corev1.ResourcePods},
+		limit: func(spec *myoperatorv1alpha1.TenantBudgetSpec) string { return spec.Pods },
+	},
+}
+
+// committedQuota is the quota a UserConfig or a Team hands out for each budget resource
+type committedQuota struct {
+	// key is the UserConfig name, or the Team name prefixed with team/
+	key        string
+	object     client.Object
+	conditions *[]metav1.Condition
+	quota      corev1.ResourceList
+}
+
+// teamBudgetKey is the key of the quota of the Team name. UserConfig names
+// can't hold a slash, so it never clashes with the key of a UserConfig.
+func teamBudgetKey(name string) string {
+	return "team/" + name
+}
+
+// BudgetViolation is a TenantBudget resource a UserConfig quota doesn't fit in
+type BudgetViolation struct {
+	Budget      string
+	Enforcement string
+	Resource    corev1.ResourceName
+	Requested   resource.Quantity
+	Headroom    resource.Quantity
+
+	// requester names what requests the quota in the message
+	requester string
+}
+
+func (v BudgetViolation) String() string {
+	return fmt.Sprintf("TenantBudget %s has %s %s left for %s, which requests %s",
+		v.Budget, v.Headroom.String(), v.Resource, v.requester, v.Requested.String())
+}
+
+// budgetLimits parses the limits set in spec
+func budgetLimits(spec *myoperatorv1alpha1.TenantBudgetSpec) (corev1.ResourceList, error) {
+	limits := corev1.ResourceList{}
+	for _, budget := range budgetResources {
+		value := budget.limit(spec)
+		if value == "" {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantity %q of %s: %w", value, budget.name, err)
+		}
+		limits[budget.name] = quantity
+	}
+	return limits, nil
+}
+
+// budgetQuota returns the quota hard counted for each budget resource, the
+// smallest one when hard sets several of its quota resources
+func budgetQuota(hard corev1.ResourceList) corev1.ResourceList {
+	counted := corev1.ResourceList{}
+	for _, budget := range budgetResources {
+		for _, name := range budget.quota {
+			quantity, ok := hard[name]
+			if current, counting := counted[budget.name]; ok && (!counting || quantity.Cmp(current) < 0) {
+				counted[budget.name] = quantity
+			}
+		}
+	}
+	return counted
+}
+
+// userConfigQuota sums the quota of every tenant namespace of the resolved uc
+// for each budget resource. Additional quotas only narrow the main one and
+// aren't counted. Namespaces without quota count the defaults quota, and
+// autoscaled resources count their current hard limit.
+func userConfigQuota(uc *myoperatorv1alpha1.UserConfig, defaults *myoperatorv1alpha1.ResourceQuota) (corev1.ResourceList, error) {
+	total := corev1.ResourceList{}
+	for _, ns := range tenantNamespaces(uc) {
+		spec, err := resourceQuotaSpec(ns.ResourceQuotas, defaults)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ResourceQuota of namespace %s: %w", ns.Name, err)
+		}
+		if ns.ResourceQuotas != nil {
+			if err := applyAutoscaledHard(&spec, ns.ResourceQuotas.Autoscale, uc, ns.Name); err != nil {
+				return nil, fmt.Errorf("invalid ResourceQuota of namespace %s: %w", ns.Name, err)
+			}
+		}
+		addResourceList(total, budgetQuota(spec.Hard))
+	}
+	return total, nil
+}
+
+// teamQuota returns the quota of the shared namespace of team for each budget
+// resource, the defaults quota when the Team doesn't set one
+func teamQuota(team *myoperatorv1alpha1.Team, defaults *myoperatorv1alpha1.ResourceQuota) (corev1.ResourceList, error) {
+	spec, err := resourceQuotaSpec(team.Spec.ResourceQuotas, defaults)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ResourceQuota of team %s: %w", team.Name, err)
+	}
+	return budgetQuota(spec.Hard), nil
+}
+
+// resolvedQuota resolves the template of uc before summing its quota
+func (u *UserConfigUseCase) resolvedQuota(ctx context.Context, uc *myoperatorv1alpha1.UserConfig) (corev1.ResourceList, error) {
+	resolved, err := u.ResolveTemplate(ctx, uc)
+	if err != nil {
+		return nil, err
+	}
+	return userConfigQuota(resolved, u.Defaults.Spec().DefaultResourceQuota)
+}
+
+// committedQuotas returns the quota of every UserConfig and Team, oldest
+// first. Those whose quota can't be resolved aren't counted.
+func (u *UserConfigUseCase) committedQuotas(ctx context.Context) ([]committedQuota, error) {
+	userConfigs := &myoperatorv1alpha1.UserConfigList{}
+	if err := u.List(ctx, userConfigs); err != nil {
+		return nil, fmt.Errorf("failed to list UserConfigs: %w", err)
+	}
+	teams := &myoperatorv1alpha1.TeamList{}
+	if err := u.List(ctx, teams); err != nil {
+		return nil, fmt.Errorf("failed to list Teams: %w", err)
+	}
+
+	var committed []committedQuota
+	for i := range userConfigs.Items {
+		uc := &userConfigs.Items[i]
+		if !uc.DeletionTimestamp.IsZero() {
+			continue
+		}
+		quota, err := u.resolvedQuota(ctx, uc)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "UserConfig not counted in the tenant budgets", "userConfig", uc.Name)
+			continue
+		}
+		committed = append(committed, committedQuota{key: uc.Name, object: uc, conditions: &uc.Status.Conditions, quota: quota})
+	}
+	for i := range teams.Items {
+		team := &teams.Items[i]
+		if !team.DeletionTimestamp.IsZero() {
+			continue
+		}
+		quota, err := teamQuota(team, u.Defaults.Spec().DefaultResourceQuota)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "Team not counted in the tenant budgets", "team", team.Name)
+			continue
+		}
+		committed = append(committed, committedQuota{key: teamBudgetKey(team.Name), object: team, conditions: &team.Status.Conditions, quota: quota})
+	}
+
+	sort.SliceStable(committed, func(i, j int) bool {
+		a, b := committed[i].object.GetCreationTimestamp(), committed[j].object.GetCreationTimestamp()
+		if !a.Equal(&b) {
+			return a.Before(&b)
+		}
+		return committed[i].key < committed[j].key
+	})
+	return committed, nil
+}
+
+// usedQuota returns the quota committed by every UserConfig and Team but the
+// exclude keys, read from the ledger once it has been refreshed
+func (u *UserConfigUseCase) usedQuota(ctx context.Context, exclude ...string) (corev1.ResourceList, error) {
+	if used, ok := u.Budgets.used(exclude); ok {
+		return used, nil
+	}
+	committed, err := u.committedQuotas(ctx)
+	if err != nil {
+		return nil, err
+	}
+	used := corev1.ResourceList{}
+	for _, entry := range committed {
+		if !slices.Contains(exclude, entry.key) {
+			addResourceList(used, entry.quota)
+		}
+	}
+	return used, nil
+}
+
+// budgetViolations returns the budget resources requested raises past what
+// the quota of every other owner leaves in the TenantBudgets. owners are the
+// keys requested replaces the quota of, and requester names them in messages. Resources requested doesn't raise
+// compared to previous are never reported, so that shrinking a budget doesn't
+// block unrelated updates.
+func (u *UserConfigUseCase) budgetViolations(ctx context.Context, requester string, requested, previous corev1.ResourceList, owners ...string) ([]BudgetViolation, error) {
+	budgets := &myoperatorv1alpha1.TenantBudgetList{}
+	if err := u.List(ctx, budgets); err != nil {
+		return nil, fmt.Errorf("failed to list TenantBudgets: %w", err)
+	}
+	if len(budgets.Items) == 0 {
+		return nil, nil
+	}
+	used, err := u.usedQuota(ctx, owners...)
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []BudgetViolation
+	for _, budget := range budgets.Items {
+		limits, err := budgetLimits(&budget.Spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TenantBudget %s: %w", budget.Name, err)
+		}
+		for _, resourceBudget := range budgetResources {
+			limit, ok := limits[resourceBudget.name]
+			want := requested[resourceBudget.name]
+			if !ok || want.Cmp(previous[resourceBudget.name]) <= 0 {
+				continue
+			}
+			headroom := limit.DeepCopy()
+			headroom.Sub(used[resourceBudget.name])
+			if want.Cmp(headroom) > 0 {
+				violations = append(violations, BudgetViolation{
+					Budget:      budget.Name,
+					Enforcement: budget.Spec.Enforcement,
+					Resource:    resourceBudget.name,
+					Requested:   want,
+					Headroom:    headroom,
+					requester:   requester,
+				})
+			}
+		}
+	}
+	return violations, nil
+}
+
+// rejected reports whether one of violations is enforced by rejecting the change
+func rejected(violations []BudgetViolation) bool {
+	return slices.ContainsFunc(violations, func(violation BudgetViolation) bool {
+		return violation.Enforcement != myoperatorv1alpha1.BudgetEnforcementMark
+	})
+}
+
+// TenantBudgetViolations returns the budget resources uc requests more of than
+// the other UserConfigs and Teams left. Resources uc doesn't raise compared to
+// old are never reported. The quota of an admitted uc is counted right away.
+func (u *UserConfigUseCase) TenantBudgetViolations(ctx context.Context, uc, old *myoperatorv1alpha1.UserConfig) ([]BudgetViolation, error) {
+	requested, err := u.resolvedQuota(ctx, uc)
+	if err != nil {
+		return nil, err
+	}
+	previous := corev1.ResourceList{}
+	if old != nil {
+		if quota, err := u.resolvedQuota(ctx, old); err == nil {
+			previous = quota
+		}
+	}
+	violations, err := u.budgetViolations(ctx, "this UserConfig", requested, previous, uc.Name)
+	if err != nil || rejected(violations) {
+		return violations, err
+	}
+	u.Budgets.record(map[string]corev1.ResourceList{uc.Name: requested})
+	return violations, nil
+}
+
+// TeamBudgetViolations is TenantBudgetViolations for the quota of team
+func (u *UserConfigUseCase) TeamBudgetViolations(ctx context.Context, team, old *myoperatorv1alpha1.Team) ([]BudgetViolation, error) {
+	defaults := u.Defaults.Spec().DefaultResourceQuota
+	requested, err := teamQuota(team, defaults)
+	if err != nil {
+		return nil, err
+	}
+	previous := corev1.ResourceList{}
+	if old != nil {
+		if quota, err := teamQuota(old, defaults); err == nil {
+			previous = quota
+		}
+	}
+	key := teamBudgetKey(team.Name)
+	violations, err := u.budgetViolations(ctx, "this Team", requested, previous, key)
+	if err != nil || rejected(violations) {
+		return violations, err
+	}
+	u.Budgets.record(map[string]corev1.ResourceList{key: requested})
+	return violations, nil
+}
+
+// TemplateBudgetViolations returns the budget resources the UserConfigs
+// inheriting from template request more of, altogether, than the others left.
+// Their quota is resolved against template and against old, the template
+// being updated.
+func (u *UserConfigUseCase) TemplateBudgetViolations(ctx context.Context, template, old *myoperatorv1alpha1.UserConfigTemplate) ([]BudgetViolation, error) {
+	userConfigs := &myoperatorv1alpha1.UserConfigList{}
+	if err := u.List(ctx, userConfigs); err != nil {
+		return nil, fmt.Errorf("failed to list UserConfigs: %w", err)
+	}
+
+	defaults := u.Defaults.Spec().DefaultResourceQuota
+	quotas := map[string]corev1.ResourceList{}
+	var owners []string
+	requested, previous := corev1.ResourceList{}, corev1.ResourceList{}
+	for i := range userConfigs.Items {
+		uc := &userConfigs.Items[i]
+		if uc.Spec.TemplateRef == nil || uc.Spec.TemplateRef.Name != template.Name || !uc.DeletionTimestamp.IsZero() {
+			continue
+		}
+		resolved, err := ApplyTemplate(uc, template)
+		if err != nil {
+			return nil, err
+		}
+		quota, err := userConfigQuota(resolved, defaults)
+		if err != nil {
+			return nil, fmt.Errorf("UserConfig %s: %w", uc.Name, err)
+		}
+		quotas[uc.Name] = quota
+		owners = append(owners, uc.Name)
+		addResourceList(requested, quota)
+
+		// Without old the UserConfigs couldn't be resolved and weren't counted
+		if old == nil {
+			continue
+		}
+		if resolved, err := ApplyTemplate(uc, old); err == nil {
+			if quota, err := userConfigQuota(resolved, defaults); err == nil {
+				addResourceList(previous, quota)
+			}
+		}
+	}
+	if len(owners) == 0 {
+		return nil, nil
+	}
+
+	violations, err := u.budgetViolations(ctx, "this UserConfigTemplate", requested, previous, owners...)
+	if err != nil || rejected(violations) {
+		return violations, err
+	}
+	u.Budgets.record(quotas)
+	return violations, nil
+}
+
+// ReconcileTenantBudgets refreshes the status of every TenantBudget, the
+// OverCommitted condition of every UserConfig and Team, and the ledger the
+// admission checks read
+func (u *UserConfigUseCase) ReconcileTenantBudgets(ctx context.Context) error {
+	budgets := &myoperatorv1alpha1.TenantBudgetList{}
+	if err := u.List(ctx, budgets); err != nil {
+		return fmt.Errorf("failed to list TenantBudgets: %w", err)
+	}
+	committed, err := u.committedQuotas(ctx)
+	if err != nil {
+		return err
+	}
+	u.Budgets.set(committed)
+
+	exceeded := map[string][]string{}
+	for i := range budgets.Items {
+		budget := &budgets.Items[i]
+		patch := client.MergeFrom(budget.DeepCopy())
+		for _, name := range tenantBudgetStatus(budget, committed) {
+			exceeded[name] = append(exceeded[name], budget.Name)
+		}
+		if err := u.Status().Patch(ctx, budget, patch); err != nil {
+			return fmt.Errorf("failed to update status of TenantBudget %s: %w", budget.Name, err)
+		}
+	}
+
+	for _, entry := range committed {
+		obj := entry.object
+		patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+		var changed bool
+		switch {
+		case len(budgets.Items) == 0:
+			changed = meta.RemoveStatusCondition(entry.conditions, myoperatorv1alpha1.OverCommittedCondition)
+		case len(exceeded[entry.key]) > 0:
+			changed = meta.SetStatusCondition(entry.conditions, metav1.Condition{
+				Type:               myoperatorv1alpha1.OverCommittedCondition,
+				Status:             metav1.ConditionTrue,
+				Reason:             "BudgetExceeded",
+				Message:            "The quota doesn't fit in TenantBudget " + strings.Join(exceeded[entry.key], ", "),
+				ObservedGeneration: obj.GetGeneration(),
+			})
+		default:
+			changed = meta.SetStatusCondition(entry.conditions, metav1.Condition{
+				Type:               myoperatorv1alpha1.OverCommittedCondition,
+				Status:             metav1.ConditionFalse,
+				Reason:             "WithinBudget",
+				Message:            "The quota fits in every TenantBudget",
+				ObservedGeneration: obj.GetGeneration(),
+			})
+		}
+		if !changed {
+			continue
+		}
+		if err := u.Status().Patch(ctx, obj, patch); err != nil {
+			return fmt.Errorf("failed to update status of %s: %w", entry.key, err)
+		}
+	}
+	return nil
+}
+
+// budgetHeadroom returns, for each budget resource capped by a TenantBudget,
+// the smallest quantity uc can still grow by across the budgets. It is nil
+// when there is no TenantBudget.
+func (u *UserConfigUseCase) budgetHeadroom(ctx context.Context, uc *myoperatorv1alpha1.UserConfig) (corev1.ResourceList, error) {
+	budgets := &myoperatorv1alpha1.TenantBudgetList{}
+	if err := u.List(ctx, budgets); err != nil {
+		return nil, fmt.Errorf("failed to list TenantBudgets: %w", err)
+	}
+	if len(budgets.Items) == 0 {
+		return nil, nil
+	}
+
+	used, err := u.resolvedQuota(ctx, uc)
+	if err != nil {
+		return nil, err
+	}
+	others, err := u.usedQuota(ctx, uc.Name)
+	if err != nil {
+		return nil, err
+	}
+	addResourceList(used, others)
+
+	headroom := corev1.ResourceList{}
+	for _, budget := range budgets.Items {
+		limits, err := budgetLimits(&budget.Spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TenantBudget %s: %w", budget.Name, err)
+		}
+		for name, limit := range limits {
+			left := limit.DeepCopy()
+			left.Sub(used[name])
+			if current, ok := headroom[name]; !ok || left.Cmp(current) < 0 {
+				headroom[name] = left
+			}
+		}
+	}
+	return headroom, nil
+}
+
+// budgetResourceOf returns the budget resource capping the quota resource name
+func budgetResourceOf(name corev1.ResourceName) (corev1.ResourceName, bool) {
+	for _, budget := range budgetResources {
+		for _, quota := range budget.quota {
+			if quota == name {
+				return budget.name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// tenantBudgetStatus fills the status of budget from the committed quotas,
+// oldest first, and returns the keys of those that don't fit in it
+func tenantBudgetStatus(budget *myoperatorv1alpha1.TenantBudget, committed []committedQuota) []string {
+	condition := metav1.Condition{
+		Type:               myoperatorv1alpha1.OverCommittedCondition,
+		ObservedGeneration: budget.Generation,
+	}
+	limits, err := budgetLimits(&budget.Spec)
+	if err != nil {
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = "InvalidBudget"
+		condition.Message = err.Error()
+		meta.SetStatusCondition(&budget.Status.Conditions, condition)
+		return nil
+	}
+
+	total := corev1.ResourceList{}
+	var overCommitted []string
+	for _, entry := range committed {
+		addResourceList(total, entry.quota)
+		for name, quantity := range entry.quota {
+			sum := total[name]
+			if limit, ok := limits[name]; ok && !quantity.IsZero() && sum.Cmp(limit) > 0 {
+				overCommitted = append(overCommitted, entry.key)
+				break
+			}
+		}
+	}
+
+	budget.Status.Committed = map[string]string{}
+	budget.Status.Headroom = map[string]string{}
+	var exceeded []string
+	for _, resourceBudget := range budgetResources {
+		used := total[resourceBudget.name]
+		budget.Status.Committed[string(resourceBudget.name)] = used.String()
+		limit, ok := limits[resourceBudget.name]
+		if !ok {
+			continue
+		}
+		headroom := limit.DeepCopy()
+		headroom.Sub(used)
+		budget.Status.Headroom[string(resourceBudget.name)] = headroom.String()
+		if headroom.Sign() < 0 {
+			exceeded = append(exceeded, fmt.Sprintf("%s (%s of %s)", resourceBudget.name, used.String(), limit.String()))
+		}
+	}
+	budget.Status.UserConfigs, budget.Status.Teams = 0, 0
+	for _, entry := range committed {
+		if _, ok := entry.object.(*myoperatorv1alpha1.Team); ok {
+			budget.Status.Teams++
+		} else {
+			budget.Status.UserConfigs++
+		}
+	}
+	budget.Status.OverCommitted = overCommitted
+
+	condition.Status = metav1.ConditionFalse
+	condition.Reason = "WithinBudget"
+	condition.Message = "The UserConfig quotas fit in the budget"
+	if len(exceeded) > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "BudgetExceeded"
+		condition.Message = "Committed quota exceeds the budget: " + strings.Join(exceeded, ", ")
+	}
+	meta.SetStatusCondition(&budget.Status.Conditions, condition)
+	return overCommitted
+}
+
+// addResourceList adds every quantity of add to total
+func addResourceList(total, add corev1.ResourceList) {
+	for name, quantity := range add {
+		sum := total[name]
+		sum.Add(quantity)
+		total[name] = sum
+	}
+}
+
+// BudgetLedger caches the quota every UserConfig and Team commits to the
+// TenantBudgets, so that admission doesn't resolve every UserConfig. It is
+// refreshed by ReconcileTenantBudgets and safe for concurrent use. Until the
+// first refresh, or when nil, the committed quotas are read from the API.
+type BudgetLedger struct {
+	mu     sync.Mutex
+	loaded bool
+	quotas map[string]corev1.ResourceList
+}
+
+// NewBudgetLedger returns an empty BudgetLedger waiting for its first refresh
+func NewBudgetLedger() *BudgetLedger {
+	return &BudgetLedger{}
+}
+
+// set replaces the cached quotas with committed
+func (l *BudgetLedger) set(committed []committedQuota) {
+	if l == nil {
+		return
+	}
+	quotas := make(map[string]corev1.ResourceList, len(committed))
+	for _, entry := range committed {
+		quotas[entry.key] = entry.quota
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.quotas, l.loaded = quotas, true
+}
+
+// record counts the admitted quotas until the next refresh, so that
+// concurrent admissions don't hand out the same headroom twice
+func (l *BudgetLedger) record(quotas map[string]corev1.ResourceList) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.loaded {
+		return
+	}
+	for key, quota := range quotas {
+		l.quotas[key] = quota
+	}
+}
+
+// used sums the cached quotas but the exclude keys, and reports false before
+// the first refresh
+func (l *BudgetLedger) used(exclude []string) (corev1.ResourceList, bool) {
+	if l == nil {
+		return nil, false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.loaded {
+		return nil, false
+	}
+	used := corev1.ResourceList{}
+	for key, quota := range l.quotas {
+		if !slices.Contains(exclude, key) {
+			addResourceList(used, quota)
+		}
+	}
+	return used, true
+}
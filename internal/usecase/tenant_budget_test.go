@@ -0,0 +1,181 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+)
+
+var _ = Describe("tenant budgets", func() {
+	var (
+		ctx     context.Context
+		created time.Time
+	)
+
+	// userConfig returns a UserConfig created after the previous ones
+	userConfig := func(name string, quota *myoperatorv1alpha1.ResourceQuota) *myoperatorv1alpha1.UserConfig {
+		created = created.Add(time.Minute)
+		return &myoperatorv1alpha1.UserConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(created)},
+			Spec:       myoperatorv1alpha1.UserConfigSpec{ResourceQuotas: quota},
+		}
+	}
+
+	budget := func(enforcement string) *myoperatorv1alpha1.TenantBudget {
+		return &myoperatorv1alpha1.TenantBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Spec:       myoperatorv1alpha1.TenantBudgetSpec{CPU: "6", Pods: "100", Enforcement: enforcement},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		created = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	})
+
+	It("sums the quota of every namespace, counting the smallest of cpu and requests.cpu", func() {
+		uc := userConfig("alice", &myoperatorv1alpha1.ResourceQuota{CPU: "4", RequestsCPU: "1500m", Memory: "2Gi"})
+		uc.Spec.Namespaces = []myoperatorv1alpha1.UserNamespace{{Suffix: "dev"}}
+
+		quota, err := userConfigQuota(uc, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(quota).To(HaveLen(2))
+		Expect(quota.Cpu().String()).To(Equal("3"))
+		Expect(quota.Memory().String()).To(Equal("4Gi"))
+	})
+
+	It("serves the oldest UserConfigs first", func() {
+		committed := []committedQuota{
+			{key: "alice", object: userConfig("alice", nil), quota: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")}},
+			{key: "bob", object: userConfig("bob", nil), quota: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")}},
+			{key: "carol", object: userConfig("carol", nil), quota: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10")}},
+		}
+		b := budget(myoperatorv1alpha1.BudgetEnforcementMark)
+
+		Expect(tenantBudgetStatus(b, committed)).To(Equal([]string{"bob"}))
+		Expect(b.Status.Committed).To(HaveKeyWithValue("cpu", "8"))
+		Expect(b.Status.Headroom).To(Equal(map[string]string{"cpu": "-2", "pods": "90"}))
+		Expect(b.Status.UserConfigs).To(BeEquivalentTo(3))
+		Expect(meta.IsStatusConditionTrue(b.Status.Conditions, myoperatorv1alpha1.OverCommittedCondition)).To(BeTrue())
+	})
+
+	It("reports the resources a UserConfig raises past the headroom", func() {
+		// The default quota hands out 2 CPUs
+		alice := userConfig("alice", nil)
+		u, _ := newTestUseCase(alice, budget(myoperatorv1alpha1.BudgetEnforcementReject))
+
+		bob := userConfig("bob", &myoperatorv1alpha1.ResourceQuota{CPU: "5", Pods: "10"})
+		violations, err := u.TenantBudgetViolations(ctx, bob, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(violations).To(HaveLen(1))
+		Expect(violations[0].Resource).To(Equal(corev1.ResourceCPU))
+		Expect(violations[0].Headroom.String()).To(Equal("4"))
+		Expect(violations[0].String()).To(Equal("TenantBudget cluster has 4 cpu left for this UserConfig, which requests 5"))
+
+		// Updates that don't raise the quota are admitted
+		violations, err = u.TenantBudgetViolations(ctx, bob, bob.DeepCopy())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(violations).To(BeEmpty())
+	})
+
+	It("counts the Teams and checks the quota they raise", func() {
+		alice := userConfig("alice", &myoperatorv1alpha1.ResourceQuota{CPU: "2"})
+		team := &myoperatorv1alpha1.Team{
+			ObjectMeta: metav1.ObjectMeta{Name: "payments", CreationTimestamp: metav1.NewTime(created.Add(time.Hour))},
+			Spec:       myoperatorv1alpha1.TeamSpec{ResourceQuotas: &myoperatorv1alpha1.ResourceQuota{CPU: "3"}},
+		}
+		u, _ := newTestUseCase(alice, team, budget(myoperatorv1alpha1.BudgetEnforcementMark))
+
+		raised := team.DeepCopy()
+		raised.Spec.ResourceQuotas.CPU = "5"
+		violations, err := u.TeamBudgetViolations(ctx, raised, team)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(violations).To(HaveLen(1))
+		Expect(violations[0].Headroom.String()).To(Equal("4"))
+
+		Expect(u.ReconcileTenantBudgets(ctx)).To(Succeed())
+		b := &myoperatorv1alpha1.TenantBudget{}
+		Expect(u.Get(ctx, client.ObjectKey{Name: "cluster"}, b)).To(Succeed())
+		Expect(b.Status.Committed).To(HaveKeyWithValue("cpu", "5"))
+		Expect(b.Status.Teams).To(BeEquivalentTo(1))
+		Expect(u.Get(ctx, client.ObjectKey{Name: "payments"}, team)).To(Succeed())
+		Expect(meta.IsStatusConditionFalse(team.Status.Conditions, myoperatorv1alpha1.OverCommittedCondition)).To(BeTrue())
+	})
+
+	It("sums the quota a template raises across the UserConfigs inheriting from it", func() {
+		template := &myoperatorv1alpha1.UserConfigTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "developer"},
+			Spec:       myoperatorv1alpha1.UserConfigTemplateSpec{ResourceQuotas: &myoperatorv1alpha1.ResourceQuota{CPU: "1"}},
+		}
+		alice, bob := userConfig("alice", nil), userConfig("bob", nil)
+		for _, uc := range []*myoperatorv1alpha1.UserConfig{alice, bob} {
+			uc.Spec.TemplateRef = &myoperatorv1alpha1.TemplateReference{Name: "developer"}
+		}
+		u, _ := newTestUseCase(template, alice, bob, budget(myoperatorv1alpha1.BudgetEnforcementReject))
+
+		// 3 CPUs for each of them fill the budget
+		raised := template.DeepCopy()
+		raised.Spec.ResourceQuotas.CPU = "3"
+		violations, err := u.TemplateBudgetViolations(ctx, raised, template)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(violations).To(BeEmpty())
+
+		raised.Spec.ResourceQuotas.CPU = "4"
+		violations, err = u.TemplateBudgetViolations(ctx, raised, template)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(violations).To(HaveLen(1))
+		Expect(violations[0].String()).To(Equal("TenantBudget cluster has 6 cpu left for this UserConfigTemplate, which requests 8"))
+	})
+
+	It("reads the committed quotas from the ledger once refreshed", func() {
+		alice := userConfig("alice", &myoperatorv1alpha1.ResourceQuota{CPU: "4"})
+		u, _ := newTestUseCase(alice, budget(myoperatorv1alpha1.BudgetEnforcementReject))
+		u.Budgets = NewBudgetLedger()
+		Expect(u.ReconcileTenantBudgets(ctx)).To(Succeed())
+
+		// The ledger counts alice without listing, and bob as soon as admitted
+		Expect(u.Delete(ctx, alice)).To(Succeed())
+		bob := userConfig("bob", &myoperatorv1alpha1.ResourceQuota{CPU: "2"})
+		violations, err := u.TenantBudgetViolations(ctx, bob, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(violations).To(BeEmpty())
+
+		carol := userConfig("carol", &myoperatorv1alpha1.ResourceQuota{CPU: "1"})
+		violations, err = u.TenantBudgetViolations(ctx, carol, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(violations).To(HaveLen(1))
+		Expect(violations[0].Headroom.String()).To(Equal("0"))
+	})
+
+	It("marks the over-committed UserConfigs and fills the budget status", func() {
+		alice := userConfig("alice", &myoperatorv1alpha1.ResourceQuota{CPU: "4"})
+		bob := userConfig("bob", &myoperatorv1alpha1.ResourceQuota{CPU: "4"})
+		u, _ := newTestUseCase(alice, bob, budget(myoperatorv1alpha1.BudgetEnforcementMark))
+
+		Expect(u.ReconcileTenantBudgets(ctx)).To(Succeed())
+
+		b := &myoperatorv1alpha1.TenantBudget{}
+		Expect(u.Get(ctx, client.ObjectKey{Name: "cluster"}, b)).To(Succeed())
+		Expect(b.Status.OverCommitted).To(Equal([]string{"bob"}))
+		Expect(b.Status.Headroom).To(HaveKeyWithValue("cpu", "-2"))
+
+		Expect(u.Get(ctx, client.ObjectKey{Name: "alice"}, alice)).To(Succeed())
+		Expect(meta.IsStatusConditionFalse(alice.Status.Conditions, myoperatorv1alpha1.OverCommittedCondition)).To(BeTrue())
+		Expect(u.Get(ctx, client.ObjectKey{Name: "bob"}, bob)).To(Succeed())
+		condition := meta.FindStatusCondition(bob.Status.Conditions, myoperatorv1alpha1.OverCommittedCondition)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Message).To(ContainSubstring("TenantBudget cluster"))
+	})
+})
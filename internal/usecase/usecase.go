@@ -2,10 +2,11 @@ package usecase
 
 import (
 	"context"
-
-	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -14,6 +15,10 @@ import (
 )
 
 type UseCase interface {
+	ResolveTemplate(ctx context.Context, uc *myoperatorv1alpha1.UserConfig) (*myoperatorv1alpha1.UserConfig, error)
+	MigrateResourceQuotas(ctx context.Context, userConfig *myoperatorv1alpha1.UserConfig) error
+	ReconcileApproval(ctx context.Context, uc *myoperatorv1alpha1.UserConfig) (bool, error)
+
 	ReconcileNamespace(ctx context.Context, uc *myoperatorv1alpha1.UserConfig) error
 	ReconcileResourceQuota(ctx context.Context, userConfig *myoperatorv1alpha1.UserConfig) error
 
@@ -22,21 +27,102 @@ type UseCase interface {
 	ReconcileRole(ctx context.Context, uc *myoperatorv1alpha1.UserConfig) error
 	ReconcileServiceAccount(ctx context.Context, uc *myoperatorv1alpha1.UserConfig) error
 	ReconcileRoleBinding(ctx context.Context, uc *myoperatorv1alpha1.UserConfig) error
+	ReconcileClusterRBAC(ctx context.Context, uc *myoperatorv1alpha1.UserConfig) error
+	ReconcileNamespaceAccess(ctx context.Context, uc *myoperatorv1alpha1.UserConfig) error
 	ReconcileLimitRange(ctx context.Context, userConfig *myoperatorv1alpha1.UserConfig) error
 	GenerateAndSaveKubeconfig(ctx context.Context, uc *myoperatorv1alpha1.UserConfig) error
 	ReconcileNetworkPolicies(ctx context.Context, uc *myoperatorv1alpha1.UserConfig) error
+	ReconcileQuotaStatus(ctx context.Context, uc *myoperatorv1alpha1.UserConfig) error
+	AutoscaleQuotas(ctx context.Context, uc *myoperatorv1alpha1.UserConfig, now time.Time) error
 
 	HandleDeletion(ctx context.Context, uc *myoperatorv1alpha1.UserConfig) (ctrl.Result, error)
+	ProtectedNamespaces(uc *myoperatorv1alpha1.UserConfig) []string
+
+	ReconcileTeam(ctx context.Context, team *myoperatorv1alpha1.Team) error
+	ReconcileTeamApproval(ctx context.Context, team *myoperatorv1alpha1.Team) (bool, error)
+
+	ReconcileAccessElevation(ctx context.Context, elevation *myoperatorv1alpha1.AccessElevation, now time.Time) (time.Duration, error)
+	RevokeAccessElevation(ctx context.Context, elevation *myoperatorv1alpha1.AccessElevation, now time.Time) error
+
+	ReconcileTenantBudgets(ctx context.Context) error
+	TenantBudgetViolations(ctx context.Context, uc, old *myoperatorv1alpha1.UserConfig) ([]BudgetViolation, error)
+	TeamBudgetViolations(ctx context.Context, team, old *myoperatorv1alpha1.Team) ([]BudgetViolation, error)
+	TemplateBudgetViolations(ctx context.Context, template, old *myoperatorv1alpha1.UserConfigTemplate) ([]BudgetViolation, error)
+}
+
+// Config holds the operator wide settings shared by every UserConfig
+type Config struct {
+	// QuotaUsageThreshold is the used percentage of a quota resource above
+	// which the QuotaNearlyExhausted condition is raised
+	QuotaUsageThreshold int32
+
+	// ClusterPermissionsEnabled allows UserConfigs to request cluster scoped read access
+	ClusterPermissionsEnabled bool
+
+	// ClusterReadResources is the allowlist of cluster scoped resources a
+	// UserConfig may request read access to
+	ClusterReadResources []string
+
+	// RBACPolicy filters dangerous rules out of the generated Roles
+	RBACPolicy RBACPolicy
+
+	// PodSecurityLevel is the Pod Security level applied to managed namespaces
+	// when spec.podSecurity doesn't set one
+	PodSecurityLevel string
+
+	// PodSecurityVersion is the default Pod Security policy version
+	PodSecurityVersion string
+
+	// NetworkPolicyBackend renders the FQDN egress rules, NetworkPolicyBackendCilium
+	// or NetworkPolicyBackendCalico. FQDNs are ignored when empty.
+	NetworkPolicyBackend string
+
+	// RESTConfig is the config of the manager, used for the requests whose API
+	// server warnings are reported. They are made through the client when nil.
+	RESTConfig *rest.Config
+
+	// ApprovalsVerified is set when the AccessApproval webhook checks that every
+	// approver creates their own AccessApprovals. Without it anyone could name
+	// an approver, so no AccessApproval is trusted.
+	ApprovalsVerified bool
+}
+
+// DefaultConfig returns the settings used when nothing overrides them
+func DefaultConfig() Config {
+	return Config{
+		QuotaUsageThreshold:       90,
+		ClusterPermissionsEnabled: true,
+		ClusterReadResources:      []string{"nodes", "storageclasses", "ingressclasses", "priorityclasses", "runtimeclasses"},
+		RBACPolicy: RBACPolicy{
+			PrivilegedGroups: []string{"admin"},
+		},
+		PodSecurityLevel:   "restricted",
+		PodSecurityVersion: "latest",
+		ApprovalsVerified:  true,
+	}
 }
 
 type UserConfigUseCase struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	Config   Config
+
+	// Defaults holds the OperatorConfig defaults, the built-in ones when nil
+	Defaults *OperatorDefaults
+
+	// Budgets caches the quota committed to the TenantBudgets, which are
+	// computed from the API when nil
+	Budgets *BudgetLedger
 }
 
-func NewUserConfigUseCase(client client.Client, scheme *runtime.Scheme) UseCase {
+func NewUserConfigUseCase(client client.Client, scheme *runtime.Scheme, recorder record.EventRecorder, config Config, defaults *OperatorDefaults) UseCase {
 	return &UserConfigUseCase{
-		Client: client,
-		Scheme: scheme,
+		Client:   client,
+		Scheme:   scheme,
+		Recorder: recorder,
+		Config:   config,
+		Defaults: defaults,
+		Budgets:  NewBudgetLedger(),
 	}
 }
@@ -0,0 +1,85 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+)
+
+// nolint:unused
+// log is for logging in this package.
+var accessapprovallog = logf.Log.WithName("accessapproval-resource")
+
+// SetupAccessApprovalWebhookWithManager registers the webhook for AccessApproval in the manager.
+func SetupAccessApprovalWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&myoperatorv1alpha1.AccessApproval{}).
+		WithValidator(&AccessApprovalCustomValidator{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-myoperator-01cloud-io-v1alpha1-accessapproval,mutating=false,failurePolicy=fail,sideEffects=None,groups=myoperator.01cloud.io,resources=accessapprovals,verbs=create,versions=v1alpha1,name=vaccessapproval-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// AccessApprovalCustomValidator signs the AccessApprovals: only the user named
+// in spec.approver may create one. Whether that user is an approver is checked
+// by the UserConfig controller against the OperatorConfig approval policy.
+type AccessApprovalCustomValidator struct{}
+
+var _ webhook.CustomValidator = &AccessApprovalCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type AccessApproval.
+func (v *AccessApprovalCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	approval, ok := obj.(*myoperatorv1alpha1.AccessApproval)
+	if !ok {
+		return nil, fmt.Errorf("expected an AccessApproval object but got %T", obj)
+	}
+	accessapprovallog.Info("Validation for AccessApproval upon creation", "name", approval.GetName())
+
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the admission request: %w", err)
+	}
+	if req.UserInfo.Username == approval.Spec.Approver {
+		return nil, nil
+	}
+	return nil, apierrors.NewInvalid(myoperatorv1alpha1.GroupVersion.WithKind("AccessApproval").GroupKind(), approval.Name, field.ErrorList{
+		field.Forbidden(field.NewPath("spec", "approver"),
+			fmt.Sprintf("must be the user creating the AccessApproval, %s", req.UserInfo.Username)),
+	})
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type AccessApproval.
+// The spec is immutable, which the CRD schema enforces.
+func (v *AccessApprovalCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type AccessApproval.
+func (v *AccessApprovalCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+)
+
+var _ = Describe("AccessApproval Webhook", func() {
+	var (
+		obj       *myoperatorv1alpha1.AccessApproval
+		validator AccessApprovalCustomValidator
+	)
+
+	// requestedBy returns a context holding an admission request sent by username
+	requestedBy := func(username string) context.Context {
+		return admission.NewContextWithRequest(context.Background(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{UserInfo: authenticationv1.UserInfo{Username: username}},
+		})
+	}
+
+	BeforeEach(func() {
+		obj = &myoperatorv1alpha1.AccessApproval{
+			ObjectMeta: metav1.ObjectMeta{Name: "alice-approval"},
+			Spec:       myoperatorv1alpha1.AccessApprovalSpec{UserConfig: "alice", SpecHash: "0123456789abcdef", Approver: "sec-lead"},
+		}
+		validator = AccessApprovalCustomValidator{}
+	})
+
+	It("Should admit an AccessApproval created by its approver", func() {
+		_, err := validator.ValidateCreate(requestedBy("sec-lead"), obj)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("Should reject an AccessApproval created on behalf of someone else", func() {
+		_, err := validator.ValidateCreate(requestedBy("alice"), obj)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue(), "expected an Invalid error, got %v", err)
+		Expect(err.Error()).To(ContainSubstring("spec.approver"))
+	})
+})
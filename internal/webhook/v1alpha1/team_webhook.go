@@ -0,0 +1,111 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+	"01cloud/zoperator/internal/usecase"
+)
+
+// nolint:unused
+// log is for logging in this package.
+var teamlog = logf.Log.WithName("team-resource")
+
+// SetupTeamWebhookWithManager registers the webhook for Team in the manager.
+// uc checks the Teams against the TenantBudgets.
+func SetupTeamWebhookWithManager(mgr ctrl.Manager, uc usecase.UseCase) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&myoperatorv1alpha1.Team{}).
+		WithValidator(&TeamCustomValidator{UC: uc}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-myoperator-01cloud-io-v1alpha1-team,mutating=false,failurePolicy=fail,sideEffects=None,groups=myoperator.01cloud.io,resources=teams,verbs=create;update,versions=v1alpha1,name=vteam-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// TeamCustomValidator validates the Team resource when it is created or updated.
+// It checks the quota and limit range like the UserConfig webhook, and that the quota of
+// the shared namespace fits in the TenantBudgets.
+type TeamCustomValidator struct {
+	// UC checks the TenantBudgets, they are skipped when nil
+	UC usecase.UseCase
+}
+
+var _ webhook.CustomValidator = &TeamCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type Team.
+func (v *TeamCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	team, ok := obj.(*myoperatorv1alpha1.Team)
+	if !ok {
+		return nil, fmt.Errorf("expected a Team object but got %T", obj)
+	}
+	teamlog.Info("Validation for Team upon creation", "name", team.GetName())
+
+	return v.validate(ctx, team, nil)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type Team.
+func (v *TeamCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	team, ok := newObj.(*myoperatorv1alpha1.Team)
+	if !ok {
+		return nil, fmt.Errorf("expected a Team object for the newObj but got %T", newObj)
+	}
+	old, ok := oldObj.(*myoperatorv1alpha1.Team)
+	if !ok {
+		return nil, fmt.Errorf("expected a Team object for the oldObj but got %T", oldObj)
+	}
+	teamlog.Info("Validation for Team upon update", "name", team.GetName())
+
+	return v.validate(ctx, team, old)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type Team.
+func (v *TeamCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate checks team and, once it is valid, that the quota it adds compared
+// to old fits in the TenantBudgets
+func (v *TeamCustomValidator) validate(ctx context.Context, team, old *myoperatorv1alpha1.Team) (admission.Warnings, error) {
+	specPath := field.NewPath("spec")
+	warnings, allErrs := validateResourceQuota(team.Spec.ResourceQuotas, specPath.Child("resourceQuota"))
+	allErrs = append(allErrs, validateLimitRange(team.Spec.LimitRange, specPath.Child("limitRange"))...)
+	if len(allErrs) == 0 && v.UC != nil && team.DeletionTimestamp.IsZero() {
+		violations, err := v.UC.TeamBudgetViolations(ctx, team, old)
+		if err != nil {
+			return warnings, fmt.Errorf("failed to check the tenant budgets: %w", err)
+		}
+		var budgetErrs field.ErrorList
+		warnings, budgetErrs = budgetViolations(warnings, violations, specPath.Child("resourceQuota"))
+		allErrs = append(allErrs, budgetErrs...)
+	}
+
+	if len(allErrs) == 0 {
+		return warnings, nil
+	}
+	return warnings, apierrors.NewInvalid(myoperatorv1alpha1.GroupVersion.WithKind("Team").GroupKind(), team.Name, allErrs)
+}
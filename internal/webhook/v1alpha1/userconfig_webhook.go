@@ -0,0 +1,395 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+	"01cloud/zoperator/internal/usecase"
+)
+
+// nolint:unused
+// log is for logging in this package.
+var userconfiglog = logf.Log.WithName("userconfig-resource")
+
+// SetupUserConfigWebhookWithManager registers the webhook for UserConfig in the manager.
+// uc checks the UserConfigs against the TenantBudgets.
+func SetupUserConfigWebhookWithManager(mgr ctrl.Manager, uc usecase.UseCase) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&myoperatorv1alpha1.UserConfig{}).
+		WithValidator(&UserConfigCustomValidator{UC: uc}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-myoperator-01cloud-io-v1alpha1-userconfig,mutating=false,failurePolicy=fail,sideEffects=None,groups=myoperator.01cloud.io,resources=userconfigs,verbs=create;update,versions=v1alpha1,name=vuserconfig-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// UserConfigCustomValidator validates the UserConfig resource when it is created or updated.
+// It checks what the CRD schema can't express, like the quota resource names and quantities,
+// and that the quota fits in the TenantBudgets.
+type UserConfigCustomValidator struct {
+	// UC checks the TenantBudgets, they are skipped when nil
+	UC usecase.UseCase
+}
+
+var _ webhook.CustomValidator = &UserConfigCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type UserConfig.
+func (v *UserConfigCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	userconfig, ok := obj.(*myoperatorv1alpha1.UserConfig)
+	if !ok {
+		return nil, fmt.Errorf("expected a UserConfig object but got %T", obj)
+	}
+	userconfiglog.Info("Validation for UserConfig upon creation", "name", userconfig.GetName())
+
+	return v.validate(ctx, userconfig, nil)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type UserConfig.
+func (v *UserConfigCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	userconfig, ok := newObj.(*myoperatorv1alpha1.UserConfig)
+	if !ok {
+		return nil, fmt.Errorf("expected a UserConfig object for the newObj but got %T", newObj)
+	}
+	old, ok := oldObj.(*myoperatorv1alpha1.UserConfig)
+	if !ok {
+		return nil, fmt.Errorf("expected a UserConfig object for the oldObj but got %T", oldObj)
+	}
+	userconfiglog.Info("Validation for UserConfig upon update", "name", userconfig.GetName())
+
+	return v.validate(ctx, userconfig, old)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type UserConfig.
+func (v *UserConfigCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate checks userconfig and, once it is valid, that the quota it adds
+// compared to old fits in the TenantBudgets
+func (v *UserConfigCustomValidator) validate(ctx context.Context, userconfig, old *myoperatorv1alpha1.UserConfig) (admission.Warnings, error) {
+	warnings, err := validateUserConfig(userconfig)
+	if err != nil || v.UC == nil || !userconfig.DeletionTimestamp.IsZero() {
+		return warnings, err
+	}
+
+	var allErrs field.ErrorList
+	// Namespaces protected after the UserConfig claimed them are reported by the
+	// reconciler, only newly claimed ones are rejected
+	claimed := map[string]bool{}
+	if old != nil {
+		for _, name := range usecase.NamespaceNames(old) {
+			claimed[name] = true
+		}
+	}
+	for _, name := range v.UC.ProtectedNamespaces(userconfig) {
+		if !claimed[name] {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "namespaces"),
+				fmt.Sprintf("namespace %s is protected by OperatorConfig %s", name, myoperatorv1alpha1.OperatorConfigName)))
+		}
+	}
+
+	violations, err := v.UC.TenantBudgetViolations(ctx, userconfig, old)
+	if err != nil {
+		return warnings, fmt.Errorf("failed to check the tenant budgets: %w", err)
+	}
+	var budgetErrs field.ErrorList
+	warnings, budgetErrs = budgetViolations(warnings, violations, field.NewPath("spec", "resourceQuota"))
+	allErrs = append(allErrs, budgetErrs...)
+	if len(allErrs) == 0 {
+		return warnings, nil
+	}
+	return warnings, apierrors.NewInvalid(myoperatorv1alpha1.GroupVersion.WithKind("UserConfig").GroupKind(), userconfig.Name, allErrs)
+}
+
+// budgetViolations appends the violations of the budgets in Mark mode to
+// warnings and returns the others as errors of path
+func budgetViolations(warnings admission.Warnings, violations []usecase.BudgetViolation, path *field.Path) (admission.Warnings, field.ErrorList) {
+	var allErrs field.ErrorList
+	for _, violation := range violations {
+		if violation.Enforcement == myoperatorv1alpha1.BudgetEnforcementMark {
+			warnings = append(warnings, violation.String())
+			continue
+		}
+		allErrs = append(allErrs, field.Forbidden(path, violation.String()))
+	}
+	return warnings, allErrs
+}
+
+// validateUserConfig returns an Invalid error listing every problem of userconfig,
+// and a warning for every deprecated field it uses
+func validateUserConfig(userconfig *myoperatorv1alpha1.UserConfig) (admission.Warnings, error) {
+	specPath := field.NewPath("spec")
+	warnings, allErrs := validateResourceQuota(userconfig.Spec.ResourceQuotas, specPath.Child("resourceQuota"))
+	allErrs = append(allErrs, validateLimitRange(userconfig.Spec.LimitRange, specPath.Child("limitRange"))...)
+	for i, ns := range userconfig.Spec.Namespaces {
+		nsPath := specPath.Child("namespaces").Index(i)
+		nsWarnings, nsErrs := validateResourceQuota(ns.ResourceQuotas, nsPath.Child("resourceQuota"))
+		warnings = append(warnings, nsWarnings...)
+		allErrs = append(allErrs, nsErrs...)
+		allErrs = append(allErrs, validateLimitRange(ns.LimitRange, nsPath.Child("limitRange"))...)
+	}
+
+	if len(allErrs) == 0 {
+		return warnings, nil
+	}
+	return warnings, apierrors.NewInvalid(myoperatorv1alpha1.GroupVersion.WithKind("UserConfig").GroupKind(), userconfig.Name, allErrs)
+}
+
+// validateResourceQuota checks the resource names, quantities and scopes of rq
+// and its additional quotas
+func validateResourceQuota(rq *myoperatorv1alpha1.ResourceQuota, path *field.Path) (admission.Warnings, field.ErrorList) {
+	if rq == nil {
+		return nil, nil
+	}
+	var warnings admission.Warnings
+	var allErrs field.ErrorList
+
+	if rq.DeprecatedConfigMaps != "" {
+		legacyPath := path.Child("requests.configmaps")
+		warnings = append(warnings, fmt.Sprintf("%s is deprecated, use %s", legacyPath, path.Child("configmaps")))
+		if rq.ConfigMaps != "" && rq.ConfigMaps != rq.DeprecatedConfigMaps {
+			allErrs = append(allErrs, field.Invalid(legacyPath, rq.DeprecatedConfigMaps,
+				"conflicts with the configmaps field set to "+rq.ConfigMaps))
+		}
+	}
+
+	// The typed fields are named after their quota resource
+	typed := usecase.TypedQuotaHard(rq)
+	for name, value := range typed {
+		allErrs = append(allErrs, validateQuantity(name, value, path.Child(name))...)
+	}
+	allErrs = append(allErrs, validateHard(rq.Hard, path.Child("hard"))...)
+	allErrs = append(allErrs, validateScopes(rq.Scopes, rq.ScopeSelector, path)...)
+	for name, value := range rq.Hard {
+		if typedValue, ok := typed[name]; ok {
+			allErrs = append(allErrs, field.Invalid(path.Child("hard").Key(name), value,
+				fmt.Sprintf("conflicts with the %s field set to %s", name, typedValue)))
+		}
+	}
+
+	if rq.Autoscale != nil {
+		allErrs = append(allErrs, validateAutoscale(rq.Autoscale, path.Child("autoscale"))...)
+	}
+
+	names := map[string]bool{}
+	for i, additional := range rq.Additional {
+		additionalPath := path.Child("additional").Index(i)
+		if names[additional.Name] {
+			allErrs = append(allErrs, field.Duplicate(additionalPath.Child("name"), additional.Name))
+		}
+		names[additional.Name] = true
+		allErrs = append(allErrs, validateHard(additional.Hard, additionalPath.Child("hard"))...)
+		allErrs = append(allErrs, validateScopes(additional.Scopes, additional.ScopeSelector, additionalPath)...)
+	}
+
+	return warnings, allErrs
+}
+
+// validateAutoscale checks the resource names and bounds of autoscale
+func validateAutoscale(autoscale *myoperatorv1alpha1.QuotaAutoscale, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	names := map[string]bool{}
+	for i, res := range autoscale.Resources {
+		resPath := path.Child("resources").Index(i)
+		for _, msg := range validation.IsQualifiedName(res.Name) {
+			allErrs = append(allErrs, field.Invalid(resPath.Child("name"), res.Name, msg))
+		}
+		if names[res.Name] {
+			allErrs = append(allErrs, field.Duplicate(resPath.Child("name"), res.Name))
+		}
+		names[res.Name] = true
+
+		minErrs := validateQuantity(res.Name, res.Min, resPath.Child("min"))
+		maxErrs := validateQuantity(res.Name, res.Max, resPath.Child("max"))
+		allErrs = append(append(allErrs, minErrs...), maxErrs...)
+		if len(minErrs) == 0 && len(maxErrs) == 0 {
+			minimum, maximum := resource.MustParse(res.Min), resource.MustParse(res.Max)
+			if minimum.Cmp(maximum) > 0 {
+				allErrs = append(allErrs, field.Invalid(resPath.Child("max"), res.Max, "must be greater than or equal to min"))
+			}
+		}
+	}
+	return allErrs
+}
+
+// limitResources returns the quantities set in r keyed by resource name
+func limitResources(r *myoperatorv1alpha1.Resources) map[corev1.ResourceName]string {
+	resources := map[corev1.ResourceName]string{}
+	if r == nil {
+		return resources
+	}
+	for name, value := range map[corev1.ResourceName]string{
+		corev1.ResourceCPU:              r.CPU,
+		corev1.ResourceMemory:           r.Memory,
+		corev1.ResourceEphemeralStorage: r.EphemeralStorage,
+		corev1.ResourceStorage:          r.Storage,
+	} {
+		if value != "" {
+			resources[name] = value
+		}
+	}
+	return resources
+}
+
+// validateLimitRange checks that every limit only bounds the resources of its
+// type with valid quantities, and that the bounds are consistent
+func validateLimitRange(lr *myoperatorv1alpha1.LimitRange, path *field.Path) field.ErrorList {
+	if lr == nil {
+		return nil
+	}
+	var allErrs field.ErrorList
+	for i, limit := range lr.Limits {
+		limitPath := path.Child("limits").Index(i)
+		isPVC := limit.Type == string(corev1.LimitTypePersistentVolumeClaim)
+		bounds := map[string]map[corev1.ResourceName]resource.Quantity{}
+		for _, entry := range []struct {
+			name      string
+			resources *myoperatorv1alpha1.Resources
+		}{
+			{"min", limit.Min},
+			{"max", limit.Max},
+			{"default", limit.Default},
+			{"defaultRequest", limit.DefaultRequest},
+			{"maxLimitRequestRatio", limit.MaxLimitRequestRatio},
+		} {
+			bounds[entry.name] = map[corev1.ResourceName]resource.Quantity{}
+			for name, value := range limitResources(entry.resources) {
+				valuePath := limitPath.Child(entry.name, string(name))
+				if isPVC && name != corev1.ResourceStorage {
+					allErrs = append(allErrs, field.Forbidden(valuePath, "PersistentVolumeClaim limits only bound storage"))
+					continue
+				}
+				if !isPVC && name == corev1.ResourceStorage {
+					allErrs = append(allErrs, field.Forbidden(valuePath, "storage only applies to PersistentVolumeClaim limits"))
+					continue
+				}
+				if errs := validateQuantity(string(name), value, valuePath); len(errs) > 0 {
+					allErrs = append(allErrs, errs...)
+					continue
+				}
+				bounds[entry.name][name] = resource.MustParse(value)
+			}
+		}
+
+		if isPVC && limitResources(limit.Min)[corev1.ResourceStorage] == "" && limitResources(limit.Max)[corev1.ResourceStorage] == "" {
+			allErrs = append(allErrs, field.Required(limitPath, "PersistentVolumeClaim limits require min.storage or max.storage"))
+		}
+		for name, maximum := range bounds["max"] {
+			if minimum, ok := bounds["min"][name]; ok && minimum.Cmp(maximum) > 0 {
+				allErrs = append(allErrs, field.Invalid(limitPath.Child("min", string(name)), minimum.String(),
+					"must be less than or equal to max "+maximum.String()))
+			}
+		}
+		for name, limitValue := range bounds["default"] {
+			if request, ok := bounds["defaultRequest"][name]; ok && request.Cmp(limitValue) > 0 {
+				allErrs = append(allErrs, field.Invalid(limitPath.Child("defaultRequest", string(name)), request.String(),
+					"must be less than or equal to default "+limitValue.String()))
+			}
+		}
+		one := resource.MustParse("1")
+		for name, ratio := range bounds["maxLimitRequestRatio"] {
+			if ratio.Cmp(one) < 0 {
+				allErrs = append(allErrs, field.Invalid(limitPath.Child("maxLimitRequestRatio", string(name)), ratio.String(),
+					"must be greater than or equal to 1"))
+			}
+		}
+	}
+	return allErrs
+}
+
+// validateHard checks that hard holds quota resource names with valid quantities
+func validateHard(hard map[string]string, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for name, value := range hard {
+		for _, msg := range validation.IsQualifiedName(name) {
+			allErrs = append(allErrs, field.Invalid(path.Key(name), name, msg))
+		}
+		allErrs = append(allErrs, validateQuantity(name, value, path.Key(name))...)
+	}
+	return allErrs
+}
+
+// validateQuantity checks that value is a non-negative quantity, and a whole
+// number for the resources counting objects
+func validateQuantity(name, value string, path *field.Path) field.ErrorList {
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return field.ErrorList{field.Invalid(path, value, err.Error())}
+	}
+	if quantity.Sign() < 0 {
+		return field.ErrorList{field.Invalid(path, value, "must be greater than or equal to 0")}
+	}
+	if usecase.IsIntegerQuotaResource(name) && quantity.MilliValue()%1000 != 0 {
+		return field.ErrorList{field.Invalid(path, value, "must be an integer")}
+	}
+	return nil
+}
+
+// validateScopes checks the scope combinations and scope selector expressions
+// the ResourceQuota API rejects
+func validateScopes(scopes []myoperatorv1alpha1.QuotaScope, selector *corev1.ScopeSelector, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	used := map[corev1.ResourceQuotaScope]bool{}
+	for _, scope := range scopes {
+		used[corev1.ResourceQuotaScope(scope)] = true
+	}
+
+	if selector != nil {
+		for i, req := range selector.MatchExpressions {
+			reqPath := path.Child("scopeSelector", "matchExpressions").Index(i)
+			used[req.ScopeName] = true
+			switch req.Operator {
+			case corev1.ScopeSelectorOpIn, corev1.ScopeSelectorOpNotIn:
+				if len(req.Values) == 0 {
+					allErrs = append(allErrs, field.Required(reqPath.Child("values"), "must be set for In and NotIn"))
+				}
+			case corev1.ScopeSelectorOpExists, corev1.ScopeSelectorOpDoesNotExist:
+				if len(req.Values) > 0 {
+					allErrs = append(allErrs, field.Invalid(reqPath.Child("values"), req.Values, "must be empty for Exists and DoesNotExist"))
+				}
+			default:
+				allErrs = append(allErrs, field.NotSupported(reqPath.Child("operator"), req.Operator,
+					[]string{string(corev1.ScopeSelectorOpIn), string(corev1.ScopeSelectorOpNotIn), string(corev1.ScopeSelectorOpExists), string(corev1.ScopeSelectorOpDoesNotExist)}))
+			}
+			if req.ScopeName != corev1.ResourceQuotaScopePriorityClass && req.Operator != corev1.ScopeSelectorOpExists && req.Operator != corev1.ScopeSelectorOpDoesNotExist {
+				allErrs = append(allErrs, field.Invalid(reqPath.Child("operator"), req.Operator, "only Exists and DoesNotExist are supported for scope "+string(req.ScopeName)))
+			}
+		}
+	}
+
+	if used[corev1.ResourceQuotaScopeTerminating] && used[corev1.ResourceQuotaScopeNotTerminating] {
+		allErrs = append(allErrs, field.Invalid(path.Child("scopes"), scopes, "Terminating and NotTerminating are mutually exclusive"))
+	}
+	if used[corev1.ResourceQuotaScopeBestEffort] && used[corev1.ResourceQuotaScopeNotBestEffort] {
+		allErrs = append(allErrs, field.Invalid(path.Child("scopes"), scopes, "BestEffort and NotBestEffort are mutually exclusive"))
+	}
+	return allErrs
+}
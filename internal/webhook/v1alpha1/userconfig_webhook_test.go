@@ -0,0 +1,222 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+	"01cloud/zoperator/internal/usecase"
+)
+
+var _ = Describe("UserConfig Webhook", func() {
+	var (
+		obj       *myoperatorv1alpha1.UserConfig
+		validator UserConfigCustomValidator
+	)
+
+	BeforeEach(func() {
+		obj = &myoperatorv1alpha1.UserConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "tenant"},
+			Spec: myoperatorv1alpha1.UserConfigSpec{
+				ResourceQuotas: &myoperatorv1alpha1.ResourceQuota{CPU: "4"},
+			},
+		}
+		validator = UserConfigCustomValidator{}
+	})
+
+	expectInvalid := func(substring string) {
+		_, err := validator.ValidateCreate(context.Background(), obj)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue(), "expected an Invalid error, got %v", err)
+		Expect(err.Error()).To(ContainSubstring(substring))
+	}
+
+	Context("When validating the limit range", func() {
+		It("Should admit PersistentVolumeClaim, ephemeral storage and ratio limits", func() {
+			obj.Spec.LimitRange = &myoperatorv1alpha1.LimitRange{Limits: []myoperatorv1alpha1.LimitRangeLimit{
+				{
+					Type:                 "Container",
+					Max:                  &myoperatorv1alpha1.Resources{CPU: "2", EphemeralStorage: "4Gi"},
+					MaxLimitRequestRatio: &myoperatorv1alpha1.Resources{CPU: "4"},
+				},
+				{Type: "PersistentVolumeClaim", Max: &myoperatorv1alpha1.Resources{Storage: "100Gi"}},
+			}}
+			_, err := validator.ValidateCreate(context.Background(), obj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should only allow storage on PersistentVolumeClaim limits", func() {
+			obj.Spec.LimitRange = &myoperatorv1alpha1.LimitRange{Limits: []myoperatorv1alpha1.LimitRangeLimit{
+				{Type: "PersistentVolumeClaim", Max: &myoperatorv1alpha1.Resources{CPU: "2"}},
+			}}
+			expectInvalid("spec.limitRange.limits[0].max.cpu")
+			expectInvalid("require min.storage or max.storage")
+
+			obj.Spec.LimitRange.Limits[0] = myoperatorv1alpha1.LimitRangeLimit{
+				Type: "Container", Max: &myoperatorv1alpha1.Resources{Storage: "10Gi"},
+			}
+			expectInvalid("storage only applies to PersistentVolumeClaim limits")
+		})
+
+		It("Should reject inconsistent bounds", func() {
+			obj.Spec.LimitRange = &myoperatorv1alpha1.LimitRange{Limits: []myoperatorv1alpha1.LimitRangeLimit{{
+				Type:                 "Container",
+				Min:                  &myoperatorv1alpha1.Resources{EphemeralStorage: "2Gi"},
+				Max:                  &myoperatorv1alpha1.Resources{EphemeralStorage: "1Gi"},
+				MaxLimitRequestRatio: &myoperatorv1alpha1.Resources{Memory: "500m"},
+			}}}
+			expectInvalid("spec.limitRange.limits[0].min.ephemeral-storage")
+			expectInvalid("spec.limitRange.limits[0].maxLimitRequestRatio.memory")
+		})
+	})
+
+	Context("When validating the resource quota", func() {
+		It("Should admit generic hard limits, scopes and additional quotas", func() {
+			obj.Spec.ResourceQuotas.Hard = map[string]string{
+				"count/deployments.apps":                            "10",
+				"requests.nvidia.com/gpu":                           "2",
+				"gold.storageclass.storage.k8s.io/requests.storage": "500Gi",
+			}
+			obj.Spec.ResourceQuotas.Additional = []myoperatorv1alpha1.ScopedResourceQuota{{
+				Name: "high-priority",
+				Hard: map[string]string{"pods": "5", "requests.cpu": "1.5"},
+				ScopeSelector: &corev1.ScopeSelector{MatchExpressions: []corev1.ScopedResourceSelectorRequirement{{
+					ScopeName: corev1.ResourceQuotaScopePriorityClass,
+					Operator:  corev1.ScopeSelectorOpIn,
+					Values:    []string{"high"},
+				}}},
+			}, {
+				Name:   "best-effort",
+				Hard:   map[string]string{"pods": "20"},
+				Scopes: []myoperatorv1alpha1.QuotaScope{"BestEffort"},
+			}}
+
+			_, err := validator.ValidateCreate(context.Background(), obj)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = validator.ValidateUpdate(context.Background(), obj, obj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should reject invalid quantities and resource names", func() {
+			obj.Spec.ResourceQuotas.Hard = map[string]string{"requests.nvidia.com/gpu": "two"}
+			expectInvalid("spec.resourceQuota.hard[requests.nvidia.com/gpu]")
+
+			obj.Spec.ResourceQuotas.Hard = map[string]string{"not a name": "1"}
+			expectInvalid("spec.resourceQuota.hard[not a name]")
+		})
+
+		It("Should validate the typed fields as quantities", func() {
+			obj.Spec.ResourceQuotas = &myoperatorv1alpha1.ResourceQuota{CPU: "500m", RequestsMemory: "1.5Gi", Pods: "10"}
+			_, err := validator.ValidateCreate(context.Background(), obj)
+			Expect(err).NotTo(HaveOccurred())
+
+			obj.Spec.ResourceQuotas.RequestsMemory = "1.5GB"
+			expectInvalid("spec.resourceQuota.requests.memory")
+
+			obj.Spec.ResourceQuotas.RequestsMemory = ""
+			obj.Spec.ResourceQuotas.Pods = "1.5"
+			expectInvalid("must be an integer")
+		})
+
+		It("Should accept the deprecated configmaps spelling with a warning", func() {
+			obj.Spec.ResourceQuotas.DeprecatedConfigMaps = "20"
+			warnings, err := validator.ValidateCreate(context.Background(), obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ConsistOf("spec.resourceQuota.requests.configmaps is deprecated, use spec.resourceQuota.configmaps"))
+
+			obj.Spec.ResourceQuotas.ConfigMaps = "10"
+			expectInvalid("conflicts with the configmaps field set to 10")
+		})
+
+		It("Should validate the autoscaled resources", func() {
+			obj.Spec.ResourceQuotas.Autoscale = &myoperatorv1alpha1.QuotaAutoscale{
+				Resources: []myoperatorv1alpha1.AutoscaledResource{{Name: "requests.cpu", Min: "500m", Max: "8"}},
+			}
+			_, err := validator.ValidateCreate(context.Background(), obj)
+			Expect(err).NotTo(HaveOccurred())
+
+			obj.Spec.ResourceQuotas.Autoscale.Resources = append(obj.Spec.ResourceQuotas.Autoscale.Resources,
+				myoperatorv1alpha1.AutoscaledResource{Name: "pods", Min: "20", Max: "10"})
+			expectInvalid("spec.resourceQuota.autoscale.resources[1].max")
+		})
+
+		It("Should reject a resource set both in hard and in a typed field", func() {
+			obj.Spec.ResourceQuotas.Hard = map[string]string{"cpu": "8"}
+			expectInvalid("conflicts with the cpu field set to 4")
+		})
+
+		It("Should reject duplicated additional quota names", func() {
+			quota := myoperatorv1alpha1.ScopedResourceQuota{Name: "gpu", Hard: map[string]string{"requests.nvidia.com/gpu": "1"}}
+			obj.Spec.ResourceQuotas.Additional = []myoperatorv1alpha1.ScopedResourceQuota{quota, quota}
+			expectInvalid("spec.resourceQuota.additional[1].name")
+		})
+
+		It("Should reject conflicting scopes and invalid scope selectors", func() {
+			obj.Spec.ResourceQuotas.Scopes = []myoperatorv1alpha1.QuotaScope{"Terminating", "NotTerminating"}
+			expectInvalid("mutually exclusive")
+
+			obj.Spec.ResourceQuotas.Scopes = nil
+			obj.Spec.ResourceQuotas.ScopeSelector = &corev1.ScopeSelector{MatchExpressions: []corev1.ScopedResourceSelectorRequirement{{
+				ScopeName: corev1.ResourceQuotaScopePriorityClass,
+				Operator:  corev1.ScopeSelectorOpIn,
+			}}}
+			expectInvalid("spec.resourceQuota.scopeSelector.matchExpressions[0].values")
+		})
+
+		It("Should validate the quota overrides of additional namespaces", func() {
+			obj.Spec.Namespaces = []myoperatorv1alpha1.UserNamespace{{
+				Suffix:         "dev",
+				ResourceQuotas: &myoperatorv1alpha1.ResourceQuota{Hard: map[string]string{"pods": "-1"}},
+			}}
+			expectInvalid("spec.namespaces[0].resourceQuota.hard[pods]")
+		})
+	})
+
+	Context("When validating the namespaces", func() {
+		BeforeEach(func() {
+			scheme := runtime.NewScheme()
+			Expect(myoperatorv1alpha1.AddToScheme(scheme)).To(Succeed())
+			defaults := usecase.NewOperatorDefaults()
+			defaults.Set(myoperatorv1alpha1.OperatorConfigSpec{ProtectedNamespaces: []string{"monitoring"}})
+			validator.UC = &usecase.UserConfigUseCase{
+				Client:   fake.NewClientBuilder().WithScheme(scheme).Build(),
+				Scheme:   scheme,
+				Defaults: defaults,
+			}
+		})
+
+		It("Should reject newly claimed protected namespaces", func() {
+			obj.Spec.Namespaces = []myoperatorv1alpha1.UserNamespace{{Name: "kube-system"}, {Name: "monitoring"}}
+			expectInvalid("namespace kube-system is protected by OperatorConfig cluster")
+			expectInvalid("namespace monitoring is protected")
+
+			// A namespace protected after the UserConfig claimed it doesn't block updates
+			_, err := validator.ValidateUpdate(context.Background(), obj.DeepCopy(), obj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
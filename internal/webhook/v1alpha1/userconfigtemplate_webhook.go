@@ -0,0 +1,111 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+	"01cloud/zoperator/internal/usecase"
+)
+
+// nolint:unused
+// log is for logging in this package.
+var userconfigtemplatelog = logf.Log.WithName("userconfigtemplate-resource")
+
+// SetupUserConfigTemplateWebhookWithManager registers the webhook for UserConfigTemplate in the manager.
+// uc checks the UserConfigs inheriting from the templates against the TenantBudgets.
+func SetupUserConfigTemplateWebhookWithManager(mgr ctrl.Manager, uc usecase.UseCase) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&myoperatorv1alpha1.UserConfigTemplate{}).
+		WithValidator(&UserConfigTemplateCustomValidator{UC: uc}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-myoperator-01cloud-io-v1alpha1-userconfigtemplate,mutating=false,failurePolicy=fail,sideEffects=None,groups=myoperator.01cloud.io,resources=userconfigtemplates,verbs=create;update,versions=v1alpha1,name=vuserconfigtemplate-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// UserConfigTemplateCustomValidator validates the UserConfigTemplate resource when it is created or updated.
+// It checks the quota and limit range like the UserConfig webhook, and that the quota the
+// UserConfigs inheriting from the template add fits in the TenantBudgets.
+type UserConfigTemplateCustomValidator struct {
+	// UC checks the TenantBudgets, they are skipped when nil
+	UC usecase.UseCase
+}
+
+var _ webhook.CustomValidator = &UserConfigTemplateCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type UserConfigTemplate.
+func (v *UserConfigTemplateCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	template, ok := obj.(*myoperatorv1alpha1.UserConfigTemplate)
+	if !ok {
+		return nil, fmt.Errorf("expected a UserConfigTemplate object but got %T", obj)
+	}
+	userconfigtemplatelog.Info("Validation for UserConfigTemplate upon creation", "name", template.GetName())
+
+	return v.validate(ctx, template, nil)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type UserConfigTemplate.
+func (v *UserConfigTemplateCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	template, ok := newObj.(*myoperatorv1alpha1.UserConfigTemplate)
+	if !ok {
+		return nil, fmt.Errorf("expected a UserConfigTemplate object for the newObj but got %T", newObj)
+	}
+	old, ok := oldObj.(*myoperatorv1alpha1.UserConfigTemplate)
+	if !ok {
+		return nil, fmt.Errorf("expected a UserConfigTemplate object for the oldObj but got %T", oldObj)
+	}
+	userconfigtemplatelog.Info("Validation for UserConfigTemplate upon update", "name", template.GetName())
+
+	return v.validate(ctx, template, old)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type UserConfigTemplate.
+func (v *UserConfigTemplateCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate checks template and, once it is valid, that the quota its
+// UserConfigs add compared to old fits in the TenantBudgets
+func (v *UserConfigTemplateCustomValidator) validate(ctx context.Context, template, old *myoperatorv1alpha1.UserConfigTemplate) (admission.Warnings, error) {
+	specPath := field.NewPath("spec")
+	warnings, allErrs := validateResourceQuota(template.Spec.ResourceQuotas, specPath.Child("resourceQuota"))
+	allErrs = append(allErrs, validateLimitRange(template.Spec.LimitRange, specPath.Child("limitRange"))...)
+	if len(allErrs) == 0 && v.UC != nil && template.DeletionTimestamp.IsZero() {
+		violations, err := v.UC.TemplateBudgetViolations(ctx, template, old)
+		if err != nil {
+			return warnings, fmt.Errorf("failed to check the tenant budgets: %w", err)
+		}
+		var budgetErrs field.ErrorList
+		warnings, budgetErrs = budgetViolations(warnings, violations, specPath.Child("resourceQuota"))
+		allErrs = append(allErrs, budgetErrs...)
+	}
+
+	if len(allErrs) == 0 {
+		return warnings, nil
+	}
+	return warnings, apierrors.NewInvalid(myoperatorv1alpha1.GroupVersion.WithKind("UserConfigTemplate").GroupKind(), template.Name, allErrs)
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	myoperatorv1alpha1 "01cloud/zoperator/api/v1alpha1"
+	"01cloud/zoperator/internal/usecase"
+)
+
+var _ = Describe("UserConfigTemplate Webhook", func() {
+	var (
+		obj       *myoperatorv1alpha1.UserConfigTemplate
+		validator UserConfigTemplateCustomValidator
+	)
+
+	BeforeEach(func() {
+		obj = &myoperatorv1alpha1.UserConfigTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "developer"},
+			Spec: myoperatorv1alpha1.UserConfigTemplateSpec{
+				ResourceQuotas: &myoperatorv1alpha1.ResourceQuota{CPU: "2"},
+			},
+		}
+		validator = UserConfigTemplateCustomValidator{}
+	})
+
+	It("Should reject invalid quantities", func() {
+		obj.Spec.ResourceQuotas.CPU = "two"
+		_, err := validator.ValidateCreate(context.Background(), obj)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue(), "expected an Invalid error, got %v", err)
+		Expect(err.Error()).To(ContainSubstring("spec.resourceQuota.cpu"))
+	})
+
+	It("Should reject a quota raise the UserConfigs inheriting from it don't fit in", func() {
+		scheme := runtime.NewScheme()
+		Expect(myoperatorv1alpha1.AddToScheme(scheme)).To(Succeed())
+		uc := &myoperatorv1alpha1.UserConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "alice"},
+			Spec:       myoperatorv1alpha1.UserConfigSpec{TemplateRef: &myoperatorv1alpha1.TemplateReference{Name: "developer"}},
+		}
+		budget := &myoperatorv1alpha1.TenantBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Spec:       myoperatorv1alpha1.TenantBudgetSpec{CPU: "4", Enforcement: myoperatorv1alpha1.BudgetEnforcementReject},
+		}
+		validator.UC = &usecase.UserConfigUseCase{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(obj, uc, budget).Build(),
+			Scheme: scheme,
+		}
+
+		raised := obj.DeepCopy()
+		raised.Spec.ResourceQuotas.CPU = "8"
+		_, err := validator.ValidateUpdate(context.Background(), obj, raised)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue(), "expected an Invalid error, got %v", err)
+		Expect(err.Error()).To(ContainSubstring("TenantBudget cluster has 4 cpu left for this UserConfigTemplate"))
+
+		raised.Spec.ResourceQuotas.CPU = "3"
+		_, err = validator.ValidateUpdate(context.Background(), obj, raised)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})